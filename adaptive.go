@@ -0,0 +1,73 @@
+package log
+
+import (
+    "sync"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// AdaptiveLevel 配置高负载时自动收紧日志级别: 输出速率超过阈值时，Logger 当前级别的日志被丢弃
+// (相当于最低级别提高一档，例如 Debug 变为 Info)，速率回落到阈值以下后恢复
+type AdaptiveLevel struct {
+    RateThreshold float64       // 每秒输出条目数的阈值，<= 0 表示不启用
+    Window        time.Duration // 统计速率的时间窗口，默认为 1 秒
+}
+
+// loadMonitor 统计尝试输出的条目数 (包括收紧级别后被丢弃的条目)，在每个窗口结束时根据速率决定是否收紧级别。
+// 窗口内条目数已超过阈值时立即收紧，不必等到窗口结束；收紧期间被丢弃的条目同样计入速率，
+// 避免速率因丢弃而下降、级别在收紧与恢复之间来回切换
+type loadMonitor struct {
+    threshold float64
+    window    time.Duration
+    now       func() time.Time
+
+    mu          sync.Mutex
+    windowStart time.Time
+    count       int
+    raised      bool
+}
+
+// newLoadMonitor 创建速率统计器，未启用时返回 nil
+func newLoadMonitor(cfg AdaptiveLevel) *loadMonitor {
+    if cfg.RateThreshold <= 0 {
+        return nil
+    }
+    window := cfg.Window
+    if window <= 0 {
+        window = time.Second
+    }
+    return &loadMonitor{
+        threshold:   cfg.RateThreshold,
+        window:      window,
+        now:         time.Now,
+        windowStart: time.Now(),
+    }
+}
+
+// roll 在窗口结束时按窗口内的速率更新状态并开始新窗口，调用方需持有锁。
+// 距窗口开始已超过两个窗口时，说明至少一个完整窗口内没有条目，直接恢复
+func (m *loadMonitor) roll() {
+    now := m.now()
+    elapsed := now.Sub(m.windowStart)
+    if elapsed < m.window {
+        return
+    }
+    m.raised = elapsed < 2*m.window && float64(m.count)/elapsed.Seconds() > m.threshold
+    m.count = 0
+    m.windowStart = now
+}
+
+// drop 记录一条尝试输出的条目，并判断收紧状态下该级别的日志是否丢弃:
+// 只丢弃与 Logger 当前级别相同的日志，Fatal/Panic 始终保留
+func (m *loadMonitor) drop(level, loggerLevel logrus.Level) bool {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.roll()
+    drop := level == loggerLevel && level > logrus.FatalLevel && m.raised
+    m.count++
+    if float64(m.count) > m.threshold*m.window.Seconds() {
+        m.raised = true
+    }
+    return drop
+}
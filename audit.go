@@ -0,0 +1,186 @@
+package log
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "maps"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+const (
+    AuditActorFieldKey     = "actor"
+    AuditActionFieldKey    = "action"
+    AuditResourceFieldKey  = "resource"
+    AuditOutcomeFieldKey   = "outcome"
+    AuditTimestampFieldKey = "timestamp"
+
+    // AuditMarkerFieldKey 与 AuditMarkerValue 标记审计日志，便于与诊断日志分离 (例如按 log_type=audit 路由)
+    AuditMarkerFieldKey = "log_type"
+    AuditMarkerValue    = "audit"
+)
+
+// 常用的审计结果取值
+const (
+    AuditOutcomeSuccess = "success"
+    AuditOutcomeFailure = "failure"
+    AuditOutcomeDenied  = "denied"
+)
+
+// ErrInvalidAuditEvent 表示审计事件缺少必填字段
+var ErrInvalidAuditEvent = errors.New("invalid audit event")
+
+// AuditEvent 是具有固定 schema 的审计事件，Actor、Action、Resource、Outcome 为必填项
+type AuditEvent struct {
+    Actor     string         // 执行操作的主体，例如用户 ID 或服务名
+    Action    string         // 操作，例如 "delete_user"
+    Resource  string         // 操作对象，例如 "user/42"
+    Outcome   string         // 结果，例如 AuditOutcomeSuccess
+    Timestamp time.Time      // 事件发生时间，为零值时使用当前时间
+    Fields    map[string]any // 附加字段，不能覆盖 schema 中的字段
+}
+
+// validate 检查必填字段，返回包装了 ErrInvalidAuditEvent 的错误
+func (e AuditEvent) validate() error {
+    var missing []string
+    for _, f := range []struct{ key, value string }{
+        {AuditActorFieldKey, e.Actor},
+        {AuditActionFieldKey, e.Action},
+        {AuditResourceFieldKey, e.Resource},
+        {AuditOutcomeFieldKey, e.Outcome},
+    } {
+        if strings.TrimSpace(f.value) == "" {
+            missing = append(missing, f.key)
+        }
+    }
+    if len(missing) > 0 {
+        return fmt.Errorf("%w: missing %s", ErrInvalidAuditEvent, strings.Join(missing, ", "))
+    }
+    return nil
+}
+
+// syncer 是支持落盘的输出目标，*os.File 即满足该接口
+type syncer interface {
+    Sync() error
+}
+
+// syncWriter 在每次写入后调用 Sync，保证数据已落盘
+type syncWriter struct {
+    w io.Writer
+}
+
+// Write 实现 io.Writer
+func (s *syncWriter) Write(p []byte) (int, error) {
+    n, err := s.w.Write(p)
+    if err != nil {
+        return n, err
+    }
+    if f, ok := s.w.(syncer); ok {
+        if err := f.Sync(); err != nil {
+            return n, err
+        }
+    }
+    return n, nil
+}
+
+// AuditLogger 是用于审计日志的 Logger。
+// 与普通诊断日志不同，审计日志逐条同步写入并落盘 (输出目标支持 Sync 时)，不做任何采样或合并，
+// 每条日志都带有 actor 与 action 字段，写入失败会直接返回给调用方。
+type AuditLogger struct {
+    mu     sync.Mutex // 串行化写入，保证顺序与错误归属
+    logger *LogrusLogger
+}
+
+// NewAuditLogger 创建审计 Logger，cfg 中的频率控制与采样相关配置会被忽略。
+// 审计日志以 Info 级别写出，cfg.Level 高于 Info (如 Warn、Error) 时降为 Info，保证审计事件不会被级别过滤。
+// 字段白名单 (AllowedFields)、命名风格 (FieldNameStyle) 与字段前缀 (FieldPrefix、PrefixExplicitFields) 同样被忽略，
+// 保证审计日志的 schema 字段名固定不变；RespectStructTags 只影响附加字段中的结构体值，保持生效
+func NewAuditLogger(cfg Config) (*AuditLogger, error) {
+    cfg.PropagateWriteErrors = true
+    cfg.LevelSampleRates = nil
+    cfg.AdaptiveLevel = AdaptiveLevel{}
+    cfg.Level = max(cfg.Level, logrus.InfoLevel)
+    cfg.AllowedFields = nil
+    cfg.FieldNameStyle = ""
+    cfg.FieldPrefix = ""
+    cfg.PrefixExplicitFields = false
+    cfg.ErrorOutput = nil
+    cfg.SplitErrorStream = false
+
+    l, err := NewLogger(cfg)
+    if err != nil {
+        return nil, err
+    }
+    ll := l.(*LogrusLogger)
+    var out io.Writer = cfg.Output
+    if ll.file != nil {
+        out = ll.file
+    }
+    ll.tracker.setWriter(&syncWriter{w: out})
+    return &AuditLogger{logger: ll}, nil
+}
+
+// SetLevel 调整审计 Logger 的级别，level 会过滤掉 Info 级别的审计日志时返回错误且不做修改
+func (a *AuditLogger) SetLevel(level logrus.Level) error {
+    if level < logrus.InfoLevel {
+        return fmt.Errorf("audit logger level cannot be raised above info (got %s): audit events would be dropped", level)
+    }
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    a.logger.SetLevel(level)
+    return nil
+}
+
+// Log 同步写入一条审计日志，返回写入或落盘过程中的错误
+func (a *AuditLogger) Log(ctx context.Context, actor, action string, format string, args ...any) error {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+
+    entry := a.logger.newEntry(ctx)
+    entry.Data[AuditActorFieldKey] = actor
+    entry.Data[AuditActionFieldKey] = action
+    entry.Data[AuditMarkerFieldKey] = AuditMarkerValue
+    entry.Infof(format, args...)
+    return a.logger.LastError()
+}
+
+// Audit 校验并同步写入一条审计事件，消息为 Action。输出包含 actor、action、resource、outcome、timestamp 与 log_type 字段，
+// 日志时间戳同样取自 event.Timestamp (为零值时使用 Config.Clock 或当前时间)。缺少必填字段时不写入并返回包装了 ErrInvalidAuditEvent 的错误
+func (a *AuditLogger) Audit(ctx context.Context, event AuditEvent) error {
+    if err := event.validate(); err != nil {
+        return err
+    }
+    a.mu.Lock()
+    defer a.mu.Unlock()
+
+    entry := a.logger.newEntry(ctx)
+    if event.Timestamp.IsZero() {
+        // newEntry 在配置了 Clock 时已经设置了时间
+        event.Timestamp = entry.Time
+        if event.Timestamp.IsZero() {
+            event.Timestamp = time.Now()
+        }
+    }
+    maps.Copy(entry.Data, event.Fields)
+    entry.Data[AuditActorFieldKey] = event.Actor
+    entry.Data[AuditActionFieldKey] = event.Action
+    entry.Data[AuditResourceFieldKey] = event.Resource
+    entry.Data[AuditOutcomeFieldKey] = event.Outcome
+    entry.Data[AuditTimestampFieldKey] = event.Timestamp.UTC().Format(time.RFC3339Nano)
+    entry.Data[AuditMarkerFieldKey] = AuditMarkerValue
+    entry.Time = event.Timestamp
+    entry.Info(event.Action)
+    return a.logger.LastError()
+}
+
+// Close 关闭审计 Logger 打开的文件
+func (a *AuditLogger) Close() error {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return a.logger.Close()
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+    "context"
+    "maps"
+
+    "github.com/sirupsen/logrus"
+)
+
+// BaggageKey 用于在 Context 中存储 baggage (跨服务传递的任意键值对，类似 OTel baggage)
+const BaggageKey contextKey = "baggage"
+
+// WithBaggage 将 baggage 键值对合并到 Context 中，同名键以 values 为准。
+// baggage 默认不会输出到日志，只有列在 Config.BaggageAllowlist 中的键才会作为字段输出，
+// 避免任意键值 (如用户输入) 造成字段基数膨胀。
+func WithBaggage(ctx context.Context, values map[string]string) context.Context {
+    current, _ := GetBaggage(ctx)
+    merged := make(map[string]string, len(current)+len(values))
+    maps.Copy(merged, current)
+    maps.Copy(merged, values)
+    return context.WithValue(ctx, BaggageKey, merged)
+}
+
+// GetBaggage 从 Context 中获取全部 baggage 键值对，返回的 map 不应被修改
+func GetBaggage(ctx context.Context) (map[string]string, bool) {
+    val, ok := ctx.Value(BaggageKey).(map[string]string)
+    return val, ok
+}
+
+// applyBaggage 将白名单中的 baggage 键作为字段添加到 entry，字段名按 Config.FieldPrefix 添加前缀，不覆盖已存在的字段
+func (l *LogrusLogger) applyBaggage(ctx context.Context, entry *logrus.Entry) {
+    if len(l.config.BaggageAllowlist) == 0 {
+        return
+    }
+    baggage, ok := GetBaggage(ctx)
+    if !ok {
+        return
+    }
+    for _, key := range l.config.BaggageAllowlist {
+        if v, ok := baggage[key]; ok {
+            setFieldIfAbsent(entry, l.prefixed(key), v)
+        }
+    }
+}
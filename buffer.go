@@ -0,0 +1,216 @@
+package log
+
+import (
+    "context"
+    "io"
+    "sync"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// DefaultFlushInterval 缓冲输出默认的定时写出间隔
+const DefaultFlushInterval = time.Second
+
+// bufferedWriters 记录所有未关闭的缓冲 Writer，供 InstallCrashFlush 在 panic 时写出
+var bufferedWriters sync.Map // map[*bufferedWriter]struct{}
+
+// bufferedWriter 先将日志写入内存缓冲区，在缓冲区满、定时器到期或显式 Flush 时写入底层 Writer。
+// 达到 syncLevel (含) 及以上级别的日志绕过缓冲区同步写出，条目级别由 bufferLevelFormatter 在格式化时记录。
+type bufferedWriter struct {
+    mu        sync.Mutex
+    w         io.Writer
+    buf       []byte
+    size      int
+    pending   int // 缓冲区中的日志条数
+    syncLevel logrus.Level
+    level     logrus.Level // 最近一次格式化的条目级别
+    stats     WriterStats
+
+    done      chan struct{}
+    wg        sync.WaitGroup
+    closeOnce sync.Once
+}
+
+// newBufferedWriter 创建缓冲 Writer，interval > 0 时启动定时写出的 goroutine，
+// 该 goroutine 在 Close 或 ctx 结束时退出 (ctx 为 nil 表示只随 Close 退出)
+func newBufferedWriter(ctx context.Context, w io.Writer, size int, interval time.Duration, syncLevel logrus.Level) *bufferedWriter {
+    b := &bufferedWriter{
+        w:         w,
+        buf:       make([]byte, 0, size),
+        size:      size,
+        syncLevel: syncLevel,
+        level:     logrus.TraceLevel,
+        done:      make(chan struct{}),
+    }
+    if interval > 0 {
+        b.wg.Add(1)
+        go b.flushLoop(ctx, interval)
+    }
+    bufferedWriters.Store(b, struct{}{})
+    return b
+}
+
+// Write 实现 io.Writer。缓冲区放不下时先同步写出已缓冲的内容，此时返回底层 Writer 的错误
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.stats.Enqueued++
+    if b.level <= b.syncLevel {
+        return b.writeDirect(p, true)
+    }
+    if len(p) > b.size {
+        return b.writeDirect(p, false)
+    }
+    if len(b.buf)+len(p) > b.size {
+        if err := b.flushLocked(); err != nil {
+            b.stats.Dropped++
+            return 0, err
+        }
+    }
+    // logrus 会复用 p 的底层缓冲区，append 即完成拷贝
+    b.buf = append(b.buf, p...)
+    b.pending++
+    return len(p), nil
+}
+
+// writeDirect 先写出已缓冲的内容以保持顺序，再直接写入底层 Writer。sync 为 true 且底层支持 Sync 时同时落盘
+func (b *bufferedWriter) writeDirect(p []byte, sync bool) (int, error) {
+    if err := b.flushLocked(); err != nil {
+        b.stats.Dropped++
+        return 0, err
+    }
+    n, err := b.w.Write(p)
+    if err == nil && sync {
+        if f, ok := b.w.(syncer); ok {
+            err = f.Sync()
+        }
+    }
+    if err != nil {
+        b.stats.Dropped++
+        b.stats.Errors++
+        return n, err
+    }
+    b.stats.Flushed++
+    return n, nil
+}
+
+func (b *bufferedWriter) setLevel(level logrus.Level) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.level = level
+}
+
+// bufferLevelFormatter 包装实际的 Formatter，把条目级别告知 bufferedWriter
+type bufferLevelFormatter struct {
+    logrus.Formatter
+    buffer *bufferedWriter
+}
+
+// Format 实现 logrus.Formatter
+func (f *bufferLevelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+    f.buffer.setLevel(entry.Level)
+    return f.Formatter.Format(entry)
+}
+
+// Flush 将缓冲区中的内容写入底层 Writer
+func (b *bufferedWriter) Flush() error {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    return b.flushLocked()
+}
+
+// flushLocked 写出缓冲区中的全部内容。写出失败时丢弃这些内容，避免后续日志持续失败
+func (b *bufferedWriter) flushLocked() error {
+    if b.pending == 0 {
+        return nil
+    }
+    start := time.Now()
+    _, err := b.w.Write(b.buf)
+    b.stats.LastFlushLatency = time.Since(start)
+    if err != nil {
+        b.stats.Dropped += uint64(b.pending)
+        b.stats.Errors++
+    } else {
+        b.stats.Flushed += uint64(b.pending)
+    }
+    b.buf = b.buf[:0]
+    b.pending = 0
+    return err
+}
+
+// setWriter 写出已缓冲的内容后切换底层 Writer
+func (b *bufferedWriter) setWriter(w io.Writer) error {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    err := b.flushLocked()
+    b.w = w
+    return err
+}
+
+// writerStats 返回当前的统计信息
+func (b *bufferedWriter) writerStats() WriterStats {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    stats := b.stats
+    stats.Pending = b.pending
+    return stats
+}
+
+// Close 停止定时写出并写出剩余内容，不会关闭底层 Writer
+func (b *bufferedWriter) Close() error {
+    b.closeOnce.Do(func() {
+        close(b.done)
+        bufferedWriters.Delete(b)
+    })
+    b.wg.Wait()
+    return b.Flush()
+}
+
+// flushLoop 定时写出缓冲区。ctx 结束后写出剩余内容并退出，此后缓冲区只在写满或 Flush/Close 时写出
+func (b *bufferedWriter) flushLoop(ctx context.Context, interval time.Duration) {
+    defer b.wg.Done()
+    if ctx == nil {
+        ctx = context.Background()
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ticker.C:
+            _ = b.Flush()
+        case <-ctx.Done():
+            _ = b.Flush()
+            return
+        case <-b.done:
+            return
+        }
+    }
+}
+
+// InstallCrashFlush 在发生未恢复的 panic 时写出所有缓冲 Logger (包括全局 Logger) 中尚未输出的日志，
+// 然后继续向上抛出原 panic。应在 main 函数 (或 goroutine 入口) 顶部以 defer 方式调用:
+//
+//	func main() {
+//	    defer log.InstallCrashFlush()
+//	    ...
+//	}
+//
+// 限制:
+//   - 只能捕获调用它的 goroutine 中的 panic，其他 goroutine 需各自 defer
+//   - 重新抛出的 panic 堆栈起点是本函数，原始 panic 的值与调用栈仍会输出
+//   - 无法处理 runtime 致命错误 (如并发写 map)、os.Exit 以及进程被信号杀死的情况
+func InstallCrashFlush() {
+    if r := recover(); r != nil {
+        flushAllBuffers()
+        panic(r)
+    }
+}
+
+// flushAllBuffers 写出所有未关闭的缓冲 Writer
+func flushAllBuffers() {
+    bufferedWriters.Range(func(key, _ any) bool {
+        _ = key.(*bufferedWriter).Flush()
+        return true
+    })
+}
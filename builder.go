@@ -0,0 +1,89 @@
+package log
+
+import (
+    "context"
+
+    "github.com/sirupsen/logrus"
+)
+
+// Builder 用于逐步累积字段后输出一条日志，例如:
+//
+//	log.Entry(ctx).Field("order_id", id).Err(err).Error("create order failed")
+//
+// Builder 不是并发安全的，应在单个 goroutine 中构建并输出。
+type Builder struct {
+    logger Logger
+    ctx    context.Context
+    fields map[string]any
+}
+
+// Entry 返回基于全局 Logger 的 Builder
+func Entry(ctx context.Context) *Builder {
+    return newBuilder(GetGlobalLogger(), ctx)
+}
+
+// Entry 返回基于当前 Logger 的 Builder
+func (l *LogrusLogger) Entry(ctx context.Context) *Builder {
+    return newBuilder(l, ctx)
+}
+
+func newBuilder(l Logger, ctx context.Context) *Builder {
+    if ctx == nil {
+        ctx = context.Background()
+    }
+    return &Builder{logger: l, ctx: ctx}
+}
+
+// Field 添加一个字段，同名字段以最后一次设置为准
+func (b *Builder) Field(key string, value any) *Builder {
+    if b.fields == nil {
+        b.fields = make(map[string]any)
+    }
+    b.fields[key] = value
+    return b
+}
+
+// Fields 批量添加字段
+func (b *Builder) Fields(fields map[string]any) *Builder {
+    for k, v := range fields {
+        b.Field(k, v)
+    }
+    return b
+}
+
+// Err 将 err 添加到 logrus.ErrorKey ("error") 字段，err 为 nil 时忽略
+func (b *Builder) Err(err error) *Builder {
+    if err == nil {
+        return b
+    }
+    return b.Field(logrus.ErrorKey, err)
+}
+
+// Stack 将当前调用栈 (从 Stack 的调用处开始) 添加到 stacktrace 字段
+func (b *Builder) Stack() *Builder {
+    return b.Field(StacktraceFieldKey, CaptureStacktrace(1))
+}
+
+// --- 终结方法: 输出一条日志 ---
+// 终结方法直接调用 Logger 的 *Contextf 方法，调用栈深度与全局日志函数相同，调用者信息指向终结方法的调用处
+
+func (b *Builder) Debug(msg string) {
+    b.target().DebugContextf(b.ctx, "%s", msg)
+}
+
+func (b *Builder) Info(msg string) {
+    b.target().InfoContextf(b.ctx, "%s", msg)
+}
+
+func (b *Builder) Warn(msg string) {
+    b.target().WarnContextf(b.ctx, "%s", msg)
+}
+
+func (b *Builder) Error(msg string) {
+    b.target().ErrorContextf(b.ctx, "%s", msg)
+}
+
+// target 返回绑定了累积字段的 Logger
+func (b *Builder) target() Logger {
+    return withFields(b.logger, b.fields)
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+    "runtime/debug"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+const (
+    GoVersionFieldKey   = "go_version"
+    VCSRevisionFieldKey = "vcs_revision"
+    MainVersionFieldKey = "main_version"
+)
+
+var (
+    buildInfoFields     logrus.Fields
+    buildInfoFieldsOnce sync.Once
+)
+
+// readBuildInfoFields 读取并缓存二进制中嵌入的构建信息，无法获取的项不会出现在结果中
+func readBuildInfoFields() logrus.Fields {
+    buildInfoFieldsOnce.Do(func() {
+        buildInfoFields = logrus.Fields{}
+        info, ok := debug.ReadBuildInfo()
+        if !ok {
+            return
+        }
+        if info.GoVersion != "" {
+            buildInfoFields[GoVersionFieldKey] = info.GoVersion
+        }
+        if info.Main.Version != "" {
+            buildInfoFields[MainVersionFieldKey] = info.Main.Version
+        }
+        for _, setting := range info.Settings {
+            if setting.Key == "vcs.revision" && setting.Value != "" {
+                buildInfoFields[VCSRevisionFieldKey] = setting.Value
+            }
+        }
+    })
+    return buildInfoFields
+}
+
+// BuildInfoHook 为每条日志添加构建信息字段 (go_version, vcs_revision, main_version)，不覆盖已有的同名字段
+type BuildInfoHook struct {
+    staticFieldsHook
+}
+
+// NewBuildInfoHook 创建一个 BuildInfoHook，构建信息只在首次创建时读取
+func NewBuildInfoHook() *BuildInfoHook {
+    return &BuildInfoHook{staticFieldsHook{fields: readBuildInfoFields()}}
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+    "encoding/base64"
+    "encoding/hex"
+    "fmt"
+
+    "github.com/sirupsen/logrus"
+)
+
+// BytesEncoding 定义 []byte 字段的编码方式
+type BytesEncoding string
+
+const (
+    BytesEncodingBase64 BytesEncoding = "base64" // 标准 base64 编码
+    BytesEncodingHex    BytesEncoding = "hex"    // 小写十六进制编码
+)
+
+// DefaultMaxBytesFieldLen []byte 字段编码前保留的默认最大字节数
+const DefaultMaxBytesFieldLen = 256
+
+// validate 校验编码方式，空值表示不做转换
+func (e BytesEncoding) validate() error {
+    switch e {
+    case "", BytesEncodingBase64, BytesEncodingHex:
+        return nil
+    }
+    return fmt.Errorf("invalid bytes encoding %q", e)
+}
+
+// encode 按编码方式将 b 转为字符串
+func (e BytesEncoding) encode(b []byte) string {
+    if e == BytesEncodingHex {
+        return hex.EncodeToString(b)
+    }
+    return base64.StdEncoding.EncodeToString(b)
+}
+
+// encodeBytesFields 将 []byte 字段编码为字符串。
+// 超过 maxLen 字节的值只编码前 maxLen 字节，并追加 "...(N bytes)" 标明原始长度；maxLen <= 0 表示不截断。
+func encodeBytesFields(encoding BytesEncoding, maxLen int) EntryTransform {
+    return func(entry *logrus.Entry) {
+        for k, v := range entry.Data {
+            b, ok := v.([]byte)
+            if !ok {
+                continue
+            }
+            if maxLen > 0 && len(b) > maxLen {
+                entry.Data[k] = fmt.Sprintf("%s...(%d bytes)", encoding.encode(b[:maxLen]), len(b))
+                continue
+            }
+            entry.Data[k] = encoding.encode(b)
+        }
+    }
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+    "io"
+    "reflect"
+    "runtime"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+// callerProbeName 是校准时发出探测日志的函数名
+var callerProbeName = runtime.FuncForPC(reflect.ValueOf(callerProbe).Pointer()).Name()
+
+// calibratedCallerDepth 返回从 CallerHook.Fire 到调用 logrus Entry 方法的栈帧之间的帧数，
+// 由首次调用时发出的探测日志测得，之后直接复用。测量失败时返回 0，此时 CallerHook 退回使用 SkipFrames。
+// 与固定的 CallerSkipFrames 相比，它不依赖 logrus 内部的调用层级，升级 logrus 后仍然准确。
+var calibratedCallerDepth = sync.OnceValue(func() int {
+    probe := &callerProbeHook{}
+    l := logrus.New()
+    l.SetOutput(io.Discard)
+    l.AddHook(probe)
+    callerProbe(logrus.NewEntry(l))
+    return probe.depth
+})
+
+// callerProbe 是探测日志的已知调用位置，与 LogrusLogger 的方法一样调用 Entry 的格式化方法
+//
+//go:noinline
+func callerProbe(entry *logrus.Entry) {
+    entry.Infof("caller probe")
+}
+
+// callerProbeHook 记录 callerProbe 在调用栈中相对 Fire 的位置，Fire 的调用方式必须与 CallerHook.Fire 相同
+type callerProbeHook struct {
+    depth int
+}
+
+// Levels 返回 Hook 应该触发的日志级别
+func (*callerProbeHook) Levels() []logrus.Level {
+    return logrus.AllLevels
+}
+
+// Fire 在调用栈中查找 callerProbe，Fire 自身为第 0 帧
+func (h *callerProbeHook) Fire(*logrus.Entry) error {
+    pcs := make([]uintptr, maxCallerDepth)
+    n := runtime.Callers(1, pcs)
+    frames := runtime.CallersFrames(pcs[:n])
+    for i := 0; ; i++ {
+        frame, more := frames.Next()
+        if frame.Function == callerProbeName {
+            h.depth = i
+            return nil
+        }
+        if !more {
+            return nil
+        }
+    }
+}
+
+// calibratedCaller 从校准测得的位置开始向上查找第一个不属于本包的栈帧，
+// 从而跳过 Logger 方法、全局函数、MultiLogger 等任意层数的本包封装。只能在 CallerHook.Fire 中直接调用
+func calibratedCaller(depth int) (runtime.Frame, bool) {
+    pcs := make([]uintptr, maxCallerDepth)
+    n := runtime.Callers(2, pcs) // 跳过 runtime.Callers 与 calibratedCaller，Fire 为第 0 帧
+    frames := runtime.CallersFrames(pcs[:n])
+    for i := 0; ; i++ {
+        frame, more := frames.Next()
+        if i >= depth && funcPackage(frame.Function) != selfPackage {
+            return frame, true
+        }
+        if !more {
+            return runtime.Frame{}, false
+        }
+    }
+}
@@ -0,0 +1,126 @@
+package log
+
+import (
+    "context"
+
+    "github.com/sirupsen/logrus"
+)
+
+// DebugfWith 输出一条 Debug 日志，fields 只作用于这一条日志，并优先于 Context 字段、绑定字段与默认字段中的同名字段
+func (l *LogrusLogger) DebugfWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    entry, level, ok := l.callEntry(ctx, logrus.DebugLevel, fields)
+    if !ok {
+        return
+    }
+    l.logf(entry, level, format, args...)
+}
+
+// InfofWith 输出一条 Info 日志，fields 只作用于这一条日志，并优先于 Context 字段、绑定字段与默认字段中的同名字段
+func (l *LogrusLogger) InfofWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    entry, level, ok := l.callEntry(ctx, logrus.InfoLevel, fields)
+    if !ok {
+        return
+    }
+    l.logf(entry, level, format, args...)
+}
+
+// WarnfWith 输出一条 Warn 日志，fields 只作用于这一条日志，并优先于 Context 字段、绑定字段与默认字段中的同名字段
+func (l *LogrusLogger) WarnfWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    entry, level, ok := l.callEntry(ctx, logrus.WarnLevel, fields)
+    if !ok {
+        return
+    }
+    l.logf(entry, level, format, args...)
+}
+
+// ErrorfWith 输出一条 Error 日志，fields 只作用于这一条日志，并优先于 Context 字段、绑定字段与默认字段中的同名字段
+func (l *LogrusLogger) ErrorfWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    entry, level, ok := l.callEntry(ctx, logrus.ErrorLevel, fields)
+    if !ok {
+        return
+    }
+    l.logf(entry, level, format, args...)
+}
+
+// callEntry 在 contextEntry 的基础上写入单次调用的字段，字段名按 PrefixExplicitFields 与 WithFields 一致地添加前缀
+func (l *LogrusLogger) callEntry(ctx context.Context, level logrus.Level, fields map[string]any) (*logrus.Entry, logrus.Level, bool) {
+    entry, level, ok := l.contextEntry(ctx, level)
+    if !ok {
+        return nil, 0, false
+    }
+    for k, v := range fields {
+        if l.config.PrefixExplicitFields {
+            k = l.prefixed(k)
+        }
+        entry.Data[k] = v
+    }
+    return entry, level, true
+}
+
+// callFieldsLogger 由支持单次调用字段的 Logger 实现，其他 Logger 通过 WithFields 绑定同样的字段
+type callFieldsLogger interface {
+    DebugfWith(ctx context.Context, fields map[string]any, format string, args ...any)
+    InfofWith(ctx context.Context, fields map[string]any, format string, args ...any)
+    WarnfWith(ctx context.Context, fields map[string]any, format string, args ...any)
+    ErrorfWith(ctx context.Context, fields map[string]any, format string, args ...any)
+}
+
+func (m *MultiLogger) DebugfWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    for _, l := range m.loggers {
+        if cl, ok := l.(callFieldsLogger); ok {
+            cl.DebugfWith(ctx, fields, format, args...)
+        } else {
+            withFields(l, fields).DebugContextf(ctx, format, args...)
+        }
+    }
+}
+
+func (m *MultiLogger) InfofWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    for _, l := range m.loggers {
+        if cl, ok := l.(callFieldsLogger); ok {
+            cl.InfofWith(ctx, fields, format, args...)
+        } else {
+            withFields(l, fields).InfoContextf(ctx, format, args...)
+        }
+    }
+}
+
+func (m *MultiLogger) WarnfWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    for _, l := range m.loggers {
+        if cl, ok := l.(callFieldsLogger); ok {
+            cl.WarnfWith(ctx, fields, format, args...)
+        } else {
+            withFields(l, fields).WarnContextf(ctx, format, args...)
+        }
+    }
+}
+
+func (m *MultiLogger) ErrorfWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    for _, l := range m.loggers {
+        if cl, ok := l.(callFieldsLogger); ok {
+            cl.ErrorfWith(ctx, fields, format, args...)
+        } else {
+            withFields(l, fields).ErrorContextf(ctx, format, args...)
+        }
+    }
+}
+
+// DebugfWith 使用全局 Logger 输出一条 Debug 日志，fields 只作用于这一条日志
+func DebugfWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    globalLogrusLogger().DebugfWith(ctx, fields, format, args...)
+}
+
+// InfofWith 使用全局 Logger 输出一条 Info 日志，fields 只作用于这一条日志
+func InfofWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    globalLogrusLogger().InfofWith(ctx, fields, format, args...)
+}
+
+// WarnfWith 使用全局 Logger 输出一条 Warn 日志，fields 只作用于这一条日志
+func WarnfWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    globalLogrusLogger().WarnfWith(ctx, fields, format, args...)
+}
+
+// ErrorfWith 使用全局 Logger 输出一条 Error 日志，fields 只作用于这一条日志
+func ErrorfWith(ctx context.Context, fields map[string]any, format string, args ...any) {
+    globalLogrusLogger().ErrorfWith(ctx, fields, format, args...)
+}
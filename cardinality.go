@@ -0,0 +1,66 @@
+package log
+
+import (
+    "context"
+    "maps"
+    "slices"
+)
+
+const (
+    // CardinalityLimitKey 用于在 Context 中存储自定义字段的数量上限 (见 WithCardinalityLimit)
+    CardinalityLimitKey contextKey = "cardinality_limit"
+    // droppedFieldsKey 用于在 Context 中存储因超出上限而被丢弃的自定义字段名
+    droppedFieldsKey contextKey = "dropped_fields"
+)
+
+// DroppedFieldsFieldKey 是记录被丢弃的自定义字段名的字段，取值为按丢弃顺序排列的字段名列表
+const DroppedFieldsFieldKey = "dropped_fields"
+
+// WithCardinalityLimit 限制 Context 最多携带 n 个不同的自定义字段 (WithCustomField)，
+// 避免误将请求参数等高基数的值用作字段名导致日志索引膨胀。
+// 超出上限后新增的字段名被丢弃 (已有字段仍可更新)，丢弃的字段名通过 dropped_fields 字段输出以便发现问题。
+// Context 中已有的字段超过 n 时按字段名排序保留前 n 个。n <= 0 表示不限制。
+func WithCardinalityLimit(ctx context.Context, n int) context.Context {
+    ctx = context.WithValue(ctx, CardinalityLimitKey, n)
+    fields, ok := GetCustomFields(ctx)
+    if n <= 0 || !ok || len(fields) <= n {
+        return ctx
+    }
+    keys := slices.Sorted(maps.Keys(fields))
+    kept := make(MetaData, n)
+    for _, k := range keys[:n] {
+        kept[k] = fields[k]
+    }
+    ctx = context.WithValue(ctx, CustomFieldsKey, kept)
+    for _, k := range keys[n:] {
+        ctx = withDroppedField(ctx, k)
+    }
+    return ctx
+}
+
+// GetDroppedFields 返回因超出 WithCardinalityLimit 上限而被丢弃的自定义字段名
+func GetDroppedFields(ctx context.Context) ([]string, bool) {
+    val, ok := ctx.Value(droppedFieldsKey).([]string)
+    return val, ok
+}
+
+// exceedsCardinalityLimit 判断向 fields 添加 key 是否会超出 Context 的字段数量上限
+func exceedsCardinalityLimit(ctx context.Context, fields MetaData, key string) bool {
+    limit, ok := ctx.Value(CardinalityLimitKey).(int)
+    if !ok || limit <= 0 {
+        return false
+    }
+    if _, exists := fields[key]; exists {
+        return false
+    }
+    return len(fields) >= limit
+}
+
+// withDroppedField 记录被丢弃的字段名，同名字段只记录一次 (写时复制)
+func withDroppedField(ctx context.Context, key string) context.Context {
+    dropped, _ := GetDroppedFields(ctx)
+    if slices.Contains(dropped, key) {
+        return ctx
+    }
+    return context.WithValue(ctx, droppedFieldsKey, append(slices.Clip(dropped), key))
+}
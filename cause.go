@@ -0,0 +1,45 @@
+package log
+
+import (
+    "context"
+    "time"
+)
+
+const (
+    // ContextCauseFieldKey 是 Context 已结束时记录 context.Cause 的字段名
+    ContextCauseFieldKey = "context_cause"
+    // ContextRemainingFieldKey 是 Context 已结束时记录距截止时间剩余时长的字段名，已超时为负值
+    ContextRemainingFieldKey = "context_remaining"
+)
+
+// ContextCauseExtractors 返回在 Context 已取消或超时时输出 context_cause 与 context_remaining 字段的提取器，
+// 用于排查操作中止的原因，例如通过 context.WithCancelCause 传入的错误。Context 仍有效时不添加字段。
+// 可直接作为 Config.ContextExtractors，或逐个通过 RegisterContextExtractor 注册:
+//
+//	cfg.ContextExtractors = log.ContextCauseExtractors()
+func ContextCauseExtractors() map[string]ContextExtractor {
+    return map[string]ContextExtractor{
+        ContextCauseFieldKey:     extractContextCause,
+        ContextRemainingFieldKey: extractContextRemaining,
+    }
+}
+
+// extractContextCause 返回已结束 Context 的 context.Cause，未指定原因时为 context.Canceled 或 context.DeadlineExceeded
+func extractContextCause(ctx context.Context) (any, bool) {
+    if ctx.Err() == nil {
+        return nil, false
+    }
+    return context.Cause(ctx).Error(), true
+}
+
+// extractContextRemaining 返回已结束 Context 距截止时间的剩余时长，没有截止时间时不添加
+func extractContextRemaining(ctx context.Context) (any, bool) {
+    if ctx.Err() == nil {
+        return nil, false
+    }
+    deadline, ok := ctx.Deadline()
+    if !ok {
+        return nil, false
+    }
+    return time.Until(deadline), true
+}
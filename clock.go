@@ -0,0 +1,61 @@
+package log
+
+import (
+    "fmt"
+    "time"
+)
+
+// ElapsedFieldKey 是 Timer.WithElapsed 添加的耗时字段名
+const ElapsedFieldKey = "elapsed"
+
+// Clock 返回当前的墙上时间，用于生成日志时间戳。测试中可注入固定时间以得到稳定的输出
+type Clock func() time.Time
+
+// TimePrecision 定义日志时间戳的小数秒精度，设置后使用对应精度的 RFC3339 格式并覆盖 Config.TimestampFormat
+type TimePrecision string
+
+const (
+    TimePrecisionSeconds TimePrecision = "s"  // 2006-01-02T15:04:05Z07:00
+    TimePrecisionMillis  TimePrecision = "ms" // 2006-01-02T15:04:05.000Z07:00
+    TimePrecisionMicros  TimePrecision = "us" // 2006-01-02T15:04:05.000000Z07:00
+    TimePrecisionNanos   TimePrecision = "ns" // 2006-01-02T15:04:05.000000000Z07:00
+)
+
+// layout 返回精度对应的时间格式，小数位固定宽度 (末尾的 0 不省略)，便于按列对齐与排序
+func (p TimePrecision) layout() (string, error) {
+    switch p {
+    case TimePrecisionSeconds:
+        return "2006-01-02T15:04:05Z07:00", nil
+    case TimePrecisionMillis:
+        return "2006-01-02T15:04:05.000Z07:00", nil
+    case TimePrecisionMicros:
+        return "2006-01-02T15:04:05.000000Z07:00", nil
+    case TimePrecisionNanos:
+        return "2006-01-02T15:04:05.000000000Z07:00", nil
+    }
+    return "", fmt.Errorf("invalid time precision %q, expected s, ms, us or ns", p)
+}
+
+// Timer 记录开始时刻并基于单调时钟计算耗时，不受 Config.Clock 以及系统时间跳变的影响
+type Timer struct {
+    start time.Time
+}
+
+// StartTimer 以当前时刻 (含单调时钟读数) 创建 Timer
+func StartTimer() Timer {
+    return Timer{start: time.Now()}
+}
+
+// Elapsed 返回自 StartTimer 以来经过的时间
+func (t Timer) Elapsed() time.Duration {
+    return time.Since(t.start)
+}
+
+// WithElapsed 返回带有 elapsed 耗时字段的 Logger (l 未实现 FieldLogger 时原样返回)，例如:
+//
+//	timer := log.StartTimer()
+//	...
+//	timer.WithElapsed(logger).Infof("query done")
+func (t Timer) WithElapsed(l Logger) Logger {
+    return withFields(l, map[string]any{ElapsedFieldKey: t.Elapsed()})
+}
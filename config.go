@@ -1,8 +1,10 @@
 package log
 
 import (
+    "context"
     "io"
     "os"
+    "time"
 
     "github.com/sirupsen/logrus"
 )
@@ -13,18 +15,121 @@ type LogFormat string
 const (
     FormatText LogFormat = "text"
     FormatJSON LogFormat = "json"
+
+    // FormatCustom 表示 Formatter 由 SetFormatterObject 直接安装，不能通过名称创建
+    FormatCustom LogFormat = "custom"
 )
 
 // Config 定义日志库的配置参数
 type Config struct {
     Level           logrus.Level // 日志级别
-    Format          LogFormat    // 日志输出格式 (text/json)
+    Format          LogFormat    // 日志输出格式 (text/json 或通过 RegisterFormatter 注册的格式)
     Output          io.Writer    // 日志输出目标 (例如 os.Stdout, 文件)
     FilePath        string       // 如果输出到文件，指定文件路径
     EnableJSON      bool         // 是否启用 JSON 格式输出
     JSONPretty      bool         // JSON美化输出
+    CompactJSON     bool         // 要求 JSON 输出为紧凑的单行格式 (每条日志一行，无多余空白)，与 JSONPretty 同时开启时 NewLogger 返回错误
     ReportCaller    bool         // 是否报告调用者信息 (文件, 行号, 函数名)
     TimestampFormat string       // 时间戳格式，默认为 time.RFC3339Nano
+    TextLayout      string       // 文本格式的行模板，支持 {time} {level} {msg} {fields}，为空则使用 logrus 默认布局
+    DevMode         bool         // 开发模式，文本格式下将错误字段的错误链与堆栈以缩进多行的形式输出
+    PadLevels       bool         // 文本格式中将大写的级别标签补齐到相同宽度 (如 "INFO   " 与 "WARNING")，便于列对齐
+
+    // 级别标签
+    LevelNames map[logrus.Level]string // 自定义级别标签，例如 {logrus.WarnLevel: "WARN"}，原样用于 text/json 输出 (JSON 需重新编码顶层对象，有额外开销)，注册的格式与 SetFormatterObject 不受影响
+
+    // 字段处理
+    DefaultFields       map[string]any // 每条日志默认携带的字段，优先级低于 Context 字段与显式绑定字段
+    AllowedFields       []string       // 字段白名单，非空时只输出其中的字段 (调用者信息 file/func 也需列出)，time/level/msg 始终保留
+    NormalizeTimeFields bool           // 是否将 time.Duration 字段输出为数值、time.Time 字段按 TimestampFormat 格式化
+    DurationUnit        time.Duration  // time.Duration 字段的数值单位，默认为毫秒
+    SanitizeNewlines    bool           // 文本格式中转义消息与字段中的换行等控制字符，保证每条日志只占一行，默认开启
+    SanitizeUTF8        bool           // 将消息、字段名与字符串字段值中的非法 UTF-8 序列替换为 U+FFFD
+    RespectStructTags   bool           // 结构体字段值 (含切片、数组与 map 中的元素) 按 log 标签处理: `log:"-"` 省略该字段，`log:"redact"` 脱敏
+    FieldNameStyle      FieldNameStyle // 字段名命名风格 (snake/camel/kebab)，内置字段名同样会被转换，为空则保持原样
+    IncludeBuildInfo    bool           // 是否添加构建信息字段 (go_version, vcs_revision, main_version)，构建信息不可用时不添加
+    IncludeK8sMetadata  bool           // 是否添加 Kubernetes 元数据字段 (pod, namespace, node)，取自 Downward API 环境变量 POD_NAME、POD_NAMESPACE、NODE_NAME
+    IncludeLevelNumber  bool           // 是否在 level 之外添加数值级别字段 level_num，取值为 logrus 级别数值 (error=2, warning=3, info=4, debug=5 等)
+    IncludeSequence     bool           // 是否添加 seq 字段: 同一 Logger (含其子 Logger) 内从 1 开始连续递增的序号，顺序与输出顺序一致
+
+    // 字段类型: 键为字段名，值为 string/int/float/bool，编码前将字段转换为声明的类型，便于下游按固定 schema 解析
+    FieldTypes map[string]string // 例如 {"user_id": "string"}，同时匹配加上 FieldPrefix 的字段名；无法转换的值保持原样，每个字段仅首次失败时向 os.Stderr 输出警告
+
+    // 字段前缀: 避免与其他系统合并日志时字段名冲突，time/level/msg 等内置字段不受影响
+    FieldPrefix          string // 为 Context 派生的字段 (request_id、trace_id、自定义字段等) 添加的前缀，例如 "app." 得到 "app.user_id"
+    PrefixExplicitFields bool   // 是否同时为 WithField/WithFields 显式绑定的字段添加前缀
+
+    // 调用者信息
+    CallerLevels       []logrus.Level // 只在这些级别记录调用者信息 (需开启 ReportCaller)，为空时所有级别都记录
+    CallerFields       []string       // 输出的调用者字段 ("file"、"func")，为 nil 时两者都输出，为空切片时都不输出
+    CallerSkipPackages []string       // 查找调用者时跳过的包 (导入路径)，用于让用户自己的日志封装层透明；非空时改为沿调用栈动态查找调用者
+    CallerURIScheme    bool           // 文件字段是否带 "file://" 前缀 (便于 IDE 点击跳转)，默认输出为 "path:line"
+
+    // 调用栈
+    StructuredStacktrace bool // JSON 格式中将 stacktrace 字段 (见 CaptureStacktrace) 输出为 {func, file, line} 对象数组，默认及文本格式下为多行字符串
+
+    // 按级别区分详略
+    ErrorFormatVerbose bool // Error 及以上级别的日志附带调用者 (file/func) 与调用栈 (stacktrace)，其余级别去掉这些字段以保持简洁
+
+    // 二进制字段
+    BytesEncoding    BytesEncoding // []byte 字段的编码方式 (base64/hex)，为空则保持 logrus 默认输出
+    MaxBytesFieldLen int           // []byte 字段编码前保留的最大字节数，超出部分截断，<= 0 表示不截断
+
+    // 退出
+    FatalExitCode int       // Fatal 日志写出后进程的退出码，默认为 1 (为 0 时同样使用 1)
+    ExitFunc      func(int) // Fatal 日志写出后调用的退出函数，为空时使用 os.Exit，测试中可替换以避免进程退出
+
+    // 生命周期
+    Context context.Context // 后台 goroutine (如缓冲输出的定时写出) 随其结束而退出，为空时只在 Close 时退出
+
+    // 时间源
+    Clock Clock // 生成日志时间戳的时钟，为空时使用 time.Now；Timer 计算的耗时始终基于单调时钟，不受其影响
+
+    // 时间戳精度
+    TimePrecision TimePrecision // 时间戳的小数秒精度 (s/ms/us/ns)，非空时使用对应精度的 RFC3339 格式并覆盖 TimestampFormat
+
+    // 请求 ID
+    IDGenerator IDGenerator // EnsureRequestID 生成请求 ID 的方式 (如 UUIDv4Generator、NewULIDGenerator())，为空时生成 32 位十六进制的随机 ID
+
+    // 严重程度
+    DefaultSeverity Severity // 未通过 WithSeverity 指定时默认添加的 severity 字段，为空则不添加
+
+    // Context 字段提取
+    ContextExtractors map[string]ContextExtractor // 本 Logger 专用的 Context 字段提取器，键为字段名，同名时优先于全局注册的提取器
+    BaggageAllowlist  []string                    // 通过 WithBaggage 放入 Context 的键中，需要作为字段输出的键，为空则不输出任何 baggage
+
+    // 按时间轮转文件: RotationFilePattern 非空时代替 FilePath，每个周期写入一个文件，跨过周期边界后的第一次写入时切换
+    RotationFilePattern string          // 文件名模板，支持 %Y %m %d %H 与 %%，例如 "logs/app-%Y-%m-%d.log"；daily 必须包含 %Y %m %d，hourly 还须包含 %H
+    RotationPattern     RotationPattern // 轮转周期 (daily/hourly)，默认为 daily
+    RotationMaxFiles    int             // 最多保留的文件数 (含当前文件)，<= 0 表示不限制
+    RotationMaxAge      time.Duration   // 文件的最长保留时间 (按文件名中的周期时间计算)，<= 0 表示不限制
+
+    // 缓冲输出: 日志先写入内存，缓冲区满、定时器到期或调用 Flush/Close 时写出，Fatal 退出前会自动写出
+    BufferSize     int           // 缓冲区大小 (字节)，> 0 时启用缓冲，仅作用于 Output/FilePath，不影响 ErrorOutput
+    FlushInterval  time.Duration // 定时写出的间隔，默认为 1 秒，<= 0 表示不定时写出
+    SyncFlushLevel logrus.Level  // 达到该级别 (含) 及以上的日志绕过缓冲区立即写出并落盘，默认为 Error
+
+    // 写入错误
+    PropagateWriteErrors bool // 是否记录写入输出目标的错误，通过 Logger.LastError 获取
+
+    // 换行符
+    TrailingNewline TrailingNewline // 每条日志末尾换行符的处理策略 (auto/always/never)，为空时等同于 auto
+
+    // 频率控制
+    KeyedWindow      time.Duration            // ErrorfKeyed 同一去重键的最小输出间隔，默认为 1 分钟
+    LevelSampleRates map[logrus.Level]float64 // 各级别保留日志的比例 (0~1)，未配置的级别全部保留，Fatal 不参与采样
+    AdaptiveLevel    AdaptiveLevel            // 输出速率超过阈值时临时将最低级别提高一档，速率回落后恢复
+
+    // 错误签名采样
+    ErrorSignatureWindow time.Duration // ErrorfSampled 同一错误签名 (消息模板 + 错误类型) 的最小输出间隔，默认为 1 分钟，首次出现总是输出
+
+    // Panic 限流
+    PanicThrottleWindow time.Duration // 相同消息模板 (按 format 字符串而非格式化后的消息) 的 Panicf 日志的最小输出间隔 (panic 本身不受影响)，为 0 时使用 DefaultPanicThrottleWindow，小于 0 表示不限流
+
+    // 按级别分流输出: 达到 ErrorOutputLevel (含) 及以上级别的日志写入 ErrorOutput，其余写入 Output/FilePath
+    SplitErrorStream bool         // 是否启用分流，未指定 ErrorOutput 时写入 os.Stderr
+    ErrorOutput      io.Writer    // 高级别日志的输出目标，非空时即启用分流
+    ErrorOutputLevel logrus.Level // 分流阈值，默认为 Warn
 }
 
 // DefaultConfig 返回一个默认的日志配置
@@ -37,5 +142,19 @@ func DefaultConfig() Config {
         EnableJSON:      false,
         ReportCaller:    true, // 默认开启调用者信息
         TimestampFormat: "2006/01/02 15:04:05.000",
+
+        SanitizeNewlines: true,
+        DurationUnit:     time.Millisecond,
+        KeyedWindow:      DefaultKeyedWindow,
+        FlushInterval:    DefaultFlushInterval,
+        SyncFlushLevel:   logrus.ErrorLevel,
+
+        BytesEncoding:    BytesEncodingBase64,
+        MaxBytesFieldLen: DefaultMaxBytesFieldLen,
+
+        FatalExitCode: 1,
+
+        SplitErrorStream: false,
+        ErrorOutputLevel: logrus.WarnLevel,
     }
 }
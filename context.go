@@ -14,12 +14,17 @@ const (
     TraceIDKey contextKey = "trace_id"
     // SpanIDKey 用于在 Context 中存储 Span ID
     SpanIDKey contextKey = "span_id"
+    // OperationKey 用于在 Context 中存储逻辑操作名
+    OperationKey contextKey = "operation"
     // CustomFieldsKey 用于在 Context 中存储一个 map[string]any，包含任意自定义字段
     CustomFieldsKey contextKey = "custom_fields"
+    // ScopeFieldsKey 用于在 Context 中存储作用域字段栈
+    ScopeFieldsKey contextKey = "scope_fields"
 )
 
-// WithRequestID 将请求 ID 添加到 Context 中
+// WithRequestID 将请求 ID 添加到 Context 中，同时附带派生子请求 ID 所需的计数器 (见 WithChildRequestID)
 func WithRequestID(ctx context.Context, reqID string) context.Context {
+    ctx = context.WithValue(ctx, childCounterKey, &childCounter{parent: reqID})
     return context.WithValue(ctx, RequestIDKey, reqID)
 }
 
@@ -38,12 +43,26 @@ func WithSpanID(ctx context.Context, spanID string) context.Context {
     return context.WithValue(ctx, SpanIDKey, spanID)
 }
 
+// WithOperation 将逻辑操作名添加到 Context 中。
+// 嵌套调用时操作名以 "." 连接成路径，例如先后调用 WithOperation(ctx, "sync") 与 WithOperation(ctx, "fetch")
+// 得到 "sync.fetch"，便于从日志中看出任务的层级关系。
+func WithOperation(ctx context.Context, name string) context.Context {
+    if parent, ok := GetOperation(ctx); ok && parent != "" {
+        name = parent + "." + name
+    }
+    return context.WithValue(ctx, OperationKey, name)
+}
+
 type MetaData map[string]interface{}
 
 // WithCustomField 将单个自定义字段添加到 Context 中。
 // 如果 Context 中已有 CustomFieldsKey，则会更新或添加字段。
+// 新增字段超出 WithCardinalityLimit 设置的上限时丢弃该字段，并记录到 dropped_fields 中。
 func WithCustomField(ctx context.Context, key string, value any) context.Context {
     fields, ok := ctx.Value(CustomFieldsKey).(MetaData)
+    if exceedsCardinalityLimit(ctx, fields, key) {
+        return withDroppedField(ctx, key)
+    }
     if !ok || fields == nil {
         fields = make(MetaData)
     } else {
@@ -58,6 +77,67 @@ func WithCustomField(ctx context.Context, key string, value any) context.Context
     return context.WithValue(ctx, CustomFieldsKey, fields)
 }
 
+// WithoutCustomField 返回移除了自定义字段 key 的 Context，原 Context 中的字段不受影响 (写时复制)
+func WithoutCustomField(ctx context.Context, key string) context.Context {
+    fields, ok := ctx.Value(CustomFieldsKey).(MetaData)
+    if !ok {
+        return ctx
+    }
+    if _, exists := fields[key]; !exists {
+        return ctx
+    }
+    newFields := make(MetaData, len(fields)-1)
+    for k, v := range fields {
+        if k != key {
+            newFields[k] = v
+        }
+    }
+    return context.WithValue(ctx, CustomFieldsKey, newFields)
+}
+
+// droppableKeys 是 WithoutFields 可以按字段名移除的单值字段
+var droppableKeys = []contextKey{
+    RequestIDKey, UserIDKey, TraceIDKey, SpanIDKey, OperationKey, SeverityKey,
+}
+
+// WithoutFields 返回不再携带 keys 中字段的 Context，用于子流程不应继承父级字段的场景，
+// 例如调用匿名子系统前移除 user_id。同名的内置字段 (request_id、user_id 等)、作用域字段与自定义字段都会被移除，
+// 原 Context 不受影响。
+func WithoutFields(ctx context.Context, keys ...string) context.Context {
+    for _, key := range keys {
+        for _, k := range droppableKeys {
+            if string(k) == key && ctx.Value(k) != nil {
+                ctx = context.WithValue(ctx, k, nil)
+            }
+        }
+        ctx = withoutScopeField(ctx, key)
+        ctx = WithoutCustomField(ctx, key)
+    }
+    return ctx
+}
+
+// withoutScopeField 重建不含 key 的作用域字段栈，原有节点不会被修改
+func withoutScopeField(ctx context.Context, key string) context.Context {
+    top, _ := ctx.Value(ScopeFieldsKey).(*scopeField)
+    var kept []*scopeField
+    found := false
+    for f := top; f != nil; f = f.parent {
+        if f.key == key {
+            found = true
+            continue
+        }
+        kept = append(kept, f)
+    }
+    if !found {
+        return ctx
+    }
+    var rebuilt *scopeField
+    for i := len(kept) - 1; i >= 0; i-- {
+        rebuilt = &scopeField{key: kept[i].key, value: kept[i].value, parent: rebuilt}
+    }
+    return context.WithValue(ctx, ScopeFieldsKey, rebuilt)
+}
+
 // GetRequestID 从 Context 中获取请求 ID
 func GetRequestID(ctx context.Context) (string, bool) {
     val, ok := ctx.Value(RequestIDKey).(string)
@@ -82,8 +162,116 @@ func GetSpanID(ctx context.Context) (string, bool) {
     return val, ok
 }
 
+// GetOperation 从 Context 中获取逻辑操作名 (嵌套时为完整路径)
+func GetOperation(ctx context.Context) (string, bool) {
+    val, ok := ctx.Value(OperationKey).(string)
+    return val, ok
+}
+
 // GetCustomFields 从 Context 中获取所有自定义字段
 func GetCustomFields(ctx context.Context) (MetaData, bool) {
     val, ok := ctx.Value(CustomFieldsKey).(MetaData)
     return val, ok
 }
+
+// scopeField 是作用域字段栈中的一个节点，节点不可变，多个 Context 可安全共享
+type scopeField struct {
+    key    string
+    value  any
+    parent *scopeField
+}
+
+// WithScopeField 将字段压入 Context 的作用域字段栈 (类似其他生态中的 MDC)，
+// 配合 PopScopeField / ClearScope 可在子流程结束后移除字段。同名字段以最近压入的为准。
+func WithScopeField(ctx context.Context, key string, value any) context.Context {
+    parent, _ := ctx.Value(ScopeFieldsKey).(*scopeField)
+    return context.WithValue(ctx, ScopeFieldsKey, &scopeField{key: key, value: value, parent: parent})
+}
+
+// PopScopeField 返回移除了最近一次压入的作用域字段的 Context
+func PopScopeField(ctx context.Context) context.Context {
+    top, _ := ctx.Value(ScopeFieldsKey).(*scopeField)
+    if top == nil {
+        return ctx
+    }
+    return context.WithValue(ctx, ScopeFieldsKey, top.parent)
+}
+
+// ClearScope 返回清空了全部作用域字段的 Context
+func ClearScope(ctx context.Context) context.Context {
+    if top, _ := ctx.Value(ScopeFieldsKey).(*scopeField); top == nil {
+        return ctx
+    }
+    return context.WithValue(ctx, ScopeFieldsKey, (*scopeField)(nil))
+}
+
+// GetScopeFields 从 Context 中获取当前生效的作用域字段
+func GetScopeFields(ctx context.Context) (MetaData, bool) {
+    top, _ := ctx.Value(ScopeFieldsKey).(*scopeField)
+    if top == nil {
+        return nil, false
+    }
+    fields := make(MetaData)
+    for f := top; f != nil; f = f.parent {
+        if _, exists := fields[f.key]; !exists {
+            fields[f.key] = f.value
+        }
+    }
+    return fields, true
+}
+
+// jobContextKeys 是 WithJobContext 从任务 Context 中复制的单值字段
+var jobContextKeys = []contextKey{
+    RequestIDKey, childCounterKey, UserIDKey, TraceIDKey, SpanIDKey, OperationKey, SeverityKey, BaggageKey,
+    droppedFieldsKey,
+}
+
+// WithJobContext 将任务 Context (job) 中的日志字段合并到 parent 中，用于工作池等场景:
+// 返回的 Context 继承 parent 的取消与超时，同时携带 job 的请求 ID、链路 ID 等关联字段。
+// 同名字段以 job 为准；自定义字段合并两者；job 的作用域字段压在 parent 的作用域字段之上。
+func WithJobContext(parent, job context.Context) context.Context {
+    ctx := parent
+    for _, key := range jobContextKeys {
+        if v := job.Value(key); v != nil {
+            ctx = context.WithValue(ctx, key, v)
+        }
+    }
+
+    if jobFields, ok := GetCustomFields(job); ok && len(jobFields) > 0 {
+        parentFields, _ := GetCustomFields(parent)
+        merged := make(MetaData, len(parentFields)+len(jobFields))
+        for k, v := range parentFields {
+            merged[k] = v
+        }
+        for k, v := range jobFields {
+            merged[k] = v
+        }
+        ctx = context.WithValue(ctx, CustomFieldsKey, merged)
+    }
+
+    // 按压入顺序将 job 的作用域字段依次压入
+    var stack []*scopeField
+    top, _ := job.Value(ScopeFieldsKey).(*scopeField)
+    for f := top; f != nil; f = f.parent {
+        stack = append(stack, f)
+    }
+    for i := len(stack) - 1; i >= 0; i-- {
+        ctx = WithScopeField(ctx, stack[i].key, stack[i].value)
+    }
+    return ctx
+}
+
+// MergeContexts 合并两个 Context 的日志字段，例如将服务端请求 Context 与客户端回调派生的 Context 组合。
+// 返回的 Context 继承 a 的取消、超时与其他值；日志字段为两者的并集，优先级如下:
+//   - 请求 ID、用户 ID、链路 ID、操作名、严重程度等单值字段: b 中存在时以 b 为准
+//   - 自定义字段与 baggage: 按键合并，同名键以 b 为准
+//   - 作用域字段: b 的作用域字段压在 a 的之上，同名时 b 的值生效
+func MergeContexts(a, b context.Context) context.Context {
+    ctx := WithJobContext(a, b)
+    if baggage, ok := GetBaggage(a); ok {
+        if other, ok := GetBaggage(b); ok {
+            ctx = WithBaggage(context.WithValue(ctx, BaggageKey, baggage), other)
+        }
+    }
+    return ctx
+}
@@ -0,0 +1,197 @@
+package log
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "reflect"
+    "sort"
+    "strings"
+
+    "github.com/sirupsen/logrus"
+)
+
+// LogStartupConfig 以一条 Info 日志输出 Logger 的生效配置，便于排查日志格式或级别不符合预期的问题。
+// 该日志通过 WithForcedLevel 强制输出，Logger 级别为 Warn、Error 时同样可见 (Fatal/Panic 时不输出)。
+// 只输出配置的结构信息，DefaultFields 等可能包含敏感数据的配置仅输出键名。l 未实现 ConfigProvider 时不输出。
+func LogStartupConfig(l Logger) {
+    cp, ok := l.(ConfigProvider)
+    if !ok {
+        return
+    }
+    cfg := cp.GetConfig()
+
+    format := cfg.Format
+    if cfg.EnableJSON {
+        format = FormatJSON
+    }
+    fields := map[string]any{
+        "log_level":         cfg.Level.String(),
+        "log_format":        string(format),
+        "log_output":        cfg.outputDescription(),
+        "log_report_caller": cfg.ReportCaller,
+        "log_json_pretty":   cfg.JSONPretty,
+    }
+    if features := cfg.enabledFeatures(); len(features) > 0 {
+        fields["log_features"] = features
+    }
+    if errOut := cfg.errorOutput(); errOut != nil {
+        fields["log_error_output"] = describeOutput(errOut, "")
+        fields["log_error_output_level"] = cfg.ErrorOutputLevel.String()
+    }
+    if len(cfg.DefaultFields) > 0 {
+        keys := make([]string, 0, len(cfg.DefaultFields))
+        for k := range cfg.DefaultFields {
+            keys = append(keys, k)
+        }
+        sort.Strings(keys)
+        fields["log_default_fields"] = keys
+    }
+
+    withFields(l, fields).InfoContextf(WithForcedLevel(context.Background(), logrus.InfoLevel), "logger initialized")
+}
+
+// outputDescription 返回主输出目标的可读描述，按时间轮转的文件输出为 "rotating:" 加文件名模板
+func (c Config) outputDescription() string {
+    if c.RotationFilePattern != "" {
+        return "rotating:" + c.RotationFilePattern
+    }
+    return describeOutput(c.Output, c.FilePath)
+}
+
+// describeOutput 返回输出目标的可读描述
+func describeOutput(w io.Writer, filePath string) string {
+    if filePath != "" {
+        return "file:" + filePath
+    }
+    switch w {
+    case nil:
+        return "none"
+    case os.Stdout:
+        return "stdout"
+    case os.Stderr:
+        return "stderr"
+    }
+    return fmt.Sprintf("%T", w)
+}
+
+// DescribeConfig 返回 Logger 当前生效状态的多行可读描述 (级别、格式、输出目标、已启用的功能与已注册的 Hook)，
+// 供排查问题时整体输出。与 LogStartupConfig 相同，DefaultFields 只列出键名。
+func (l *LogrusLogger) DescribeConfig() string {
+    cfg := l.GetConfig()
+
+    var b strings.Builder
+    format := cfg.Format
+    if cfg.EnableJSON {
+        format = FormatJSON
+    }
+    fmt.Fprintf(&b, "level: %s\n", l.Logger.GetLevel())
+    fmt.Fprintf(&b, "format: %s\n", format)
+    fmt.Fprintf(&b, "output: %s\n", cfg.outputDescription())
+    if errOut := cfg.errorOutput(); errOut != nil {
+        fmt.Fprintf(&b, "error output: %s (>= %s)\n", describeOutput(errOut, ""), cfg.ErrorOutputLevel)
+    }
+    if cfg.BufferSize > 0 {
+        fmt.Fprintf(&b, "buffer: %d bytes, flush interval %s\n", cfg.BufferSize, cfg.FlushInterval)
+    }
+    fmt.Fprintf(&b, "report caller: %t\n", cfg.ReportCaller)
+    if features := cfg.enabledFeatures(); len(features) > 0 {
+        fmt.Fprintf(&b, "features: %s\n", strings.Join(features, ", "))
+    }
+    if len(cfg.DefaultFields) > 0 {
+        keys := make([]string, 0, len(cfg.DefaultFields))
+        for k := range cfg.DefaultFields {
+            keys = append(keys, k)
+        }
+        sort.Strings(keys)
+        fmt.Fprintf(&b, "default fields: %s\n", strings.Join(keys, ", "))
+    }
+    b.WriteString("hooks:\n")
+    for _, hook := range l.describeHooks() {
+        fmt.Fprintf(&b, "  - %s\n", hook)
+    }
+    return b.String()
+}
+
+// enabledFeatures 返回配置中已开启的可选功能名称，按固定顺序排列
+func (c Config) enabledFeatures() []string {
+    flags := []struct {
+        name string
+        on   bool
+    }{
+        {"json_pretty", c.JSONPretty},
+        {"compact_json", c.CompactJSON},
+        {"dev_mode", c.DevMode},
+        {"sanitize_newlines", c.SanitizeNewlines},
+        {"sanitize_utf8", c.SanitizeUTF8},
+        {"struct_tags", c.RespectStructTags},
+        {"normalize_time_fields", c.NormalizeTimeFields},
+        {"build_info", c.IncludeBuildInfo},
+        {"k8s_metadata", c.IncludeK8sMetadata},
+        {"level_number", c.IncludeLevelNumber},
+        {"sequence", c.IncludeSequence},
+        {"structured_stacktrace", c.StructuredStacktrace},
+        {"error_format_verbose", c.ErrorFormatVerbose},
+        {"allowed_fields", len(c.AllowedFields) > 0},
+        {"field_prefix", c.FieldPrefix != ""},
+        {"baggage_allowlist", len(c.BaggageAllowlist) > 0},
+        {"level_sampling", len(c.LevelSampleRates) > 0},
+        {"adaptive_level", c.AdaptiveLevel.RateThreshold > 0},
+        {"propagate_write_errors", c.PropagateWriteErrors},
+    }
+    var names []string
+    for _, f := range flags {
+        if f.on {
+            names = append(names, f.name)
+        }
+    }
+    return names
+}
+
+// describeHooks 按注册顺序返回已注册 Hook 的类型名，通过 AddHookWithPriority 注册的 Hook 附带优先级
+func (l *LogrusLogger) describeHooks() []string {
+    var seen []logrus.Hook
+    var names []string
+    for _, hook := range l.hookSnapshot() {
+        if containsHook(seen, hook) {
+            continue
+        }
+        seen = append(seen, hook)
+        if d, ok := hook.(*hookDispatcher); ok {
+            d.mu.RLock()
+            for _, h := range d.hooks {
+                names = append(names, fmt.Sprintf("%T (priority %d)", h.hook, h.priority))
+            }
+            d.mu.RUnlock()
+            continue
+        }
+        names = append(names, fmt.Sprintf("%T", hook))
+    }
+    return names
+}
+
+// hookSnapshot 在 hookMu 保护下按级别顺序复制已注册的 Hook (同一 Hook 注册在多个级别时会重复出现)
+func (l *LogrusLogger) hookSnapshot() []logrus.Hook {
+    l.hookMu.RLock()
+    defer l.hookMu.RUnlock()
+    var hooks []logrus.Hook
+    for _, level := range logrus.AllLevels {
+        hooks = append(hooks, l.Logger.Hooks[level]...)
+    }
+    return hooks
+}
+
+// containsHook 判断 hooks 中是否已有 hook。不可比较的值类型 Hook (如包含切片或 map 的结构体) 不能用作 map 键或 ==，按内容比较
+func containsHook(hooks []logrus.Hook, hook logrus.Hook) bool {
+    comparable := reflect.TypeOf(hook).Comparable()
+    for _, h := range hooks {
+        if reflect.TypeOf(h) != reflect.TypeOf(hook) {
+            continue
+        }
+        if comparable && h == hook || !comparable && reflect.DeepEqual(h, hook) {
+            return true
+        }
+    }
+    return false
+}
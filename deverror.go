@@ -0,0 +1,86 @@
+package log
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+
+    "github.com/sirupsen/logrus"
+)
+
+// devErrorFormatter 用于开发模式的文本输出：错误字段仍以单行形式出现在日志行中，
+// 其错误链与堆栈 (如 pkg/errors 的 %+v 输出) 以缩进的形式追加在日志行之后，便于阅读。
+type devErrorFormatter struct {
+    logrus.Formatter
+}
+
+// Format 实现 logrus.Formatter
+func (f *devErrorFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+    var keys []string
+    for k, v := range entry.Data {
+        if _, ok := v.(error); ok {
+            keys = append(keys, k)
+        }
+    }
+    // 先取出错误，避免后续 Formatter 修改 entry.Data
+    errs := make([]error, len(keys))
+    sort.Strings(keys)
+    for i, k := range keys {
+        errs[i] = entry.Data[k].(error)
+    }
+
+    out, err := f.Formatter.Format(entry)
+    if err != nil || len(keys) == 0 {
+        return out, err
+    }
+
+    var b strings.Builder
+    for i, k := range keys {
+        writeErrorBlock(&b, k, errs[i])
+    }
+    return append(out, b.String()...), nil
+}
+
+// writeErrorBlock 输出单个错误的缩进块：错误链中每一层占一行，带堆栈的错误在其后输出堆栈
+func writeErrorBlock(b *strings.Builder, key string, err error) {
+    fmt.Fprintf(b, "    %s: %s\n", key, err.Error())
+    writeErrorStack(b, err)
+    for _, cause := range errorChain(err) {
+        fmt.Fprintf(b, "        caused by: %s\n", cause.Error())
+        writeErrorStack(b, cause)
+    }
+}
+
+// writeErrorStack 当错误的 %+v 输出包含多行 (例如 pkg/errors 的堆栈) 时，缩进输出除首行外的内容
+func writeErrorStack(b *strings.Builder, err error) {
+    detail := fmt.Sprintf("%+v", err)
+    if detail == err.Error() || !strings.Contains(detail, "\n") {
+        return
+    }
+    lines := strings.Split(strings.TrimRight(detail, "\n"), "\n")
+    for _, line := range lines[1:] {
+        b.WriteString("            ")
+        b.WriteString(strings.TrimLeft(line, "\t"))
+        b.WriteByte('\n')
+    }
+}
+
+// errorChain 返回 err 包装的下层错误 (不含 err 自身，深度优先)，支持 errors.Join 产生的多错误
+func errorChain(err error) []error {
+    var chain []error
+    var inner []error
+    switch x := err.(type) {
+    case interface{ Unwrap() []error }:
+        inner = x.Unwrap()
+    default:
+        if e := errors.Unwrap(err); e != nil {
+            inner = []error{e}
+        }
+    }
+    for _, e := range inner {
+        chain = append(chain, e)
+        chain = append(chain, errorChain(e)...)
+    }
+    return chain
+}
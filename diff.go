@@ -0,0 +1,135 @@
+package log
+
+import (
+    "context"
+    "reflect"
+
+    "github.com/sirupsen/logrus"
+)
+
+// ChangesFieldKey 是 LogDiff 输出变更内容的字段名
+const ChangesFieldKey = "changes"
+
+// diffRootKey 是无法按字段比较的值 (非结构体、非 map) 发生变化时使用的键
+const diffRootKey = "value"
+
+// FieldChange 描述一个字段的变化
+type FieldChange struct {
+    Old any `json:"old"`
+    New any `json:"new"`
+}
+
+// Diff 比较 old 与 new，返回发生变化的字段，键为以 "." 连接的字段路径 (如 "Address.City")，值为 *FieldChange。
+// 结构体按导出字段逐个递归比较 (字段名优先使用 json 标签)，未导出字段被忽略；string 键的 map 按键比较；
+// 其他值 (切片、time.Time 等) 整体比较。`log:"-"` 字段不参与比较，`log:"redact"` 字段变化时新旧值均为 RedactedValue。
+// 任一参数为 nil 时整体比较，两者均为 nil 时返回空 map。
+func Diff(old, new any) map[string]any {
+    changes := make(map[string]any)
+    diffValues(changes, "", reflect.ValueOf(old), reflect.ValueOf(new))
+    return changes
+}
+
+// diffValues 递归比较 a、b，将变化写入 changes
+func diffValues(changes map[string]any, path string, a, b reflect.Value) {
+    a, b = indirectValue(a), indirectValue(b)
+    if a.IsValid() && b.IsValid() && a.Type() == b.Type() {
+        switch {
+        case a.Kind() == reflect.Struct:
+            if info := getStructInfo(a.Type()); len(info.fields) > 0 {
+                diffStructs(changes, path, info, a, b)
+                return
+            }
+        case a.Kind() == reflect.Map && a.Type().Key().Kind() == reflect.String:
+            diffMaps(changes, path, a, b)
+            return
+        }
+    }
+    av, bv := interfaceOf(a), interfaceOf(b)
+    if !reflect.DeepEqual(av, bv) {
+        changes[diffPath(path, diffRootKey)] = &FieldChange{Old: av, New: bv}
+    }
+}
+
+// diffStructs 逐个比较结构体的导出字段
+func diffStructs(changes map[string]any, path string, info *structInfo, a, b reflect.Value) {
+    for _, f := range info.fields {
+        if f.omit {
+            continue
+        }
+        fieldPath := joinPath(path, f.name)
+        fa, fb := a.Field(f.index), b.Field(f.index)
+        if f.redact {
+            if !reflect.DeepEqual(interfaceOf(fa), interfaceOf(fb)) {
+                changes[fieldPath] = &FieldChange{Old: RedactedValue, New: RedactedValue}
+            }
+            continue
+        }
+        diffValues(changes, fieldPath, fa, fb)
+    }
+}
+
+// diffMaps 按键比较 map，只存在于一侧的键视为从 nil 变化或变化为 nil
+func diffMaps(changes map[string]any, path string, a, b reflect.Value) {
+    for _, key := range a.MapKeys() {
+        diffValues(changes, joinPath(path, key.String()), a.MapIndex(key), b.MapIndex(key))
+    }
+    for _, key := range b.MapKeys() {
+        if !a.MapIndex(key).IsValid() {
+            diffValues(changes, joinPath(path, key.String()), reflect.Value{}, b.MapIndex(key))
+        }
+    }
+}
+
+// indirectValue 解开指针与接口，遇到 nil 时返回无效的 Value
+func indirectValue(v reflect.Value) reflect.Value {
+    for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+        if v.IsNil() {
+            return reflect.Value{}
+        }
+        v = v.Elem()
+    }
+    return v
+}
+
+// interfaceOf 返回 Value 持有的值，无效的 Value 返回 nil
+func interfaceOf(v reflect.Value) any {
+    if !v.IsValid() || !v.CanInterface() {
+        return nil
+    }
+    return v.Interface()
+}
+
+func joinPath(path, name string) string {
+    if path == "" {
+        return name
+    }
+    return path + "." + name
+}
+
+// diffPath 返回整体比较时使用的键，根路径使用 fallback
+func diffPath(path, fallback string) string {
+    if path == "" {
+        return fallback
+    }
+    return path
+}
+
+// LogDiff 比较 old 与 new，并通过全局 Logger 以 level 输出一条带 changes 字段的日志，没有变化时不输出。
+// Fatal/Panic 级别按 Error 输出，不会退出进程
+func LogDiff(ctx context.Context, level logrus.Level, msg string, old, new any) {
+    changes := Diff(old, new)
+    if len(changes) == 0 {
+        return
+    }
+    l := globalLogrusLogger().WithField(ChangesFieldKey, changes)
+    switch level {
+    case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+        l.ErrorContextf(ctx, "%s", msg)
+    case logrus.WarnLevel:
+        l.WarnContextf(ctx, "%s", msg)
+    case logrus.InfoLevel:
+        l.InfoContextf(ctx, "%s", msg)
+    default:
+        l.DebugContextf(ctx, "%s", msg)
+    }
+}
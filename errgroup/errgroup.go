@@ -0,0 +1,70 @@
+// Package errgroup 在 golang.org/x/sync/errgroup 之上提供启动 goroutine 的辅助函数:
+// 子 goroutine 使用父 Context 记录日志，自动携带请求 ID、trace_id 等关联字段，
+// 并在 panic 时恢复、记录日志，以错误的形式返回给 Group，而不是让进程崩溃。
+// 该包是可选依赖，只有引入它的程序才会链接 x/sync。
+package errgroup
+
+import (
+    "context"
+    "fmt"
+    "runtime/debug"
+
+    "golang.org/x/sync/errgroup"
+
+    "github.com/sapaude/go-shims/x/log"
+)
+
+// PanicStackFieldKey 是 panic 日志中存放调用栈的字段名
+const PanicStackFieldKey = "panic_stack"
+
+// PanicError 是 goroutine panic 被恢复后返回给 Group 的错误
+type PanicError struct {
+    Value any    // panic 的值
+    Stack []byte // panic 时的调用栈
+}
+
+// Error 实现 error
+func (e *PanicError) Error() string {
+    return fmt.Sprintf("goroutine panic: %v", e.Value)
+}
+
+// Option 定义 Go 的可选配置
+type Option func(*options)
+
+type options struct {
+    logger log.Logger
+}
+
+// WithLogger 指定记录 panic 的 Logger，默认使用全局 Logger
+func WithLogger(l log.Logger) Option {
+    return func(o *options) {
+        o.logger = l
+    }
+}
+
+// Go 在 g 中启动 fn，并将 ctx 传给 fn，使子 goroutine 的日志携带 ctx 中的关联字段。
+// 通常传入 errgroup.WithContext 返回的 Context，它由父 Context 派生，同时具备取消与日志字段。
+// fn panic 时会以 Error 级别记录 panic 值与调用栈，并向 Group 返回 *PanicError。
+func Go(ctx context.Context, g *errgroup.Group, fn func(ctx context.Context) error, opts ...Option) {
+    o := &options{}
+    for _, opt := range opts {
+        opt(o)
+    }
+    g.Go(func() (err error) {
+        defer func() {
+            if r := recover(); r != nil {
+                perr := &PanicError{Value: r, Stack: debug.Stack()}
+                logger := o.logger
+                if logger == nil {
+                    logger = log.GetGlobalLogger()
+                }
+                if fl, ok := logger.(log.FieldLogger); ok {
+                    logger = fl.WithField(PanicStackFieldKey, string(perr.Stack))
+                }
+                logger.ErrorContextf(ctx, "%v", perr)
+                err = perr
+            }
+        }()
+        return fn(ctx)
+    })
+}
@@ -0,0 +1,56 @@
+package log
+
+import (
+    "context"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+// ContextExtractor 从 Context 中提取一个日志字段的值，ok 为 false 时不添加该字段
+type ContextExtractor func(ctx context.Context) (value any, ok bool)
+
+var (
+    globalExtractors   = map[string]ContextExtractor{}
+    globalExtractorsMu sync.RWMutex
+)
+
+// RegisterContextExtractor 注册一个对所有 Logger 生效的 Context 字段提取器，通常在 init 中调用。
+// 同名字段以 Config.ContextExtractors 中的提取器优先。
+func RegisterContextExtractor(field string, extractor ContextExtractor) {
+    globalExtractorsMu.Lock()
+    defer globalExtractorsMu.Unlock()
+    globalExtractors[field] = extractor
+}
+
+// UnregisterContextExtractor 移除通过 RegisterContextExtractor 注册的提取器
+func UnregisterContextExtractor(field string) {
+    globalExtractorsMu.Lock()
+    defer globalExtractorsMu.Unlock()
+    delete(globalExtractors, field)
+}
+
+// applyExtractors 依次执行 Logger 自身与全局注册的提取器，字段名按 Config.FieldPrefix 添加前缀，不覆盖已存在的字段
+func (l *LogrusLogger) applyExtractors(ctx context.Context, entry *logrus.Entry) {
+    for field, extract := range l.config.ContextExtractors {
+        field = l.prefixed(field)
+        if _, exists := entry.Data[field]; exists {
+            continue
+        }
+        if v, ok := extract(ctx); ok {
+            entry.Data[field] = v
+        }
+    }
+
+    globalExtractorsMu.RLock()
+    defer globalExtractorsMu.RUnlock()
+    for field, extract := range globalExtractors {
+        field = l.prefixed(field)
+        if _, exists := entry.Data[field]; exists {
+            continue
+        }
+        if v, ok := extract(ctx); ok {
+            entry.Data[field] = v
+        }
+    }
+}
@@ -0,0 +1,58 @@
+package log
+
+import (
+    "context"
+    "os"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// fatalLogger 由可以把 Fatal 拆成“写出”与“退出”两步的 Logger 实现，
+// MultiLogger 借此先把 Fatal 日志写入所有 Logger 并写出缓冲区，最后只退出一次
+type fatalLogger interface {
+    // writeFatal 写出一条 Fatal 级别的日志但不退出，t 为零值时使用当前时间
+    writeFatal(ctx context.Context, t time.Time, format string, args []any)
+    // exitFatal 写出缓冲区并按配置的退出码退出
+    exitFatal()
+}
+
+func (l *LogrusLogger) writeFatal(ctx context.Context, t time.Time, format string, args []any) {
+    entry := l.newEntry(ctx)
+    if !t.IsZero() {
+        entry.Time = t
+    }
+    fatalLogf(entry, format, args...)
+}
+
+// fatalLogf 在调用栈中占据与 entry.Fatalf 相同的层级，保证 CallerSkipFrames 不变。
+// entry.Logf 在 Fatal 级别只写出日志，退出由 entry.Fatalf 负责，这里不会退出
+func fatalLogf(entry *logrus.Entry, format string, args ...any) {
+    entry.Logf(logrus.FatalLevel, format, args...)
+}
+
+func (l *LogrusLogger) exitFatal() {
+    l.Logger.Exit(1)
+}
+
+// newExitFunc 返回 Fatal 日志写出后由 logrus 调用的退出函数:
+// 先写出缓冲区中的日志并将文件落盘，保证 Fatal 日志本身不会丢失，再以 Config.FatalExitCode 退出
+func newExitFunc(cfg Config, buffer *bufferedWriter, file fileSink) func(int) {
+    exit := cfg.ExitFunc
+    if exit == nil {
+        exit = os.Exit
+    }
+    code := cfg.FatalExitCode
+    if code == 0 {
+        code = 1
+    }
+    return func(int) {
+        if buffer != nil {
+            _ = buffer.Flush()
+        }
+        if file != nil {
+            _ = file.Sync()
+        }
+        exit(code)
+    }
+}
@@ -0,0 +1,177 @@
+package log
+
+import (
+    "fmt"
+    "maps"
+    "os"
+    "slices"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "unicode"
+
+    "github.com/sirupsen/logrus"
+)
+
+// FieldNameStyle 定义字段名的命名风格
+type FieldNameStyle string
+
+const (
+    FieldNameStyleSnake FieldNameStyle = "snake" // request_id
+    FieldNameStyleCamel FieldNameStyle = "camel" // requestId
+    FieldNameStyleKebab FieldNameStyle = "kebab" // request-id
+)
+
+// validate 校验命名风格，空值表示不做转换
+func (s FieldNameStyle) validate() error {
+    switch s {
+    case "", FieldNameStyleSnake, FieldNameStyleCamel, FieldNameStyleKebab:
+        return nil
+    }
+    return fmt.Errorf("invalid field name style %q", s)
+}
+
+// convert 按命名风格转换字段名。以 '.' 分隔的各段 (如 "http.statusCode") 分别转换，'.' 保持不变
+func (s FieldNameStyle) convert(key string) string {
+    if strings.Contains(key, ".") {
+        segments := strings.Split(key, ".")
+        for i, seg := range segments {
+            segments[i] = s.convert(seg)
+        }
+        return strings.Join(segments, ".")
+    }
+    words := splitFieldName(key)
+    if len(words) == 0 {
+        return key
+    }
+    switch s {
+    case FieldNameStyleSnake:
+        return strings.Join(words, "_")
+    case FieldNameStyleKebab:
+        return strings.Join(words, "-")
+    case FieldNameStyleCamel:
+        var b strings.Builder
+        b.WriteString(words[0])
+        for _, w := range words[1:] {
+            b.WriteString(strings.ToUpper(w[:1]))
+            b.WriteString(w[1:])
+        }
+        return b.String()
+    }
+    return key
+}
+
+// splitFieldName 将字段名拆分为小写单词，支持 snake_case、kebab-case、camelCase 以及连续大写的缩写 (如 requestID)
+func splitFieldName(key string) []string {
+    var words []string
+    var cur []rune
+    runes := []rune(key)
+    flush := func() {
+        if len(cur) > 0 {
+            words = append(words, strings.ToLower(string(cur)))
+            cur = cur[:0]
+        }
+    }
+    for i, r := range runes {
+        switch {
+        case r == '_' || r == '-' || unicode.IsSpace(r):
+            flush()
+            continue
+        case unicode.IsUpper(r) && len(cur) > 0:
+            prev := runes[i-1]
+            nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+            // fooBar 或 HTTPServer 中 S 的位置开始新单词
+            if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+                flush()
+            }
+        }
+        cur = append(cur, r)
+    }
+    flush()
+    return words
+}
+
+// maxFieldNameCache 是字段名转换缓存的条目上限，超出后新的字段名每次重新转换，避免动态字段名使缓存无限增长
+const maxFieldNameCache = 4096
+
+// fieldNameConverter 按命名风格转换字段名并缓存结果，字段名以 prefix (Config.FieldPrefix) 开头时前缀保持原样
+type fieldNameConverter struct {
+    style  FieldNameStyle
+    prefix string
+
+    cache sync.Map // 原字段名 -> 转换后的字段名
+    size  atomic.Int64
+}
+
+func (c *fieldNameConverter) convert(key string) string {
+    if name, ok := c.cache.Load(key); ok {
+        return name.(string)
+    }
+    name := c.style.convert(key)
+    if rest, ok := strings.CutPrefix(key, c.prefix); ok && c.prefix != "" {
+        name = c.prefix + c.style.convert(rest)
+    }
+    if c.size.Load() < maxFieldNameCache {
+        if _, loaded := c.cache.LoadOrStore(key, name); !loaded {
+            c.size.Add(1)
+        }
+    }
+    return name
+}
+
+// normalizeFieldNames 按命名风格重命名 entry.Data 中的全部字段，prefix 为 Config.FieldPrefix，保持原样不转换。
+// 内置字段 (如 request_id、trace_id、file、func) 同样会被转换，time/level/msg 为单个单词，不受影响。
+// 多个字段转换后同名 (如 userId 与 user_id) 时，原名已符合命名风格的字段优先，其余按原名排序靠前者优先，
+// 未能使用转换后名称的字段保留原名，每个字段只在第一次冲突时向 os.Stderr 输出一条警告。
+func normalizeFieldNames(style FieldNameStyle, prefix string) EntryTransform {
+    c := &fieldNameConverter{style: style, prefix: prefix}
+    var warned sync.Map // map[string]struct{}
+    return func(entry *logrus.Entry) {
+        data := make(logrus.Fields, len(entry.Data))
+        collided := false
+        for k, v := range entry.Data {
+            name := c.convert(k)
+            if _, exists := data[name]; exists {
+                collided = true
+                break
+            }
+            data[name] = v
+        }
+        if collided {
+            data = renameDeterministic(entry.Data, c.convert, func(key, name string) {
+                if _, loaded := warned.LoadOrStore(key, struct{}{}); !loaded {
+                    fmt.Fprintf(os.Stderr, "log: field %q keeps its name: %q is already used by another field\n", key, name)
+                }
+            })
+        }
+        entry.Data = data
+    }
+}
+
+// renameDeterministic 在转换后的字段名冲突时按固定顺序重命名: 原名已等于转换结果的字段先占用名称，
+// 其余字段按原名排序依次占用，名称已被占用的字段保留原名并通过 collide 报告
+func renameDeterministic(fields logrus.Fields, convert func(string) string, collide func(key, name string)) logrus.Fields {
+    keys := slices.Sorted(maps.Keys(fields))
+    names := make(map[string]string, len(keys))
+    for _, k := range keys {
+        names[k] = convert(k)
+    }
+    data := make(logrus.Fields, len(fields))
+    for _, k := range keys {
+        if names[k] == k {
+            data[k] = fields[k]
+        }
+    }
+    for _, k := range keys {
+        name := names[k]
+        if name == k {
+            continue
+        }
+        if _, taken := data[name]; taken {
+            collide(k, name)
+            name = k
+        }
+        data[name] = fields[k]
+    }
+    return data
+}
@@ -0,0 +1,112 @@
+package log
+
+import (
+    "fmt"
+    "math"
+    "os"
+    "reflect"
+    "strconv"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+// Config.FieldTypes 支持的字段类型
+const (
+    FieldTypeString = "string"
+    FieldTypeInt    = "int"
+    FieldTypeFloat  = "float"
+    FieldTypeBool   = "bool"
+)
+
+// validateFieldTypes 检查 Config.FieldTypes 中只使用支持的类型
+func validateFieldTypes(types map[string]string) error {
+    for field, typ := range types {
+        switch typ {
+        case FieldTypeString, FieldTypeInt, FieldTypeFloat, FieldTypeBool:
+        default:
+            return fmt.Errorf("invalid type %q for field %q, expected string, int, float or bool", typ, field)
+        }
+    }
+    return nil
+}
+
+// coerceFieldTypes 将声明了类型的字段转换为该类型，保证同一字段在各条日志中的 JSON 类型一致。
+// 字段名同时按原名与加上 prefix (Config.FieldPrefix) 后的名称匹配，声明时不必关心字段是否带前缀。
+// 无法转换的值保持原样，每个字段只在第一次失败时向 os.Stderr 输出一条警告
+// (此时持有 Logger 的锁，无法通过 Logger 自身输出)。
+func coerceFieldTypes(types map[string]string, prefix string) EntryTransform {
+    var warned sync.Map // map[string]struct{}
+    return func(entry *logrus.Entry) {
+        for field, typ := range types {
+            coerceField(entry, field, typ, &warned)
+            if prefix != "" {
+                coerceField(entry, prefix+field, typ, &warned)
+            }
+        }
+    }
+}
+
+// coerceField 转换 entry 中名为 field 的字段，字段不存在或为 nil 时忽略
+func coerceField(entry *logrus.Entry, field, typ string, warned *sync.Map) {
+    v, ok := entry.Data[field]
+    if !ok || v == nil {
+        return
+    }
+    coerced, err := coerceValue(v, typ)
+    if err != nil {
+        if _, loaded := warned.LoadOrStore(field, struct{}{}); !loaded {
+            fmt.Fprintf(os.Stderr, "log: cannot coerce field %q to %s: %v\n", field, typ, err)
+        }
+        return
+    }
+    entry.Data[field] = coerced
+}
+
+// coerceValue 将 v 转换为 typ 对应的 Go 类型 (string、int64、float64、bool)
+func coerceValue(v any, typ string) (any, error) {
+    if typ == FieldTypeString {
+        if s, ok := v.(string); ok {
+            return s, nil
+        }
+        return fmt.Sprint(v), nil
+    }
+
+    rv := reflect.ValueOf(v)
+    switch typ {
+    case FieldTypeInt:
+        switch rv.Kind() {
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            return rv.Int(), nil
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+            if u := rv.Uint(); u <= math.MaxInt64 {
+                return int64(u), nil
+            }
+        case reflect.Float32, reflect.Float64:
+            if f := rv.Float(); f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+                return int64(f), nil
+            }
+        case reflect.String:
+            return strconv.ParseInt(rv.String(), 10, 64)
+        }
+    case FieldTypeFloat:
+        switch rv.Kind() {
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            return float64(rv.Int()), nil
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+            return float64(rv.Uint()), nil
+        case reflect.Float32, reflect.Float64:
+            return rv.Float(), nil
+        case reflect.String:
+            return strconv.ParseFloat(rv.String(), 64)
+        }
+    case FieldTypeBool:
+        switch rv.Kind() {
+        case reflect.Bool:
+            return rv.Bool(), nil
+        case reflect.String:
+            return strconv.ParseBool(rv.String())
+        }
+    }
+    return nil, fmt.Errorf("unsupported value %v (%T)", v, v)
+}
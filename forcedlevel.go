@@ -0,0 +1,91 @@
+package log
+
+import (
+    "context"
+
+    "github.com/sirupsen/logrus"
+)
+
+// ForcedLevelKey 用于在 Context 中存储强制生效的日志级别
+const ForcedLevelKey contextKey = "forced_level"
+
+// forcedLevelField 是传递强制级别条目真实级别的内部字段，由 forcedLevelHook 在输出前移除
+const forcedLevelField = "__forced_level"
+
+// WithForcedLevel 为该 Context 的日志强制启用 level 及以上的级别，例如只为某个租户的请求输出 Debug 日志。
+// 只能放宽 Logger 的级别，不会屏蔽原本会输出的日志；只对 ...Contextf 方法生效。
+//
+// 限制: logrus 按 Logger 的级别过滤条目，被强制输出的条目会以 Logger 当前级别进入 logrus，
+// 再在第一个 Hook 中还原为真实级别。因此直接注册到底层 logrus.Logger 的 Hook 按 Logger 当前级别触发，
+// 通过 AddHookWithPriority 注册的 Hook 则按真实级别触发；Logger 级别低于 Error 时 (Fatal/Panic) 不生效。
+func WithForcedLevel(ctx context.Context, level logrus.Level) context.Context {
+    return context.WithValue(ctx, ForcedLevelKey, level)
+}
+
+// GetForcedLevel 从 Context 中获取强制生效的日志级别
+func GetForcedLevel(ctx context.Context) (logrus.Level, bool) {
+    val, ok := ctx.Value(ForcedLevelKey).(logrus.Level)
+    return val, ok
+}
+
+// contextEntry 判断 ...Contextf 的日志是否输出，并返回构建好的条目与进入 logrus 时使用的级别。
+// 级别未启用但 Context 强制启用时，条目以 Logger 当前级别进入 logrus，真实级别记录在 forcedLevelField 中。
+func (l *LogrusLogger) contextEntry(ctx context.Context, level logrus.Level) (*logrus.Entry, logrus.Level, bool) {
+    emitLevel, ok := l.emitLevel(ctx, level)
+    if !ok || !l.pass(level) {
+        return nil, 0, false
+    }
+    return l.leveledEntry(ctx, level, emitLevel), emitLevel, true
+}
+
+// emitLevel 只做级别判断 (含 Context 强制级别)，返回进入 logrus 时使用的级别，不经过采样等频率控制
+func (l *LogrusLogger) emitLevel(ctx context.Context, level logrus.Level) (logrus.Level, bool) {
+    if l.Logger.IsLevelEnabled(level) {
+        return level, true
+    }
+    forced, ok := GetForcedLevel(ctx)
+    emitLevel := l.Logger.GetLevel()
+    if !ok || level > forced || emitLevel < logrus.ErrorLevel {
+        return 0, false
+    }
+    return emitLevel, true
+}
+
+// leveledEntry 构建条目，emitLevel 与真实级别不同时记录在 forcedLevelField 中
+func (l *LogrusLogger) leveledEntry(ctx context.Context, level, emitLevel logrus.Level) *logrus.Entry {
+    entry := l.newEntry(ctx)
+    if emitLevel != level {
+        entry.Data[forcedLevelField] = level
+    }
+    return entry
+}
+
+// logf 输出条目，在调用栈中占据与 entry.Debugf 等方法相同的层级，保证 CallerSkipFrames 不变。
+// Context 开启了请求级缓冲 (见 BeginRequestBuffer) 时，Info 及以下级别的条目先暂存在缓冲中；
+// 带有请求级采样器 (见 WithRequestSampler) 时，超出限额的条目被丢弃
+func (l *LogrusLogger) logf(entry *logrus.Entry, level logrus.Level, format string, args ...any) {
+    if !allowRequestSample(entry.Context, level, format) {
+        return
+    }
+    if level >= logrus.InfoLevel && l.bufferRequestEntry(entry, level, format, args) {
+        return
+    }
+    entry.Logf(level, format, args...)
+}
+
+// forcedLevelHook 将强制输出的条目还原为真实级别，需要作为第一个 Hook 注册
+type forcedLevelHook struct{}
+
+// Levels 返回 Hook 应该触发的日志级别
+func (forcedLevelHook) Levels() []logrus.Level {
+    return logrus.AllLevels
+}
+
+// Fire 还原条目级别并移除内部字段
+func (forcedLevelHook) Fire(entry *logrus.Entry) error {
+    if level, ok := entry.Data[forcedLevelField].(logrus.Level); ok {
+        delete(entry.Data, forcedLevelField)
+        entry.Level = level
+    }
+    return nil
+}
@@ -0,0 +1,228 @@
+package log
+
+import (
+    "errors"
+    "fmt"
+    "maps"
+    "strings"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// newFormatter 根据配置构建 logrus.Formatter
+// NewLogger 与 SetFormatter 共用，保证两条路径的输出格式一致
+func newFormatter(cfg Config) (logrus.Formatter, error) {
+    if err := cfg.FieldNameStyle.validate(); err != nil {
+        return nil, err
+    }
+    if err := cfg.BytesEncoding.validate(); err != nil {
+        return nil, err
+    }
+    if err := validateFieldTypes(cfg.FieldTypes); err != nil {
+        return nil, err
+    }
+    if cfg.CompactJSON && cfg.JSONPretty {
+        return nil, errors.New("CompactJSON and JSONPretty cannot both be enabled")
+    }
+    base, err := newBaseFormatter(cfg)
+    if err != nil {
+        return nil, err
+    }
+    if cfg.DevMode && !cfg.isJSON() {
+        base = &devErrorFormatter{Formatter: base}
+    }
+    if _, ok := base.(*logrus.JSONFormatter); ok {
+        base = &safeJSONFormatter{Formatter: base}
+    }
+    if _, ok := base.(*layoutFormatter); !ok {
+        base = newLevelNameFormatter(base, cfg)
+    }
+    if transforms := cfg.entryTransforms(); len(transforms) > 0 {
+        base = &transformFormatter{Formatter: base, transforms: transforms}
+    }
+    if cfg.ErrorFormatVerbose {
+        return newLevelBandFormatter(base), nil
+    }
+    return base, nil
+}
+
+// newBaseFormatter 构建负责最终编码的 Formatter。
+// 非美化的 JSON 由 json.Encoder 生成，字符串中的换行会被转义，实现了 json.Marshaler 的字段值也会被压缩，
+// 因此每条日志恰好占一行。
+func newBaseFormatter(cfg Config) (logrus.Formatter, error) {
+    if factory, ok := registeredFormatter(cfg.Format); ok && !cfg.EnableJSON {
+        return factory(cfg), nil
+    }
+    if cfg.isJSON() {
+        return &logrus.JSONFormatter{
+            TimestampFormat:   cfg.TimestampFormat,
+            DisableTimestamp:  false,
+            DisableHTMLEscape: true,
+            FieldMap:          nil,
+            CallerPrettyfier:  nil,
+            PrettyPrint:       cfg.JSONPretty, // JSON格式美化输出
+        }, nil
+    }
+    if cfg.TextLayout != "" {
+        f, err := newLayoutFormatter(cfg.TextLayout, cfg.TimestampFormat, cfg.PadLevels)
+        if err != nil {
+            return nil, err
+        }
+        f.levelNames = maps.Clone(cfg.LevelNames)
+        return f, nil
+    }
+    return &logrus.TextFormatter{
+        FullTimestamp:   true,
+        TimestampFormat: cfg.TimestampFormat,
+        ForceColors:     true, // 强制终端颜色
+        DisableColors:   false,
+        PadLevelText:    cfg.PadLevels, // 级别标签补齐到相同宽度
+    }, nil
+}
+
+// isJSON 判断是否输出 JSON 格式
+func (c Config) isJSON() bool {
+    return c.EnableJSON || c.Format == FormatJSON
+}
+
+// EntryTransform 在编码前修改日志条目，例如过滤、脱敏或规范化字段，可通过 ChainFormatter 组合使用。
+// 传入的 entry 是 logrus 为本次输出复制的实例，可以直接修改。
+type EntryTransform func(entry *logrus.Entry)
+
+// entryTransforms 返回配置启用的条目变换，按执行顺序排列
+func (c Config) entryTransforms() []EntryTransform {
+    var transforms []EntryTransform
+    if c.RespectStructTags {
+        transforms = append(transforms, respectStructTags)
+    }
+    if c.SanitizeUTF8 {
+        transforms = append(transforms, sanitizeUTF8)
+    }
+    if c.SanitizeNewlines && !c.isJSON() {
+        transforms = append(transforms, sanitizeNewlines)
+    }
+    // 文本格式下保持字符串形式
+    if c.StructuredStacktrace && c.isJSON() {
+        transforms = append(transforms, structuredStacktrace)
+    }
+    if len(c.AllowedFields) > 0 {
+        transforms = append(transforms, allowFields(c.AllowedFields))
+    }
+    // 与 level 一样视为内置字段，放在白名单之后不被过滤
+    if c.IncludeLevelNumber {
+        transforms = append(transforms, addLevelNumber)
+    }
+    if c.NormalizeTimeFields {
+        transforms = append(transforms, normalizeTimeFields(c.DurationUnit, c.TimestampFormat))
+    }
+    if c.BytesEncoding != "" {
+        transforms = append(transforms, encodeBytesFields(c.BytesEncoding, c.MaxBytesFieldLen))
+    }
+    if len(c.FieldTypes) > 0 {
+        transforms = append(transforms, coerceFieldTypes(maps.Clone(c.FieldTypes), c.FieldPrefix))
+    }
+    // 命名风格转换放在最后，前面的变换仍可使用原始字段名
+    if c.FieldNameStyle != "" {
+        transforms = append(transforms, normalizeFieldNames(c.FieldNameStyle, c.FieldPrefix))
+    }
+    return transforms
+}
+
+// ChainFormatter 返回一个依次执行 transforms、再交给 final 编码的 Formatter，
+// 例如先脱敏再输出 JSON:
+//
+//	f := log.ChainFormatter(&logrus.JSONFormatter{}, log.RedactFields("password"), log.AllowFields("user", "password"))
+//	logger.SetFormatterObject(f)
+func ChainFormatter(final logrus.Formatter, transforms ...EntryTransform) logrus.Formatter {
+    return &transformFormatter{Formatter: final, transforms: transforms}
+}
+
+// RedactFields 返回将指定字段的值替换为 RedactedValue 的变换
+func RedactFields(keys ...string) EntryTransform {
+    return func(entry *logrus.Entry) {
+        for _, k := range keys {
+            if _, ok := entry.Data[k]; ok {
+                entry.Data[k] = RedactedValue
+            }
+        }
+    }
+}
+
+// MaskTail 返回只保留 field 字段值最后 visible 个字符、其余替换为 '*' 的变换，
+// 例如 visible 为 4 时 "1234567890" 输出为 "******7890"。非字符串值按 fmt.Sprint 转换后处理；
+// 值的长度不超过 visible 时整体替换为 '*'，避免短值被完整输出
+func MaskTail(field string, visible int) EntryTransform {
+    return func(entry *logrus.Entry) {
+        if v, ok := entry.Data[field]; ok {
+            entry.Data[field] = maskTail(fmt.Sprint(v), visible)
+        }
+    }
+}
+
+// maskTail 按字符 (rune) 计数，保证不会截断多字节字符
+func maskTail(s string, visible int) string {
+    runes := []rune(s)
+    if visible <= 0 || len(runes) <= visible {
+        return strings.Repeat("*", len(runes))
+    }
+    masked := len(runes) - visible
+    return strings.Repeat("*", masked) + string(runes[masked:])
+}
+
+// AllowFields 返回只保留指定字段的变换，与 Config.AllowedFields 效果相同
+func AllowFields(keys ...string) EntryTransform {
+    return allowFields(keys)
+}
+
+// transformFormatter 依次执行 transforms 后交给内部 Formatter 编码
+type transformFormatter struct {
+    logrus.Formatter
+    transforms []EntryTransform
+}
+
+// Format 实现 logrus.Formatter
+func (f *transformFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+    for _, transform := range f.transforms {
+        transform(entry)
+    }
+    return f.Formatter.Format(entry)
+}
+
+// allowFields 只保留白名单中的字段，time/level/msg 不属于 entry.Data，不受影响
+func allowFields(allowed []string) EntryTransform {
+    set := make(map[string]struct{}, len(allowed))
+    for _, k := range allowed {
+        set[k] = struct{}{}
+    }
+    return func(entry *logrus.Entry) {
+        data := make(logrus.Fields, len(set))
+        for k, v := range entry.Data {
+            if _, ok := set[k]; ok {
+                data[k] = v
+            }
+        }
+        entry.Data = data
+    }
+}
+
+// normalizeTimeFields 将 time.Duration 字段转换为以 unit 为单位的数值，
+// time.Time 字段按 timestampFormat 格式化为字符串
+func normalizeTimeFields(unit time.Duration, timestampFormat string) EntryTransform {
+    if unit <= 0 {
+        unit = time.Millisecond
+    }
+    if timestampFormat == "" {
+        timestampFormat = time.RFC3339Nano
+    }
+    return func(entry *logrus.Entry) {
+        for k, v := range entry.Data {
+            switch v := v.(type) {
+            case time.Duration:
+                entry.Data[k] = float64(v) / float64(unit)
+            case time.Time:
+                entry.Data[k] = v.Format(timestampFormat)
+            }
+        }
+    }
+}
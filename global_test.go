@@ -0,0 +1,65 @@
+package log
+
+import (
+    "bytes"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// resetGlobalLogger 将全局 Logger 恢复为未初始化状态，返回的函数用于还原调用前的全局 Logger。
+// 不能与其他使用全局 Logger 的测试并行执行
+func resetGlobalLogger() (restore func()) {
+    prevLogger, prevConfig, prevInited := globalLogger, globalConfig, globalInited.Load()
+    globalLoggerOnce = sync.Once{}
+    globalLogger, globalConfig = nil, Config{}
+    globalInited.Store(false)
+    return func() {
+        globalLoggerOnce = sync.Once{}
+        if prevInited {
+            globalLoggerOnce.Do(func() {})
+        }
+        globalLogger, globalConfig = prevLogger, prevConfig
+        globalInited.Store(prevInited)
+    }
+}
+
+func TestDuplicateInitGlobalLogger(t *testing.T) {
+    t.Cleanup(resetGlobalLogger())
+
+    var buf bytes.Buffer
+    first := DefaultConfig()
+    first.Level = logrus.DebugLevel
+    first.Format = FormatJSON
+    first.Output = &buf
+    first.ExitFunc = func(int) {}
+    first.Clock = func() time.Time { return time.Unix(0, 0) }
+    InitGlobalLogger(first)
+    if !GlobalLoggerInitialized() {
+        t.Fatal("global logger should be initialized")
+    }
+    global := GetGlobalLogger().(*LogrusLogger)
+
+    // 相同的配置不会告警，函数类型字段 (ExitFunc、Clock) 不参与比较
+    same := first
+    same.ExitFunc = func(int) {}
+    same.Clock = func() time.Time { return time.Unix(1, 0) }
+    InitGlobalLogger(same)
+    if buf.Len() != 0 {
+        t.Errorf("unexpected warning for identical init: %q", buf.String())
+    }
+
+    // 与生效配置不同的二次初始化会输出警告
+    cfg := first
+    cfg.Level = logrus.ErrorLevel
+    InitGlobalLogger(cfg)
+    if !strings.Contains(buf.String(), "InitGlobalLogger called again") {
+        t.Errorf("expected warning for conflicting init, got %q", buf.String())
+    }
+    if global.GetConfig().Level != logrus.DebugLevel {
+        t.Error("conflicting init must not change the global logger")
+    }
+}
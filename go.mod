@@ -2,6 +2,13 @@ module github.com/sapaude/go-shims/x/log
 
 go 1.24.1
 
-require github.com/sirupsen/logrus v1.9.3
+require (
+	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/sync v0.13.0
+	golang.org/x/sys v0.40.0
+)
 
-require golang.org/x/sys v0.34.0 // indirect
+require github.com/stretchr/testify v1.11.1 // indirect
+
+// 仅测试使用
+require go.uber.org/goleak v1.3.0
@@ -0,0 +1,55 @@
+package log
+
+import (
+    "errors"
+    "fmt"
+    "io"
+)
+
+// HealthChecker 由可以自检的输出目标实现 (例如 kafka.Writer 检查与 Broker 的连通性)，
+// HealthCheck 对实现了该接口的输出目标调用其 HealthCheck 代替探测写入
+type HealthChecker interface {
+    HealthCheck() error
+}
+
+// HealthCheck 检查日志输出目标当前是否可写，通常在服务报告就绪前调用。
+// 对普通输出目标 (Output、FilePath 对应的文件、ErrorOutput) 执行一次零字节的探测写入，
+// 可以发现文件已关闭、以只读方式打开等问题；不经过缓冲区，也不会产生日志内容。
+func (l *LogrusLogger) HealthCheck() error {
+    l.mu.RLock()
+    sinks := []struct {
+        name string
+        w    io.Writer
+    }{{"output", l.config.Output}}
+    if l.file != nil && (l.config.FilePath != "" || l.config.RotationFilePattern != "") {
+        sinks[0].w = l.file
+    }
+    if errOut := l.config.errorOutput(); errOut != nil {
+        sinks = append(sinks, struct {
+            name string
+            w    io.Writer
+        }{"error output", errOut})
+    }
+    l.mu.RUnlock()
+
+    var errs []error
+    for _, sink := range sinks {
+        if err := probeWriter(sink.w); err != nil {
+            errs = append(errs, fmt.Errorf("log %s: %w", sink.name, err))
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// probeWriter 检查 w 是否可写
+func probeWriter(w io.Writer) error {
+    switch w := w.(type) {
+    case nil:
+        return errors.New("no writer configured")
+    case HealthChecker:
+        return w.HealthCheck()
+    default:
+        _, err := w.Write(nil)
+        return err
+    }
+}
@@ -2,7 +2,9 @@ package log
 
 import (
     "fmt"
+    "reflect"
     "runtime"
+    "slices"
     "strings"
 
     "github.com/sirupsen/logrus"
@@ -13,6 +15,8 @@ const (
     CallerFileFieldKey = "file"
     CallerFuncFieldKey = "func"
 
+    // CallerSkipFrames 是通过全局函数调用时 runtime.Caller 需要跳过的固定帧数，
+    // NewLogger 改为在首次使用时校准调用层级，仅在校准失败或直接使用 NewCallerHook 时使用该值
     EchoCallerSkipFrames = 9
     CallerSkipFrames     = EchoCallerSkipFrames
 )
@@ -22,8 +26,29 @@ type CallerHook struct {
     // SkipFrames 决定向上跳过多少个栈帧来找到真正的调用者
     // 默认情况下，我们需要跳过 Logrus 内部调用和我们自己的封装层
     SkipFrames int
+    // ReportLevels 限定需要记录调用者信息的级别，为空时所有级别都记录。
+    // 未列出的级别不会触发 Hook，也就不会产生 runtime.Caller 的开销
+    ReportLevels []logrus.Level
+    // SkipPackages 非空时改为动态查找调用者: 沿调用栈向上跳过 logrus、本包以及这些包 (导入路径) 中的栈帧，
+    // 第一个不属于它们的栈帧即为调用者，此时忽略 SkipFrames
+    SkipPackages []string
+    // Fields 限定输出的调用者字段 (CallerFileFieldKey / CallerFuncFieldKey)，为 nil 时两者都输出
+    Fields []string
+    // URIScheme 为 true 时文件字段输出为 "file://path:line"，便于在 IDE 中点击跳转，否则为 "path:line"
+    URIScheme bool
+
+    // depth 是 NewLogger 校准得到的 logrus 内部栈帧数 (见 calibratedCallerDepth)，> 0 时代替 SkipFrames
+    depth int
 }
 
+var (
+    logrusPackage = reflect.TypeOf(logrus.Entry{}).PkgPath()
+    selfPackage   = reflect.TypeOf(CallerHook{}).PkgPath()
+)
+
+// maxCallerDepth 是动态查找调用者时检查的最大栈帧数
+const maxCallerDepth = 32
+
 // NewCallerHook 创建一个新的 CallerHook 实例
 func NewCallerHook(skipFrames int) *CallerHook {
     return &CallerHook{
@@ -33,19 +58,42 @@ func NewCallerHook(skipFrames int) *CallerHook {
 
 // Levels 返回 Hook 应该触发的日志级别
 func (hook *CallerHook) Levels() []logrus.Level {
+    if len(hook.ReportLevels) > 0 {
+        return hook.ReportLevels
+    }
     return logrus.AllLevels
 }
 
 // Fire 在日志事件发生时被调用
 func (hook *CallerHook) Fire(entry *logrus.Entry) error {
-    // 向上跳过 hook.Fire, logrus.Entry.log, my_logger.Logger 方法, 以及 Logrus 内部的调用
+    // 未配置 SkipPackages 时向上跳过 hook.Fire, logrus.Entry.log, my_logger.Logger 方法, 以及 Logrus 内部的调用
     // 具体的跳过帧数可能需要根据实际封装层级进行微调
-    pc, file, line, ok := runtime.Caller(hook.SkipFrames)
-    if !ok {
-        return nil
+    var (
+        funcName, file string
+        line           int
+    )
+    if frame, ok := capturedCaller(entry); ok {
+        funcName, file, line = frame.Function, frame.File, frame.Line
+    } else if len(hook.SkipPackages) > 0 {
+        frame, ok := hook.findCaller()
+        if !ok {
+            return nil
+        }
+        funcName, file, line = frame.Function, frame.File, frame.Line
+    } else if hook.depth > 0 {
+        frame, ok := calibratedCaller(hook.depth)
+        if !ok {
+            return nil
+        }
+        funcName, file, line = frame.Function, frame.File, frame.Line
+    } else {
+        pc, f, l, ok := runtime.Caller(hook.SkipFrames)
+        if !ok {
+            return nil
+        }
+        funcName, file, line = runtime.FuncForPC(pc).Name(), f, l
     }
 
-    funcName := runtime.FuncForPC(pc).Name()
     // 简化函数名，去除包路径
     lastSlash := strings.LastIndex(funcName, "/")
     if lastSlash != -1 {
@@ -57,8 +105,88 @@ func (hook *CallerHook) Fire(entry *logrus.Entry) error {
     }
 
     // 格式化调用者信息
-    entry.Data[CallerFileFieldKey] = fmt.Sprintf("file://%s:%d", file, line)
-    entry.Data[CallerFuncFieldKey] = fmt.Sprintf("%s()", funcName)
+    if hook.reports(CallerFileFieldKey) {
+        location := fmt.Sprintf("%s:%d", file, line)
+        if hook.URIScheme {
+            location = "file://" + location
+        }
+        entry.Data[CallerFileFieldKey] = location
+    }
+    if hook.reports(CallerFuncFieldKey) {
+        entry.Data[CallerFuncFieldKey] = fmt.Sprintf("%s()", funcName)
+    }
     return nil
+}
+
+// reports 判断是否输出名为 field 的调用者字段
+func (hook *CallerHook) reports(field string) bool {
+    return hook.Fields == nil || slices.Contains(hook.Fields, field)
+}
+
+// validateCallerFields 检查 Config.CallerFields 中只包含已知的调用者字段
+func validateCallerFields(fields []string) error {
+    for _, f := range fields {
+        if f != CallerFileFieldKey && f != CallerFuncFieldKey {
+            return fmt.Errorf("unknown caller field %q, expected %q or %q", f, CallerFileFieldKey, CallerFuncFieldKey)
+        }
+    }
+    return nil
+}
+
+// findCaller 沿调用栈向上查找第一个不属于 logrus、本包及 SkipPackages 的栈帧
+func (hook *CallerHook) findCaller() (runtime.Frame, bool) {
+    pcs := make([]uintptr, maxCallerDepth)
+    n := runtime.Callers(3, pcs) // 跳过 runtime.Callers、findCaller 与 Fire
+    frames := runtime.CallersFrames(pcs[:n])
+    for {
+        frame, more := frames.Next()
+        if !hook.skipFrame(funcPackage(frame.Function)) {
+            return frame, true
+        }
+        if !more {
+            return runtime.Frame{}, false
+        }
+    }
+}
 
+// skipFrame 判断所属包为 pkg 的栈帧是否应被跳过
+func (hook *CallerHook) skipFrame(pkg string) bool {
+    if pkg == logrusPackage || pkg == selfPackage {
+        return true
+    }
+    for _, skip := range hook.SkipPackages {
+        if pkg == skip {
+            return true
+        }
+    }
+    return false
+}
+
+// funcPackage 从完整函数名 (如 "example.com/a/b.(*T).Method") 中解析出包的导入路径
+func funcPackage(funcName string) string {
+    lastSlash := strings.LastIndex(funcName, "/")
+    if dot := strings.Index(funcName[lastSlash+1:], "."); dot != -1 {
+        return funcName[:lastSlash+1+dot]
+    }
+    return funcName
+}
+
+// staticFieldsHook 为每条日志添加一组固定字段 (创建时确定)，不覆盖已有的同名字段
+type staticFieldsHook struct {
+    fields logrus.Fields
+}
+
+// Levels 返回 Hook 应该触发的日志级别
+func (hook *staticFieldsHook) Levels() []logrus.Level {
+    return logrus.AllLevels
+}
+
+// Fire 添加固定字段，不覆盖已有的同名字段
+func (hook *staticFieldsHook) Fire(entry *logrus.Entry) error {
+    for k, v := range hook.fields {
+        if _, exists := entry.Data[k]; !exists {
+            entry.Data[k] = v
+        }
+    }
+    return nil
 }
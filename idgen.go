@@ -0,0 +1,127 @@
+package log
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/binary"
+    "encoding/hex"
+    "sync"
+    "time"
+)
+
+// IDGenerator 生成请求 ID，通过 Config.IDGenerator 选择，供 EnsureRequestID 使用
+type IDGenerator interface {
+    NewID() string
+}
+
+// UUIDv4Generator 生成随机的 UUID v4，例如 "0f8fad5b-d9cb-469f-a165-70867728950e"
+type UUIDv4Generator struct{}
+
+// NewID 实现 IDGenerator
+func (UUIDv4Generator) NewID() string {
+    var b [16]byte
+    _, _ = rand.Read(b[:])
+    b[6] = b[6]&0x0f | 0x40 // version 4
+    b[8] = b[8]&0x3f | 0x80 // variant RFC 4122
+
+    var s [36]byte
+    hex.Encode(s[0:8], b[0:4])
+    s[8] = '-'
+    hex.Encode(s[9:13], b[4:6])
+    s[13] = '-'
+    hex.Encode(s[14:18], b[6:8])
+    s[18] = '-'
+    hex.Encode(s[19:23], b[8:10])
+    s[23] = '-'
+    hex.Encode(s[24:], b[10:])
+    return string(s[:])
+}
+
+// crockfordAlphabet 是 ULID 使用的 Crockford Base32 字母表
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator 生成 ULID (26 位 Crockford Base32，前 48 位为毫秒时间戳)，按字典序排列即按生成时间排列。
+// 同一毫秒内生成的 ULID 将上一个 ULID 的随机部分加一，保证严格单调递增。零值可直接使用，可并发调用。
+type ULIDGenerator struct {
+    mu     sync.Mutex
+    lastMS uint64
+    last   [10]byte // 上一个 ULID 的随机部分
+}
+
+// NewULIDGenerator 创建 ULIDGenerator
+func NewULIDGenerator() *ULIDGenerator {
+    return &ULIDGenerator{}
+}
+
+// NewID 实现 IDGenerator
+func (g *ULIDGenerator) NewID() string {
+    g.mu.Lock()
+    ms := uint64(time.Now().UnixMilli())
+    if ms <= g.lastMS {
+        // 同一毫秒 (或时钟回拨) 时沿用上一个时间戳并递增随机部分，随机部分溢出时进位到时间戳
+        ms = g.lastMS
+        if incrementBytes(g.last[:]) {
+            ms++
+        }
+    } else {
+        _, _ = rand.Read(g.last[:])
+    }
+    g.lastMS = ms
+    var b [16]byte
+    binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+    binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+    copy(b[6:], g.last[:])
+    g.mu.Unlock()
+    return encodeULID(b)
+}
+
+// incrementBytes 将 b 视为大端整数加一，溢出时返回 true
+func incrementBytes(b []byte) bool {
+    for i := len(b) - 1; i >= 0; i-- {
+        b[i]++
+        if b[i] != 0 {
+            return false
+        }
+    }
+    return true
+}
+
+// encodeULID 将 128 位的 ULID 编码为 26 位 Crockford Base32 (首位只使用 3 位)
+func encodeULID(b [16]byte) string {
+    hi := binary.BigEndian.Uint64(b[:8])
+    lo := binary.BigEndian.Uint64(b[8:])
+    var s [26]byte
+    for i := len(s) - 1; i >= 0; i-- {
+        s[i] = crockfordAlphabet[lo&31]
+        lo = lo>>5 | hi<<59
+        hi >>= 5
+    }
+    return string(s[:])
+}
+
+// NewRequestID 使用 Config.IDGenerator 生成请求 ID，未配置时生成 32 位十六进制的随机 ID
+func (l *LogrusLogger) NewRequestID() string {
+    if l.config.IDGenerator != nil {
+        return l.config.IDGenerator.NewID()
+    }
+    return newRequestID()
+}
+
+// EnsureRequestID 在 Context 中没有请求 ID 时使用 Logger 的 Config.IDGenerator 生成一个，已有时原样返回
+func (l *LogrusLogger) EnsureRequestID(ctx context.Context) context.Context {
+    if reqID, ok := GetRequestID(ctx); ok && reqID != "" {
+        return ctx
+    }
+    return WithRequestID(ctx, l.NewRequestID())
+}
+
+// EnsureRequestID 在 Context 中没有请求 ID 时使用全局 Logger 的 Config.IDGenerator 生成一个，已有时原样返回
+func EnsureRequestID(ctx context.Context) context.Context {
+    if reqID, ok := GetRequestID(ctx); ok && reqID != "" {
+        return ctx
+    }
+    if gen := globalLogrusLogger().GetConfig().IDGenerator; gen != nil {
+        return WithRequestID(ctx, gen.NewID())
+    }
+    return WithRequestID(ctx, newRequestID())
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+    "os"
+
+    "github.com/sirupsen/logrus"
+)
+
+const (
+    PodFieldKey       = "pod"
+    NamespaceFieldKey = "namespace"
+    NodeFieldKey      = "node"
+)
+
+// k8sEnvFields 定义 Downward API 环境变量与日志字段的对应关系
+var k8sEnvFields = []struct {
+    env   string
+    field string
+}{
+    {"POD_NAME", PodFieldKey},
+    {"POD_NAMESPACE", NamespaceFieldKey},
+    {"NODE_NAME", NodeFieldKey},
+}
+
+// readK8sMetadata 从 Downward API 注入的环境变量中读取 Pod 元数据，未设置的变量不会出现在结果中
+func readK8sMetadata() logrus.Fields {
+    fields := logrus.Fields{}
+    for _, m := range k8sEnvFields {
+        if v := os.Getenv(m.env); v != "" {
+            fields[m.field] = v
+        }
+    }
+    return fields
+}
+
+// K8sMetadataHook 为每条日志添加 Kubernetes Pod 元数据字段 (pod, namespace, node)，不覆盖已有的同名字段
+type K8sMetadataHook struct {
+    staticFieldsHook
+}
+
+// NewK8sMetadataHook 创建一个 K8sMetadataHook，环境变量只在创建时读取一次。
+// 不在 Kubernetes 中运行 (环境变量均未设置) 时返回 nil
+func NewK8sMetadataHook() *K8sMetadataHook {
+    fields := readK8sMetadata()
+    if len(fields) == 0 {
+        return nil
+    }
+    return &K8sMetadataHook{staticFieldsHook{fields: fields}}
+}
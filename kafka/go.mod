@@ -0,0 +1,18 @@
+module github.com/sapaude/go-shims/x/log/kafka
+
+go 1.24.1
+
+require (
+	github.com/sapaude/go-shims/x/log v0.0.0
+	github.com/segmentio/kafka-go v0.4.50
+	go.uber.org/goleak v1.3.0
+)
+
+require (
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
+	golang.org/x/sys v0.40.0 // indirect
+)
+
+replace github.com/sapaude/go-shims/x/log => ../
@@ -0,0 +1,331 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "math"
+    "net"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sapaude/go-shims/x/log/kafka"
+    kafkago "github.com/segmentio/kafka-go"
+    "go.uber.org/goleak"
+)
+
+// mockProducer 记录所有发送的消息
+type mockProducer struct {
+    mu      sync.Mutex
+    batches [][]kafkago.Message
+    closed  bool
+    err     error // 非 nil 时 WriteMessages 返回该错误
+}
+
+func (p *mockProducer) WriteMessages(_ context.Context, msgs ...kafkago.Message) error {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if p.err != nil {
+        return p.err
+    }
+    p.batches = append(p.batches, msgs)
+    return nil
+}
+
+func (p *mockProducer) Close() error {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.closed = true
+    return nil
+}
+
+func TestKafkaWriter(t *testing.T) {
+    producer := &mockProducer{}
+    w := kafka.NewKafkaWriter([]string{"localhost:9092"}, "logs",
+        kafka.WithProducer(producer),
+        kafka.WithBatchSize(2),
+        kafka.WithFlushInterval(0),
+    )
+
+    cfg := log.DefaultConfig()
+    cfg.Output = w
+    cfg.Format = log.FormatJSON
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+
+    ctx := log.WithTraceID(context.Background(), "trace-1")
+    l.InfoContextf(ctx, "first")
+    l.InfoContextf(ctx, "second")
+    l.Infof("third")
+
+    producer.mu.Lock()
+    if len(producer.batches) != 1 || len(producer.batches[0]) != 2 {
+        t.Fatalf("batches = %v, want one batch of 2", producer.batches)
+    }
+    producer.mu.Unlock()
+
+    if err := w.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if !producer.closed || len(producer.batches) != 2 {
+        t.Fatalf("remaining message not flushed on Close")
+    }
+
+    first := producer.batches[0][0]
+    if string(first.Key) != "trace-1" {
+        t.Errorf("key = %q, want trace-1", first.Key)
+    }
+    var payload map[string]any
+    if err := json.Unmarshal(first.Value, &payload); err != nil {
+        t.Fatalf("payload is not JSON: %v", err)
+    }
+    if payload["msg"] != "first" {
+        t.Errorf("msg = %v, want first", payload["msg"])
+    }
+    if !bytes.Contains(producer.batches[1][0].Value, []byte("third")) || producer.batches[1][0].Key != nil {
+        t.Errorf("unexpected message without trace_id: %+v", producer.batches[1][0])
+    }
+}
+
+func TestKafkaWriterNumericKey(t *testing.T) {
+    producer := &mockProducer{}
+    w := kafka.NewKafkaWriter([]string{"localhost:9092"}, "logs",
+        kafka.WithProducer(producer),
+        kafka.WithKeyField("account_id"),
+        kafka.WithBatchSize(1),
+        kafka.WithFlushInterval(0),
+    )
+    defer w.Close()
+
+    cfg := log.DefaultConfig()
+    cfg.Output = w
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    l.(*log.LogrusLogger).WithField("account_id", int64(math.MaxInt64)).Infof("numeric key")
+
+    producer.mu.Lock()
+    defer producer.mu.Unlock()
+    if got := string(producer.batches[0][0].Key); got != "9223372036854775807" {
+        t.Errorf("key = %q, want exact int64", got)
+    }
+}
+
+func TestKafkaWriterStats(t *testing.T) {
+    producer := &mockProducer{}
+    w := kafka.NewKafkaWriter([]string{"localhost:9092"}, "logs",
+        kafka.WithProducer(producer),
+        kafka.WithBatchSize(2),
+        kafka.WithFlushInterval(0),
+    )
+
+    line := []byte(`{"msg":"x"}` + "\n")
+    w.Write(line)
+    w.Write(line) // 批满发送
+    w.Write(line)
+    if s := w.WriterStats(); s.Enqueued != 3 || s.Flushed != 2 || s.Pending != 1 {
+        t.Errorf("stats = %+v", s)
+    }
+
+    producer.mu.Lock()
+    producer.err = errors.New("broker down")
+    producer.mu.Unlock()
+    if err := w.Flush(); err == nil {
+        t.Fatal("expected flush error")
+    }
+    w.Close()
+    w.Write(line) // 关闭后写入被丢弃
+
+    s := w.WriterStats()
+    if s.Enqueued != 4 || s.Flushed != 2 || s.Dropped != 2 || s.Errors != 1 || s.Pending != 0 {
+        t.Errorf("stats after failures = %+v", s)
+    }
+}
+
+// stalledProducer 模拟无响应的 broker: WriteMessages 一直阻塞到 ctx 结束
+type stalledProducer struct{}
+
+func (stalledProducer) WriteMessages(ctx context.Context, _ ...kafkago.Message) error {
+    <-ctx.Done()
+    return ctx.Err()
+}
+
+func (stalledProducer) Close() error { return nil }
+
+func TestKafkaWriterCloseWithTimeout(t *testing.T) {
+    w := kafka.NewKafkaWriter([]string{"localhost:9092"}, "logs",
+        kafka.WithProducer(stalledProducer{}),
+        kafka.WithFlushInterval(0),
+    )
+    w.Write([]byte(`{"msg":"stuck"}` + "\n"))
+
+    start := time.Now()
+    err := w.CloseWithTimeout(50 * time.Millisecond)
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("CloseWithTimeout error = %v, want deadline exceeded", err)
+    }
+    if elapsed := time.Since(start); elapsed > time.Second {
+        t.Errorf("CloseWithTimeout took %v", elapsed)
+    }
+    if s := w.WriterStats(); s.Dropped != 1 || s.Pending != 0 {
+        t.Errorf("stats = %+v", s)
+    }
+}
+
+// healthyProducer 是实现了 log.HealthChecker 的 mockProducer
+type healthyProducer struct{ mockProducer }
+
+func (p *healthyProducer) HealthCheck() error { return nil }
+
+func TestKafkaWriterHealthCheck(t *testing.T) {
+    w := kafka.NewKafkaWriter(nil, "logs", kafka.WithProducer(&healthyProducer{}), kafka.WithFlushInterval(0))
+    if err := w.HealthCheck(); err != nil {
+        t.Errorf("HealthCheck() = %v, want nil", err)
+    }
+    w.Close()
+
+    // 没有 Broker 且生产者不支持健康检查时不能视为健康
+    unsupported := kafka.NewKafkaWriter(nil, "logs", kafka.WithProducer(&mockProducer{}), kafka.WithFlushInterval(0))
+    defer unsupported.Close()
+    if err := unsupported.HealthCheck(); !errors.Is(err, kafka.ErrHealthCheckNotSupported) {
+        t.Errorf("HealthCheck() = %v, want ErrHealthCheckNotSupported", err)
+    }
+    if err := w.HealthCheck(); err == nil {
+        t.Error("closed writer should fail the health check")
+    }
+
+    // 无法连接的 Broker
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    addr := ln.Addr().String()
+    ln.Close()
+    unreachable := kafka.NewKafkaWriter([]string{addr}, "logs", kafka.WithFlushInterval(0))
+    defer unreachable.Close()
+    if err := unreachable.HealthCheck(); err == nil {
+        t.Error("unreachable broker should fail the health check")
+    }
+
+    // 作为 Output 时 Logger.HealthCheck 使用 Writer 自身的检查
+    cfg := log.DefaultConfig()
+    cfg.Output = unreachable
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    if err := l.(*log.LogrusLogger).HealthCheck(); err == nil {
+        t.Error("logger health check should surface the kafka error")
+    }
+}
+
+// hungProducer 模拟完全无响应的 broker: WriteMessages 忽略 ctx，直到 release 被关闭才返回
+type hungProducer struct {
+    entered chan struct{}
+    release chan struct{}
+}
+
+func (p *hungProducer) WriteMessages(context.Context, ...kafkago.Message) error {
+    select {
+    case p.entered <- struct{}{}:
+    default:
+    }
+    <-p.release
+    return errors.New("broker unavailable")
+}
+
+func (p *hungProducer) Close() error {
+    <-p.release
+    return nil
+}
+
+func TestKafkaWriterCloseWithTimeoutHungBroker(t *testing.T) {
+    p := &hungProducer{entered: make(chan struct{}, 1), release: make(chan struct{})}
+    defer close(p.release)
+    w := kafka.NewKafkaWriter([]string{"localhost:9092"}, "logs",
+        kafka.WithProducer(p),
+        kafka.WithBatchSize(1),
+        kafka.WithFlushInterval(0),
+    )
+    // 并发写入触发发送并阻塞
+    go w.Write([]byte(`{"msg":"stuck"}` + "\n"))
+    <-p.entered
+
+    start := time.Now()
+    err := w.CloseWithTimeout(50 * time.Millisecond)
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("CloseWithTimeout error = %v, want deadline exceeded", err)
+    }
+    if elapsed := time.Since(start); elapsed > time.Second {
+        t.Errorf("CloseWithTimeout blocked for %v", elapsed)
+    }
+}
+
+func TestKafkaWriterWriteDuringSend(t *testing.T) {
+    p := &hungProducer{entered: make(chan struct{}, 1), release: make(chan struct{})}
+    w := kafka.NewKafkaWriter([]string{"localhost:9092"}, "logs",
+        kafka.WithProducer(p),
+        kafka.WithBatchSize(2),
+        kafka.WithFlushInterval(0),
+    )
+    line := []byte(`{"msg":"x"}` + "\n")
+    go func() {
+        w.Write(line)
+        w.Write(line) // 批满发送，阻塞在 broker 上
+    }()
+    <-p.entered
+
+    // 发送期间其他写入与统计不等待 Kafka
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        w.Write(line)
+        w.WriterStats()
+    }()
+    select {
+    case <-done:
+    case <-time.After(time.Second):
+        t.Fatal("Write blocked while another batch was being sent")
+    }
+    // 正在发送的消息在完成前仍计入 Pending
+    if s := w.WriterStats(); s.Enqueued != 3 || s.Pending != 3 {
+        t.Errorf("stats = %+v", s)
+    }
+    close(p.release)
+    w.Close()
+}
+
+// 只检查本测试启动的 goroutine，其他测试遗留的 goroutine 不计入
+func TestKafkaWriterNoGoroutineLeak(t *testing.T) {
+    defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+    w := kafka.NewKafkaWriter(nil, "logs", kafka.WithProducer(&mockProducer{}), kafka.WithFlushInterval(time.Millisecond))
+    if err := w.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+
+    // 取消 Context 后定时发送 goroutine 退出，并发送剩余消息
+    ctx, cancel := context.WithCancel(context.Background())
+    producer := &mockProducer{}
+    w = kafka.NewKafkaWriter(nil, "logs", kafka.WithProducer(producer), kafka.WithContext(ctx), kafka.WithFlushInterval(time.Hour))
+    w.Write([]byte(`{"msg":"pending at cancel"}` + "\n"))
+    cancel()
+
+    deadline := time.Now().Add(time.Second)
+    for time.Now().Before(deadline) {
+        producer.mu.Lock()
+        n := len(producer.batches)
+        producer.mu.Unlock()
+        if n > 0 {
+            return
+        }
+        time.Sleep(time.Millisecond)
+    }
+    t.Error("pending message not flushed on cancel")
+}
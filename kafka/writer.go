@@ -0,0 +1,329 @@
+// Package kafka 提供将渲染后的 JSON 日志批量发送到 Kafka 的 io.Writer。
+// 该包是独立的 Go 模块，只有引入它的程序才会依赖并链接 Kafka 客户端。
+package kafka
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net"
+    "sync"
+    "time"
+
+    kafkago "github.com/segmentio/kafka-go"
+
+    "github.com/sapaude/go-shims/x/log"
+)
+
+const (
+    // DefaultBatchSize 默认每批发送的消息条数
+    DefaultBatchSize = 100
+    // DefaultFlushInterval 默认的定时发送间隔
+    DefaultFlushInterval = time.Second
+    // DefaultKeyField 默认作为消息 Key 的日志字段，相同 trace_id 的日志会落到同一分区
+    DefaultKeyField = "trace_id"
+    // HealthCheckTimeout HealthCheck 连接单个 Broker 的超时时间
+    HealthCheckTimeout = 3 * time.Second
+)
+
+// Producer 抽象 Kafka 生产者，*kafkago.Writer 即满足该接口，测试中可替换为 Mock
+type Producer interface {
+    WriteMessages(ctx context.Context, msgs ...kafkago.Message) error
+    Close() error
+}
+
+// Option 配置 Writer
+type Option func(*Writer)
+
+// WithKeyField 指定从日志 JSON 中提取消息 Key 的字段名，为空则不设置 Key
+func WithKeyField(field string) Option {
+    return func(w *Writer) {
+        w.keyField = field
+    }
+}
+
+// WithBatchSize 指定每批发送的消息条数
+func WithBatchSize(n int) Option {
+    return func(w *Writer) {
+        if n > 0 {
+            w.batchSize = n
+        }
+    }
+}
+
+// WithFlushInterval 指定定时发送间隔，<= 0 表示仅在批满或 Flush/Close 时发送
+func WithFlushInterval(d time.Duration) Option {
+    return func(w *Writer) {
+        w.flushInterval = d
+    }
+}
+
+// WithProducer 替换底层生产者，主要用于测试或复用已有的 Kafka 客户端
+func WithProducer(p Producer) Option {
+    return func(w *Writer) {
+        w.producer = p
+    }
+}
+
+// WithContext 指定定时发送 goroutine 的生命周期: ctx 结束时发送剩余消息后退出，
+// 此后消息只在批满或 Flush/Close 时发送。默认只在 Close 时退出
+func WithContext(ctx context.Context) Option {
+    return func(w *Writer) {
+        w.ctx = ctx
+    }
+}
+
+// Writer 实现 io.Writer，将每条日志作为一条 Kafka 消息批量发送
+type Writer struct {
+    producer      Producer
+    keyField      string
+    batchSize     int
+    flushInterval time.Duration
+
+    // sendMu 串行化发送以保持批次顺序，先于 mu 获取；mu 只保护内存状态，发送期间不持有，写日志不必等待 Kafka
+    sendMu  sync.Mutex
+    mu      sync.Mutex
+    pending []kafkago.Message
+    sending int // 正在发送的消息条数，CloseWithTimeout 超时放弃时清零
+    closed  bool
+    stats   log.WriterStats
+    done    chan struct{}
+    wg      sync.WaitGroup
+    ctx     context.Context
+    brokers []string
+
+    // flushCtx 用于后台与写入触发的发送，CloseWithTimeout 超时时取消，中止仍在进行的发送
+    flushCtx    context.Context
+    cancelFlush context.CancelFunc
+}
+
+// NewKafkaWriter 创建一个发送到指定 Topic 的 Writer，配合 Config.Output 与 JSON 格式使用
+func NewKafkaWriter(brokers []string, topic string, opts ...Option) *Writer {
+    w := &Writer{
+        keyField:      DefaultKeyField,
+        batchSize:     DefaultBatchSize,
+        flushInterval: DefaultFlushInterval,
+        done:          make(chan struct{}),
+        ctx:           context.Background(),
+        brokers:       brokers,
+    }
+    w.flushCtx, w.cancelFlush = context.WithCancel(context.Background())
+    for _, opt := range opts {
+        opt(w)
+    }
+    if w.producer == nil {
+        // kafka-go 默认攒满 100 条或等待 1s 才发送，而批次已由 Writer 聚合，这里让每批尽快发出
+        w.producer = &kafkago.Writer{
+            Addr:         kafkago.TCP(brokers...),
+            Topic:        topic,
+            Balancer:     &kafkago.Hash{}, // 按 Key 哈希选择分区
+            BatchSize:    w.batchSize,
+            BatchTimeout: 10 * time.Millisecond,
+        }
+    }
+
+    if w.flushInterval > 0 {
+        w.wg.Add(1)
+        go w.flushLoop()
+    }
+    return w
+}
+
+// Write 实现 io.Writer，p 为一条完整的日志
+func (w *Writer) Write(p []byte) (int, error) {
+    // logrus 会复用 p 的底层缓冲区，必须拷贝
+    value := make([]byte, len(p))
+    copy(value, p)
+    msg := kafkago.Message{Key: w.extractKey(value), Value: value}
+
+    w.mu.Lock()
+    w.stats.Enqueued++
+    if w.closed {
+        w.stats.Dropped++
+        w.mu.Unlock()
+        return 0, fmt.Errorf("kafka writer closed")
+    }
+    w.pending = append(w.pending, msg)
+    full := len(w.pending) >= w.batchSize
+    w.mu.Unlock()
+
+    if full {
+        if err := w.flushContext(w.flushCtx); err != nil {
+            return 0, err
+        }
+    }
+    return len(p), nil
+}
+
+// Flush 立即发送所有待发送的消息
+func (w *Writer) Flush() error {
+    return w.flushContext(w.flushCtx)
+}
+
+// Close 发送剩余消息并关闭底层生产者
+func (w *Writer) Close() error {
+    return w.close(context.Background())
+}
+
+// CloseWithTimeout 与 Close 相同，但整个关闭过程 (等待进行中的发送、发送剩余消息、关闭生产者) 最多等待 d。
+// 超时后取消进行中的发送 (未发送的消息计入 Dropped) 并返回包装了 context.DeadlineExceeded 的错误；
+// 不响应取消的生产者会使关闭在后台继续进行
+func (w *Writer) CloseWithTimeout(d time.Duration) error {
+    ctx, cancel := context.WithTimeout(context.Background(), d)
+    defer cancel()
+    return w.close(ctx)
+}
+
+// close 在后台 goroutine 中执行关闭，ctx 结束时不再等待
+func (w *Writer) close(ctx context.Context) error {
+    done := make(chan error, 1)
+    go func() {
+        done <- w.shutdown(ctx)
+    }()
+    select {
+    case err := <-done:
+        return err
+    case <-ctx.Done():
+        w.cancelFlush()
+        w.abandon()
+        return fmt.Errorf("kafka writer close: %w", ctx.Err())
+    }
+}
+
+// abandon 在关闭超时时将正在发送与待发送的消息计入 Dropped，之后才结束的发送不再更新统计
+func (w *Writer) abandon() {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.stats.Dropped += uint64(w.sending + len(w.pending))
+    w.sending = 0
+    w.pending = nil
+}
+
+func (w *Writer) shutdown(ctx context.Context) error {
+    w.mu.Lock()
+    if w.closed {
+        w.mu.Unlock()
+        return nil
+    }
+    w.closed = true
+    close(w.done)
+    w.mu.Unlock()
+
+    err := w.flushContext(ctx)
+    w.wg.Wait()
+    if cerr := w.producer.Close(); err == nil {
+        err = cerr
+    }
+    w.cancelFlush()
+    return err
+}
+
+// flushContext 在 ctx 的期限内发送待发送的消息: 在 mu 内取走 pending，在 mu 外发送
+func (w *Writer) flushContext(ctx context.Context) error {
+    w.sendMu.Lock()
+    defer w.sendMu.Unlock()
+
+    w.mu.Lock()
+    msgs := w.pending
+    w.pending = nil
+    w.sending = len(msgs)
+    w.mu.Unlock()
+    if len(msgs) == 0 {
+        return nil
+    }
+
+    start := time.Now()
+    err := w.producer.WriteMessages(ctx, msgs...)
+
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.sending == 0 {
+        return err // 已被 abandon 计入 Dropped
+    }
+    w.sending = 0
+    w.stats.LastFlushLatency = time.Since(start)
+    if err != nil {
+        w.stats.Dropped += uint64(len(msgs))
+        w.stats.Errors++
+        return err
+    }
+    w.stats.Flushed += uint64(len(msgs))
+    return nil
+}
+
+// ErrHealthCheckNotSupported 表示 Writer 既没有 Broker 地址、生产者也未实现 log.HealthChecker，无法判断健康状态
+var ErrHealthCheckNotSupported = errors.New("kafka writer: health check not supported")
+
+// HealthCheck 实现 log.HealthChecker: Writer 已关闭时返回错误；生产者实现了 log.HealthChecker 时调用它，
+// 否则依次尝试与 Broker 建立 TCP 连接，任一 Broker 可连接即视为健康；没有 Broker 地址时返回 ErrHealthCheckNotSupported
+func (w *Writer) HealthCheck() error {
+    w.mu.Lock()
+    closed := w.closed
+    w.mu.Unlock()
+    if closed {
+        return errors.New("kafka writer closed")
+    }
+    if checker, ok := w.producer.(log.HealthChecker); ok {
+        return checker.HealthCheck()
+    }
+    if len(w.brokers) == 0 {
+        return ErrHealthCheckNotSupported
+    }
+
+    var errs []error
+    for _, broker := range w.brokers {
+        conn, err := net.DialTimeout("tcp", broker, HealthCheckTimeout)
+        if err == nil {
+            return conn.Close()
+        }
+        errs = append(errs, err)
+    }
+    return errors.Join(errs...)
+}
+
+// WriterStats 返回发送统计，失败批次中的消息计入 Dropped
+func (w *Writer) WriterStats() log.WriterStats {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    stats := w.stats
+    stats.Pending = len(w.pending) + w.sending
+    return stats
+}
+
+func (w *Writer) flushLoop() {
+    defer w.wg.Done()
+    ticker := time.NewTicker(w.flushInterval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ticker.C:
+            _ = w.Flush()
+        case <-w.ctx.Done():
+            _ = w.Flush()
+            return
+        case <-w.done:
+            return
+        }
+    }
+}
+
+// extractKey 从 JSON 日志中取出 keyField 对应的值作为消息 Key
+func (w *Writer) extractKey(line []byte) []byte {
+    if w.keyField == "" {
+        return nil
+    }
+    // 使用 json.Number 保留数值字段的原始文本，避免大整数被转换为 float64 后丢失精度
+    var fields map[string]any
+    dec := json.NewDecoder(bytes.NewReader(line))
+    dec.UseNumber()
+    if err := dec.Decode(&fields); err != nil {
+        return nil
+    }
+    v, ok := fields[w.keyField]
+    if !ok || v == nil {
+        return nil
+    }
+    return []byte(fmt.Sprint(v))
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+const (
+    // DedupKeyFieldKey 是 ErrorfKeyed 输出的去重键字段名
+    DedupKeyFieldKey = "dedup_key"
+    // SuppressedCountFieldKey 是自上次输出以来被合并（未输出）的次数
+    SuppressedCountFieldKey = "suppressed_count"
+
+    // DefaultKeyedWindow 是同一去重键的默认最小输出间隔
+    DefaultKeyedWindow = time.Minute
+
+    // maxKeyedStates 是限流器保留的键数上限，超出后清理时连同未报告的合并次数一起删除过期的键
+    maxKeyedStates = 10000
+)
+
+// keyedLimiter 按去重键限制日志频率：每个键首次出现立即输出，
+// 之后在每个时间窗口内至多输出一次，并附带期间被合并的次数。
+// 每个窗口至多清理一次过期的键，避免键的数量 (如包含 ID 的去重键) 使内存无限增长。
+type keyedLimiter struct {
+    window time.Duration
+
+    mu        sync.Mutex
+    state     map[string]*keyedState
+    lastSweep time.Time // 最近一次清理的时间
+}
+
+type keyedState struct {
+    last       time.Time // 最近一次输出的时间
+    suppressed int       // 最近一次输出之后被合并的次数
+}
+
+func newKeyedLimiter(window time.Duration) *keyedLimiter {
+    if window <= 0 {
+        window = DefaultKeyedWindow
+    }
+    return &keyedLimiter{
+        window: window,
+        state:  make(map[string]*keyedState),
+    }
+}
+
+// allow 判断该键本次是否应输出，返回值 suppressed 为此前被合并的次数
+func (k *keyedLimiter) allow(key string, now time.Time) (ok bool, suppressed int) {
+    ok, suppressed, _ = k.allowFirst(key, now)
+    return ok, suppressed
+}
+
+// allowFirst 与 allow 相同，first 表示该键是首次出现 (或已过期被清理)
+func (k *keyedLimiter) allowFirst(key string, now time.Time) (ok bool, suppressed int, first bool) {
+    k.mu.Lock()
+    defer k.mu.Unlock()
+
+    if now.Sub(k.lastSweep) >= k.window {
+        k.sweep(now)
+    }
+    s, exists := k.state[key]
+    if !exists {
+        k.state[key] = &keyedState{last: now}
+        return true, 0, true
+    }
+    if now.Sub(s.last) < k.window {
+        s.suppressed++
+        return false, 0, false
+    }
+    suppressed = s.suppressed
+    s.last = now
+    s.suppressed = 0
+    return true, suppressed, false
+}
+
+// sweep 删除最近一次输出已超过窗口的键。没有被合并次数的键删除后再次出现时同样立即输出，结果不变；
+// 有未报告合并次数的键保留到再次出现时报告，仅当键数超过 maxKeyedStates 时才一起删除。调用方需持有 k.mu。
+func (k *keyedLimiter) sweep(now time.Time) {
+    k.lastSweep = now
+    overflow := len(k.state) > maxKeyedStates
+    for key, s := range k.state {
+        if now.Sub(s.last) >= k.window && (s.suppressed == 0 || overflow) {
+            delete(k.state, key)
+        }
+    }
+}
+
+// ErrorfKeyed 以 Error 级别输出日志，相同 key 的日志在 Config.KeyedWindow 内只输出一次，
+// 下一次输出时通过 suppressed_count 字段报告期间被合并的次数。
+// 注意: 被合并的日志只会在该 key 再次出现时才被报告。
+func (l *LogrusLogger) ErrorfKeyed(key string, format string, args ...any) {
+    if !l.allow(logrus.ErrorLevel) {
+        return
+    }
+    ok, suppressed := l.keyed.allow(key, time.Now())
+    if !ok {
+        return
+    }
+    entry := l.newEntry(context.Background())
+    entry.Data[DedupKeyFieldKey] = key
+    if suppressed > 0 {
+        entry.Data[SuppressedCountFieldKey] = suppressed
+    }
+    entry.Errorf(format, args...)
+}
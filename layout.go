@@ -0,0 +1,145 @@
+package log
+
+import (
+    "bytes"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// TextLayout 支持的占位符
+const (
+    LayoutTime   = "{time}"
+    LayoutLevel  = "{level}"
+    LayoutMsg    = "{msg}"
+    LayoutFields = "{fields}"
+)
+
+// levelTextWidth 是最长级别标签 (WARNING) 的宽度
+var levelTextWidth = func() int {
+    width := 0
+    for _, level := range logrus.AllLevels {
+        if n := len(level.String()); n > width {
+            width = n
+        }
+    }
+    return width
+}()
+
+// layoutSegment 是解析后的模板片段，placeholder 为空时表示字面量
+type layoutSegment struct {
+    literal     string
+    placeholder string
+}
+
+// layoutFormatter 按 Config.TextLayout 模板渲染文本日志，例如 "{level} | {time} | {msg} | {fields}"
+type layoutFormatter struct {
+    segments        []layoutSegment
+    timestampFormat string
+    padLevel        bool                    // 是否将级别标签补齐到相同宽度
+    levelNames      map[logrus.Level]string // 自定义级别标签 (Config.LevelNames)，未配置的级别使用大写的默认标签
+}
+
+// newLayoutFormatter 解析并校验模板，模板只能包含已知占位符且必须包含 {msg}
+func newLayoutFormatter(layout, timestampFormat string, padLevel bool) (*layoutFormatter, error) {
+    segments, err := parseLayout(layout)
+    if err != nil {
+        return nil, err
+    }
+    if timestampFormat == "" {
+        timestampFormat = time.RFC3339
+    }
+    return &layoutFormatter{segments: segments, timestampFormat: timestampFormat, padLevel: padLevel}, nil
+}
+
+func parseLayout(layout string) ([]layoutSegment, error) {
+    var segments []layoutSegment
+    hasMsg := false
+    rest := layout
+    for rest != "" {
+        open := strings.IndexByte(rest, '{')
+        if open < 0 {
+            segments = append(segments, layoutSegment{literal: rest})
+            break
+        }
+        if open > 0 {
+            segments = append(segments, layoutSegment{literal: rest[:open]})
+        }
+        end := strings.IndexByte(rest[open:], '}')
+        if end < 0 {
+            return nil, fmt.Errorf("invalid text layout %q: unclosed placeholder", layout)
+        }
+        placeholder := rest[open : open+end+1]
+        switch placeholder {
+        case LayoutTime, LayoutLevel, LayoutFields:
+        case LayoutMsg:
+            hasMsg = true
+        default:
+            return nil, fmt.Errorf("invalid text layout %q: unknown placeholder %s", layout, placeholder)
+        }
+        segments = append(segments, layoutSegment{placeholder: placeholder})
+        rest = rest[open+end+1:]
+    }
+    if !hasMsg {
+        return nil, fmt.Errorf("invalid text layout %q: missing %s", layout, LayoutMsg)
+    }
+    return segments, nil
+}
+
+// Format 实现 logrus.Formatter
+func (f *layoutFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+    b := entry.Buffer
+    if b == nil {
+        b = &bytes.Buffer{}
+    }
+    for _, seg := range f.segments {
+        switch seg.placeholder {
+        case "":
+            b.WriteString(seg.literal)
+        case LayoutTime:
+            b.WriteString(entry.Time.Format(f.timestampFormat))
+        case LayoutLevel:
+            level, ok := f.levelNames[entry.Level]
+            if !ok {
+                level = strings.ToUpper(entry.Level.String())
+            }
+            if f.padLevel {
+                level = fmt.Sprintf("%-*s", levelNameWidth(f.levelNames), level)
+            }
+            b.WriteString(level)
+        case LayoutMsg:
+            b.WriteString(entry.Message)
+        case LayoutFields:
+            writeLayoutFields(b, entry.Data)
+        }
+    }
+    // 没有字段时去掉模板留下的行尾空格
+    b.Truncate(len(bytes.TrimRight(b.Bytes(), " ")))
+    b.WriteByte('\n')
+    return b.Bytes(), nil
+}
+
+// writeLayoutFields 按键名排序输出 key=value，包含空白、引号或控制字符的值会被加引号转义
+func writeLayoutFields(b *bytes.Buffer, data logrus.Fields) {
+    keys := make([]string, 0, len(data))
+    for k := range data {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for i, k := range keys {
+        if i > 0 {
+            b.WriteByte(' ')
+        }
+        value := fmt.Sprint(data[k])
+        if strings.ContainsAny(value, " \"=") || hasControl(value) {
+            value = strconv.Quote(value)
+        }
+        b.WriteString(k)
+        b.WriteByte('=')
+        b.WriteString(value)
+    }
+}
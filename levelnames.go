@@ -0,0 +1,113 @@
+package log
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "maps"
+
+    "github.com/sirupsen/logrus"
+)
+
+// levelNameFormatter 将内置 JSON / 文本 Formatter 输出中的级别标签替换为 Config.LevelNames 中的自定义名称。
+// logrus 的 Formatter 固定使用 Level.String() 生成标签，因此在编码后替换:
+// JSON 按顶层对象重新编码 (字段值原样保留)，文本替换行首带颜色的级别标签或 level=... 键值。
+// TextLayout 由 layoutFormatter 直接支持，不经过这里。
+type levelNameFormatter struct {
+    logrus.Formatter
+    names  map[logrus.Level]string
+    json   bool
+    pretty bool
+    pad    int // 文本格式中标签补齐的宽度，0 表示不补齐
+}
+
+// newLevelNameFormatter 包装 f，names 为空时原样返回
+func newLevelNameFormatter(f logrus.Formatter, cfg Config) logrus.Formatter {
+    if len(cfg.LevelNames) == 0 {
+        return f
+    }
+    lf := &levelNameFormatter{
+        Formatter: f,
+        names:     maps.Clone(cfg.LevelNames),
+        json:      cfg.isJSON(),
+        pretty:    cfg.JSONPretty,
+    }
+    if cfg.PadLevels {
+        lf.pad = levelNameWidth(lf.names)
+    }
+    return lf
+}
+
+// levelNameWidth 返回内置与自定义级别标签中的最大宽度
+func levelNameWidth(names map[logrus.Level]string) int {
+    width := levelTextWidth
+    for _, name := range names {
+        width = max(width, len(name))
+    }
+    return width
+}
+
+// Format 实现 logrus.Formatter
+func (f *levelNameFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+    out, err := f.Formatter.Format(entry)
+    if err != nil {
+        return nil, err
+    }
+    name, ok := f.names[entry.Level]
+    if !ok {
+        return out, nil
+    }
+    if f.json {
+        return f.relabelJSON(out, name)
+    }
+    return f.relabelText(out, entry.Level, name), nil
+}
+
+func (f *levelNameFormatter) relabelJSON(out []byte, name string) ([]byte, error) {
+    var fields map[string]json.RawMessage
+    if err := json.Unmarshal(out, &fields); err != nil {
+        return nil, fmt.Errorf("failed to relabel level: %w", err)
+    }
+    label, err := json.Marshal(name)
+    if err != nil {
+        return nil, err
+    }
+    fields[logrus.FieldKeyLevel] = label
+
+    b := &bytes.Buffer{}
+    enc := json.NewEncoder(b)
+    enc.SetEscapeHTML(false)
+    if f.pretty {
+        enc.SetIndent("", "  ")
+    }
+    if err := enc.Encode(fields); err != nil {
+        return nil, fmt.Errorf("failed to relabel level: %w", err)
+    }
+    return b.Bytes(), nil
+}
+
+func (f *levelNameFormatter) relabelText(out []byte, level logrus.Level, name string) []byte {
+    if f.pad > 0 {
+        name = fmt.Sprintf("%-*s", f.pad, name)
+    }
+    // 带颜色: "\x1b[33mWARN\x1b[0m[...] ..."
+    if bytes.HasPrefix(out, []byte("\x1b[")) {
+        start := bytes.IndexByte(out, 'm') + 1
+        end := bytes.Index(out, []byte("\x1b[0m"))
+        if start > 0 && end >= start {
+            return concatBytes(out[:start], []byte(name), out[end:])
+        }
+        return out
+    }
+    // 不带颜色: "time=... level=warning msg=..."
+    label := []byte(logrus.FieldKeyLevel + "=" + level.String())
+    if i := bytes.Index(out, label); i >= 0 {
+        return concatBytes(out[:i], []byte(logrus.FieldKeyLevel+"="+name), out[i+len(label):])
+    }
+    return out
+}
+
+// concatBytes 拼接为新的切片，不复用 logrus 的缓冲区
+func concatBytes(parts ...[]byte) []byte {
+    return bytes.Join(parts, nil)
+}
@@ -0,0 +1,12 @@
+package log
+
+import "github.com/sirupsen/logrus"
+
+// LevelNumberFieldKey 是 Config.IncludeLevelNumber 开启时数值级别字段的字段名
+const LevelNumberFieldKey = "level_num"
+
+// addLevelNumber 添加数值级别字段，取值为 logrus 的级别数值:
+// panic=0, fatal=1, error=2, warning=3, info=4, debug=5, trace=6，数值越小越严重
+func addLevelNumber(entry *logrus.Entry) {
+    entry.Data[LevelNumberFieldKey] = uint32(entry.Level)
+}
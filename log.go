@@ -2,7 +2,10 @@ package log
 
 import (
     "context"
+    "os"
+    "reflect"
     "sync"
+    "sync/atomic"
 
     "github.com/sirupsen/logrus"
 )
@@ -10,23 +13,130 @@ import (
 var (
     globalLogger     Logger
     globalLoggerOnce sync.Once
+    globalConfig     Config      // 初始化全局 Logger 时使用的配置
+    globalInited     atomic.Bool // 全局 Logger 是否已初始化
 )
 
 // InitGlobalLogger 初始化全局 Logger 实例。
-// 只能被调用一次，后续调用将被忽略。
+// 只能被调用一次，后续调用将被忽略；若后续调用的配置与生效的配置不同 (包括先前已通过 GetGlobalLogger
+// 使用默认配置初始化的情况)，会通过全局 Logger 输出一条警告。函数类型的字段 (如 Clock、ExitFunc) 无法比较，不参与比较。
 func InitGlobalLogger(cfg Config) {
+    applied := false
     globalLoggerOnce.Do(func() {
-        l, err := NewLogger(cfg)
+        applied = true
+        defer markGlobalInited(cfg)
+        l, err := NewLoggerWithFallback(cfg)
         if err != nil {
-            // 如果初始化失败，退回到一个最简单的 Logrus 实例，并打印错误
-            logrus.SetOutput(cfg.Output)
-            logrus.SetLevel(logrus.ErrorLevel)
-            logrus.Errorf("Failed to initialize custom logger: %v. Falling back to basic logrus.", err)
-            globalLogger = &LogrusLogger{Logger: logrus.StandardLogger(), config: cfg} // 使用标准 Logrus 作为回退
-            return
+            // 初始化失败时使用回退 Logger，并通过它输出错误
+            l.Errorf("Failed to initialize custom logger: %v. Falling back to a degraded logger.", err)
         }
         globalLogger = l
     })
+    if !applied && !equalIgnoringFuncs(reflect.ValueOf(cfg), reflect.ValueOf(globalConfig)) {
+        globalLogger.Warnf("InitGlobalLogger called again with a different config, ignored: the global logger is already initialized")
+    }
+}
+
+// NewLoggerWithFallback 与 NewLogger 相同，但初始化失败时仍返回一个可用的回退 Logger 以及原始错误。
+// 回退按固定顺序尝试，尽量保持日志结构 (格式、调用者信息等) 不变:
+//  1. 去掉文件输出 (FilePath、RotationFilePattern)，改写到 Output (为空时为 os.Stderr)，其余配置保持不变
+//  2. 仍然失败时使用 DefaultConfig 的 JSON 格式，只保留 Output、Level 与 ReportCaller
+//
+// 全局 Logger 初始化失败时使用该回退，并通过回退 Logger 输出一条错误日志。
+func NewLoggerWithFallback(cfg Config) (Logger, error) {
+    l, err := NewLogger(cfg)
+    if err == nil {
+        return l, nil
+    }
+
+    degraded := cfg
+    degraded.FilePath = ""
+    degraded.RotationFilePattern = ""
+    if degraded.Output == nil {
+        degraded.Output = os.Stderr
+    }
+    if l, ferr := NewLogger(degraded); ferr == nil {
+        return l, err
+    }
+
+    minimal := DefaultConfig()
+    minimal.Output = degraded.Output
+    minimal.Level = cfg.Level
+    minimal.ReportCaller = cfg.ReportCaller
+    if l, ferr := NewLogger(minimal); ferr == nil {
+        return l, err
+    }
+    // DefaultConfig 总能构建成功，这里仅作兜底
+    return &LogrusLogger{
+        Logger:  logrus.New(),
+        config:  minimal,
+        keyed:   newKeyedLimiter(minimal.KeyedWindow),
+        errSigs: newKeyedLimiter(minimal.ErrorSignatureWindow),
+        hookMu:  &sync.RWMutex{},
+    }, err
+}
+
+// equalIgnoringFuncs 与 reflect.DeepEqual 类似地逐层比较两个值，但跳过函数类型的值 (函数只能与 nil 比较)，
+// 指针与接口中的指针按地址比较
+func equalIgnoringFuncs(a, b reflect.Value) bool {
+    if a.Type() != b.Type() {
+        return false
+    }
+    switch a.Kind() {
+    case reflect.Func:
+        return true
+    case reflect.Struct:
+        for i := range a.NumField() {
+            if !equalIgnoringFuncs(a.Field(i), b.Field(i)) {
+                return false
+            }
+        }
+        return true
+    case reflect.Slice, reflect.Array:
+        if a.Len() != b.Len() || (a.Kind() == reflect.Slice && a.IsNil() != b.IsNil()) {
+            return false
+        }
+        for i := range a.Len() {
+            if !equalIgnoringFuncs(a.Index(i), b.Index(i)) {
+                return false
+            }
+        }
+        return true
+    case reflect.Map:
+        if a.Len() != b.Len() || a.IsNil() != b.IsNil() {
+            return false
+        }
+        for _, k := range a.MapKeys() {
+            bv := b.MapIndex(k)
+            if !bv.IsValid() || !equalIgnoringFuncs(a.MapIndex(k), bv) {
+                return false
+            }
+        }
+        return true
+    case reflect.Interface:
+        if a.IsNil() || b.IsNil() {
+            return a.IsNil() == b.IsNil()
+        }
+        return equalIgnoringFuncs(a.Elem(), b.Elem())
+    default:
+        return a.Equal(b)
+    }
+}
+
+// markGlobalInited 记录全局 Logger 的配置并标记为已初始化
+func markGlobalInited(cfg Config) {
+    globalConfig = cfg
+    globalInited.Store(true)
+}
+
+// GlobalLoggerInitialized 返回全局 Logger 是否已经初始化 (通过 InitGlobalLogger 或首次调用 GetGlobalLogger)
+func GlobalLoggerInitialized() bool {
+    return globalInited.Load()
+}
+
+// globalLogrusLogger 返回全局 Logger 的具体实现，用于 Logger 接口之外的方法；全局 Logger 总是由 NewLogger 或其回退创建
+func globalLogrusLogger() *LogrusLogger {
+    return GetGlobalLogger().(*LogrusLogger)
 }
 
 // GetGlobalLogger 获取全局 Logger 实例。
@@ -34,19 +144,23 @@ func InitGlobalLogger(cfg Config) {
 func GetGlobalLogger() Logger {
     globalLoggerOnce.Do(func() {
         cfg := DefaultConfig()
-        l, err := NewLogger(cfg)
+        defer markGlobalInited(cfg)
+        l, err := NewLoggerWithFallback(cfg)
         if err != nil {
-            logrus.SetOutput(cfg.Output)
-            logrus.SetLevel(logrus.ErrorLevel)
-            logrus.Errorf("Failed to initialize default global logger: %v. Falling back to basic logrus.", err)
-            globalLogger = &LogrusLogger{Logger: logrus.StandardLogger(), config: cfg}
-            return
+            l.Errorf("Failed to initialize default global logger: %v. Falling back to a degraded logger.", err)
         }
         globalLogger = l
     })
     return globalLogger
 }
 
+// Sync 写出全局 Logger 缓冲中的日志并将文件落盘，不会关闭 Logger，通常在 main 中 defer 调用:
+//
+//	defer log.Sync()
+func Sync() error {
+    return globalLogrusLogger().Sync()
+}
+
 // --- 全局日志方法 (方便直接调用) ---
 
 func Debugf(format string, args ...any) {
@@ -69,6 +183,26 @@ func Fatalf(format string, args ...any) {
     GetGlobalLogger().Fatalf(format, args...)
 }
 
+// ErrorfKeyed 使用全局 Logger 按去重键输出 Error 日志
+func ErrorfKeyed(key string, format string, args ...any) {
+    globalLogrusLogger().ErrorfKeyed(key, format, args...)
+}
+
+// Panicf 使用全局 Logger 输出 Panic 日志后 panic，按 format 限流，参见 LogrusLogger.Panicf
+func Panicf(format string, args ...any) {
+    globalLogrusLogger().Panicf(format, args...)
+}
+
+// PanicContextf 与 Panicf 相同，同时添加 Context 中的字段
+func PanicContextf(ctx context.Context, format string, args ...any) {
+    globalLogrusLogger().PanicContextf(ctx, format, args...)
+}
+
+// ErrorfSampled 使用全局 Logger 按错误签名 (消息模板 + 错误类型) 采样输出 Error 日志，参见 LogrusLogger.ErrorfSampled
+func ErrorfSampled(ctx context.Context, err error, format string, args ...any) {
+    errorfSampled(ctx, GetGlobalLogger(), err, format, args)
+}
+
 func DebugContextf(ctx context.Context, format string, args ...any) {
     GetGlobalLogger().DebugContextf(ctx, format, args...)
 }
@@ -88,3 +222,9 @@ func ErrorContextf(ctx context.Context, format string, args ...any) {
 func FatalContextf(ctx context.Context, format string, args ...any) {
     GetGlobalLogger().FatalContextf(ctx, format, args...)
 }
+
+// ErrorErr 输出一条 Error 日志: msg 原样作为消息，err 作为 error 字段输出而不是拼接进消息，
+// 便于按错误聚合与检索。err 为 nil 时只输出 msg。
+func ErrorErr(ctx context.Context, err error, msg string) {
+    globalLogrusLogger().WithFields(errorFields(err)).ErrorContextf(ctx, "%s", msg)
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+    "context"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// LogAt 以指定的事件时间 t 记录一条日志，用于导入、回填历史事件等场景，时间戳优先于 Config.Clock。
+// level 为 Fatal 时与 Fatalf 一样在写出后退出进程
+func (l *LogrusLogger) LogAt(t time.Time, level logrus.Level, format string, args ...any) {
+    l.LogAtContext(context.Background(), t, level, format, args...)
+}
+
+// LogAtContext 与 LogAt 相同，同时添加 Context 中的字段
+func (l *LogrusLogger) LogAtContext(ctx context.Context, t time.Time, level logrus.Level, format string, args ...any) {
+    if entry, emitLevel, ok := l.contextEntry(ctx, level); ok {
+        entry.Time = t
+        l.logf(entry, emitLevel, format, args...)
+    }
+    if level == logrus.FatalLevel {
+        l.Logger.Exit(1)
+    }
+}
+
+// LogAt 以指定的事件时间在所有 Logger 上记录日志
+func (m *MultiLogger) LogAt(t time.Time, level logrus.Level, format string, args ...any) {
+    m.LogAtContext(context.Background(), t, level, format, args...)
+}
+
+// LogAtContext 以指定的事件时间在所有 Logger 上记录带 Context 字段的日志，Fatal 级别的处理与 FatalContextf 相同
+func (m *MultiLogger) LogAtContext(ctx context.Context, t time.Time, level logrus.Level, format string, args ...any) {
+    if level == logrus.FatalLevel {
+        m.writeFatal(ctx, t, format, args)
+        m.finishFatal()
+        return
+    }
+    for _, l := range m.loggers {
+        if tl, ok := l.(timedLogger); ok {
+            tl.LogAtContext(ctx, t, level, format, args...)
+            continue
+        }
+        switch level {
+        case logrus.DebugLevel, logrus.TraceLevel:
+            l.DebugContextf(ctx, format, args...)
+        case logrus.InfoLevel:
+            l.InfoContextf(ctx, format, args...)
+        case logrus.WarnLevel:
+            l.WarnContextf(ctx, format, args...)
+        default:
+            l.ErrorContextf(ctx, format, args...)
+        }
+    }
+}
+
+// timedLogger 由支持指定事件时间的 Logger 实现，其他 Logger 以当前时间按对应级别输出
+type timedLogger interface {
+    LogAtContext(ctx context.Context, t time.Time, level logrus.Level, format string, args ...any)
+}
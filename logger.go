@@ -2,9 +2,14 @@ package log
 
 import (
     "context"
+    "errors"
+    "fmt"
     "io"
+    "maps"
     "os"
     "sync"
+    "sync/atomic"
+    "time"
 
     "github.com/sirupsen/logrus"
 )
@@ -29,85 +34,237 @@ type Logger interface {
     SetLevel(level logrus.Level)
     SetOutput(output io.Writer)
     SetFormatter(format LogFormat)
+    SetFormatterObject(f logrus.Formatter)
+
+    // IsTerminal 判断日志是否写入终端 (TTY)，便于调用方决定是否输出颜色、进度条等，非文件类型的输出目标返回 false
+    IsTerminal() bool
+}
+
+// FieldLogger 由支持绑定字段的 Logger (*LogrusLogger、*MultiLogger) 实现。
+// 中间件、Builder 等需要附加字段的功能通过类型断言使用它，Logger 未实现时省略这些字段
+type FieldLogger interface {
+    WithField(key string, value any) Logger
+    WithFields(fields map[string]any) Logger
+}
+
+// ConfigProvider 由可以返回生效配置的 Logger (*LogrusLogger、*MultiLogger) 实现
+type ConfigProvider interface {
+    GetConfig() Config
+}
+
+// withFields 返回绑定了 fields 的 Logger，l 未实现 FieldLogger 时原样返回
+func withFields(l Logger, fields map[string]any) Logger {
+    if fl, ok := l.(FieldLogger); ok && len(fields) > 0 {
+        return fl.WithFields(fields)
+    }
+    return l
 }
 
 // LogrusLogger 是 Logger 接口的 Logrus 实现
 type LogrusLogger struct {
     *logrus.Logger
     config Config
-    mu     sync.RWMutex // 用于保护配置修改
+    mu     sync.RWMutex  // 用于保护配置修改
+    router *levelRouter  // 按级别分流输出，未启用时为 nil
+    fields logrus.Fields // 通过 WithField/WithFields 显式绑定的字段
+    keyed  *keyedLimiter // ErrorfKeyed 的去重状态，父子 Logger 共享
+    panics *keyedLimiter // Panicf 按消息限流的状态，父子 Logger 共享，未启用时为 nil
+    file   fileSink      // NewLogger 根据 FilePath 或 RotationFilePattern 打开的文件，由 Close 关闭
+
+    errSigs *keyedLimiter // ErrorfSampled 按错误签名采样的状态，父子 Logger 共享
+
+    sampler *levelSampler   // 按级别采样，未配置时为 nil
+    hooks   *hookDispatcher // 按优先级调度的 Hook，父子 Logger 共享
+    hookMu  *sync.RWMutex   // 保护 Logger.Hooks 的注册与读取 (见 AddHook)，父子 Logger 共享
+    buffer  *bufferedWriter // 缓冲输出，仅在 Config.BufferSize > 0 时非 nil
+
+    tracker *writeErrorTracker // 记录写入错误，仅在 Config.PropagateWriteErrors 时非 nil
+
+    load *loadMonitor // 统计输出速率，高负载时收紧级别，未配置 Config.AdaptiveLevel 时为 nil
+
+    newline *newlineWriter // 调整末尾换行符，位于最外层，Config.TrailingNewline 为 auto 时为 nil
+
+    seq *atomic.Uint64 // 条目序号，父子 Logger 共享，未开启 Config.IncludeSequence 时为 nil
+    tee *teeWriter     // 输出链的最外层，支持运行时添加旁路输出 (AddTee)
 }
 
 // NewLogger 创建并返回一个新的 Logger 实例
 func NewLogger(cfg Config) (Logger, error) {
     l := logrus.New()
 
+    // 复制提取器，避免调用方之后修改 map 影响已创建的 Logger
+    cfg.ContextExtractors = maps.Clone(cfg.ContextExtractors)
+
     // 设置日志级别
     l.SetLevel(cfg.Level)
 
-    // 设置输出目标
-    if cfg.FilePath != "" {
-        file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+    if err := validateCallerFields(cfg.CallerFields); err != nil {
+        return nil, err
+    }
+    if err := cfg.TrailingNewline.validate(); err != nil {
+        return nil, err
+    }
+    if cfg.TimePrecision != "" {
+        layout, err := cfg.TimePrecision.layout()
         if err != nil {
             return nil, err
         }
-        l.SetOutput(file)
-    } else {
-        l.SetOutput(cfg.Output)
+        cfg.TimestampFormat = layout
     }
 
     // 设置日志格式
-    if cfg.EnableJSON || cfg.Format == FormatJSON {
-        jsonFormatter := &logrus.JSONFormatter{
-            TimestampFormat:   cfg.TimestampFormat,
-            DisableTimestamp:  false,
-            DisableHTMLEscape: true,
-            FieldMap:          nil,
-            CallerPrettyfier:  nil,
-            PrettyPrint:       cfg.JSONPretty, // JSON格式美化输出
+    formatter, err := newFormatter(cfg)
+    if err != nil {
+        return nil, err
+    }
+
+    // 设置输出目标
+    var out io.Writer = cfg.Output
+    var file fileSink
+    switch {
+    case cfg.RotationFilePattern != "":
+        rotating, err := newRotatingWriter(cfg)
+        if err != nil {
+            return nil, err
+        }
+        file, out = rotating, rotating
+    case cfg.FilePath != "":
+        f, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+        if err != nil {
+            return nil, err
         }
-        l.SetFormatter(jsonFormatter)
+        file, out = f, f
+    }
+
+    // 缓冲输出，只作用于普通级别的输出目标
+    var buffer *bufferedWriter
+    if cfg.BufferSize > 0 {
+        buffer = newBufferedWriter(cfg.Context, out, cfg.BufferSize, cfg.FlushInterval, cfg.SyncFlushLevel)
+        out = buffer
+    }
+    l.ExitFunc = newExitFunc(cfg, buffer, file)
+
+    // 按级别分流输出
+    var router *levelRouter
+    if errOut := cfg.errorOutput(); errOut != nil {
+        router = newLevelRouter(out, errOut, cfg.ErrorOutputLevel)
+        out = router
+    }
+    var seq *atomic.Uint64
+    if cfg.IncludeSequence {
+        seq = new(atomic.Uint64)
+    }
+    formatter = wrapLevelFormatter(wrapSequenceFormatter(formatter, seq), router, buffer)
 
-    } else {
-        l.SetFormatter(&logrus.TextFormatter{
-            FullTimestamp:   true,
-            TimestampFormat: cfg.TimestampFormat,
-            ForceColors:     true, // 强制终端颜色
-            DisableColors:   false,
-        })
+    // 记录写入错误
+    var tracker *writeErrorTracker
+    if cfg.PropagateWriteErrors {
+        tracker = &writeErrorTracker{w: out}
+        out = tracker
     }
 
-    // 添加 Caller Hook,
-    if cfg.ReportCaller {
-        l.AddHook(NewCallerHook(CallerSkipFrames))
+    // 调整末尾换行符，放在最外层以便按条处理，缓冲区中的多条日志不受影响
+    newline := newNewlineWriter(out, cfg.TrailingNewline)
+    if newline != nil {
+        out = newline
     }
 
+    // 旁路输出，始终位于最外层
+    tee := &teeWriter{w: out}
+    out = tee
+
+    l.SetOutput(out)
+    l.SetFormatter(formatter)
+
+    // 还原强制级别条目的真实级别，必须先于其他 Hook 执行
+    l.AddHook(forcedLevelHook{})
+
+    // 添加 Caller Hook, ErrorFormatVerbose 开启时 Error 及以上级别始终记录调用者
+    callerLevels := cfg.CallerLevels
+    if cfg.ErrorFormatVerbose {
+        callerLevels = verboseCallerLevels(cfg)
+    }
+    if (cfg.ReportCaller || cfg.ErrorFormatVerbose) && (cfg.CallerFields == nil || len(cfg.CallerFields) > 0) {
+        hook := NewCallerHook(CallerSkipFrames)
+        hook.ReportLevels = callerLevels
+        hook.SkipPackages = cfg.CallerSkipPackages
+        hook.Fields = cfg.CallerFields
+        hook.URIScheme = cfg.CallerURIScheme
+        hook.depth = calibratedCallerDepth()
+        l.AddHook(hook)
+    }
+    if cfg.ErrorFormatVerbose {
+        l.AddHook(&stacktraceHook{caller: CallerHook{SkipPackages: cfg.CallerSkipPackages}})
+    }
+
+    // 添加构建信息 Hook
+    if cfg.IncludeBuildInfo {
+        l.AddHook(NewBuildInfoHook())
+    }
+
+    // 添加 Kubernetes 元数据 Hook，不在 Kubernetes 中运行时不添加
+    if cfg.IncludeK8sMetadata {
+        if hook := NewK8sMetadataHook(); hook != nil {
+            l.AddHook(hook)
+        }
+    }
+
+    // 按优先级调度的 Hook，放在内置 Hook 之后，使其能读取调用者等字段
+    hooks := &hookDispatcher{}
+    l.AddHook(hooks)
+
     return &LogrusLogger{
-        Logger: l,
-        config: cfg,
+        Logger:  l,
+        config:  cfg,
+        router:  router,
+        keyed:   newKeyedLimiter(cfg.KeyedWindow),
+        panics:  newPanicLimiter(cfg.PanicThrottleWindow),
+        errSigs: newKeyedLimiter(cfg.ErrorSignatureWindow),
+        file:    file,
+        tracker: tracker,
+        sampler: newLevelSampler(cfg.LevelSampleRates),
+        hooks:   hooks,
+        hookMu:  &sync.RWMutex{},
+        buffer:  buffer,
+        load:    newLoadMonitor(cfg.AdaptiveLevel),
+        newline: newline,
+        seq:     seq,
+        tee:     tee,
     }, nil
 }
 
 // Debugf --- Logger 接口实现 ---
 // 为了 SkipFrames 一致，需要保持和 XXContextf 一样的调用方式
 func (l *LogrusLogger) Debugf(format string, args ...any) {
-    l.Logger.WithContext(context.Background()).Debugf(format, args...)
+    if !l.allow(logrus.DebugLevel) {
+        return
+    }
+    l.newEntry(context.Background()).Debugf(format, args...)
 }
 
 func (l *LogrusLogger) Infof(format string, args ...any) {
-    l.Logger.WithContext(context.Background()).Infof(format, args...)
+    if !l.allow(logrus.InfoLevel) {
+        return
+    }
+    l.newEntry(context.Background()).Infof(format, args...)
 }
 
 func (l *LogrusLogger) Warnf(format string, args ...any) {
-    l.Logger.WithContext(context.Background()).Warnf(format, args...)
+    if !l.allow(logrus.WarnLevel) {
+        return
+    }
+    l.newEntry(context.Background()).Warnf(format, args...)
 }
 
 func (l *LogrusLogger) Errorf(format string, args ...any) {
-    l.Logger.WithContext(context.Background()).Errorf(format, args...)
+    if !l.allow(logrus.ErrorLevel) {
+        return
+    }
+    l.newEntry(context.Background()).Errorf(format, args...)
 }
 
 func (l *LogrusLogger) Fatalf(format string, args ...any) {
-    l.Logger.WithContext(context.Background()).Fatalf(format, args...)
+    l.newEntry(context.Background()).Fatalf(format, args...)
 }
 
 // --- 带上下文（Context）方法实现 ---
@@ -117,57 +274,174 @@ func (l *LogrusLogger) Fatalf(format string, args ...any) {
 // 这里我们通过 WithField("context", ctx) 来简单演示，实际应用中会更精细地处理。
 // 更好的做法是：从 context 中提取 trace/span ID，并使用 WithField 添加。
 
-// addContextFields 从 Context 中提取预定义的字段并添加到 Logrus Entry
+// newEntry 构建一条携带全部字段的 Logrus Entry。
+// 同名字段的优先级为: 显式绑定字段 (WithField/WithFields) > Context 字段 > 默认字段 (Config.DefaultFields)，
+// 高优先级的字段先写入，低优先级的来源只补充尚不存在的键。
+func (l *LogrusLogger) newEntry(ctx context.Context) *logrus.Entry {
+    entry := l.Logger.WithContext(ctx)
+    if l.config.Clock != nil {
+        entry.Time = l.config.Clock()
+    }
+    if len(l.fields) > 0 {
+        entry = entry.WithFields(l.fields)
+    }
+    entry = l.addContextFields(ctx, entry) // 添加上下文字段
+    for k, v := range l.config.DefaultFields {
+        setFieldIfAbsent(entry, k, v)
+    }
+    if l.config.DefaultSeverity != "" {
+        setFieldIfAbsent(entry, l.prefixed(string(SeverityKey)), string(l.config.DefaultSeverity))
+    }
+    return entry
+}
+
+// addContextFields 从 Context 中提取预定义的字段并添加到 Logrus Entry，
+// 字段名按 Config.FieldPrefix 添加前缀，不会覆盖 Entry 中已存在的同名字段
 func (l *LogrusLogger) addContextFields(ctx context.Context, entry *logrus.Entry) *logrus.Entry {
     if reqID, ok := GetRequestID(ctx); ok {
-        entry = entry.WithField(string(RequestIDKey), reqID)
+        setFieldIfAbsent(entry, l.prefixed(string(RequestIDKey)), reqID)
     }
     if userID, ok := GetUserID(ctx); ok {
-        entry = entry.WithField(string(UserIDKey), userID)
+        setFieldIfAbsent(entry, l.prefixed(string(UserIDKey)), userID)
     }
     if traceID, ok := GetTraceID(ctx); ok {
-        entry = entry.WithField(string(TraceIDKey), traceID)
+        setFieldIfAbsent(entry, l.prefixed(string(TraceIDKey)), traceID)
     }
     if spanID, ok := GetSpanID(ctx); ok {
-        entry = entry.WithField(string(SpanIDKey), spanID)
+        setFieldIfAbsent(entry, l.prefixed(string(SpanIDKey)), spanID)
+    }
+    if operation, ok := GetOperation(ctx); ok {
+        setFieldIfAbsent(entry, l.prefixed(string(OperationKey)), operation)
+    }
+    if severity, ok := GetSeverity(ctx); ok {
+        setFieldIfAbsent(entry, l.prefixed(string(SeverityKey)), string(severity))
+    }
+    // 处理作用域字段
+    if scopeFields, ok := GetScopeFields(ctx); ok {
+        for k, v := range scopeFields {
+            setFieldIfAbsent(entry, l.prefixed(k), v)
+        }
     }
     // 处理自定义字段
     if customFields, ok := GetCustomFields(ctx); ok {
         for k, v := range customFields {
-            entry = entry.WithField(k, v)
+            setFieldIfAbsent(entry, l.prefixed(k), v)
         }
     }
+    if dropped, ok := GetDroppedFields(ctx); ok {
+        setFieldIfAbsent(entry, l.prefixed(DroppedFieldsFieldKey), dropped)
+    }
+    // 处理提取器
+    l.applyExtractors(ctx, entry)
+    // 处理白名单中的 baggage
+    l.applyBaggage(ctx, entry)
     return entry
 }
 
+// prefixed 返回添加了 Config.FieldPrefix 的字段名
+func (l *LogrusLogger) prefixed(key string) string {
+    return l.config.FieldPrefix + key
+}
+
+// setFieldIfAbsent 仅在 Entry 尚无该字段时写入。
+// entry 必须是 newEntry 中新建的实例，其 Data 不与其他 Entry 共享。
+func setFieldIfAbsent(entry *logrus.Entry, key string, value any) {
+    if _, exists := entry.Data[key]; !exists {
+        entry.Data[key] = value
+    }
+}
+
 func (l *LogrusLogger) DebugContextf(ctx context.Context, format string, args ...any) {
-    entry := l.Logger.WithContext(ctx)
-    entry = l.addContextFields(ctx, entry) // 添加上下文字段
-    entry.Debugf(format, args...)
+    entry, level, ok := l.contextEntry(ctx, logrus.DebugLevel)
+    if !ok {
+        return
+    }
+    l.logf(entry, level, format, args...)
 }
 
 func (l *LogrusLogger) InfoContextf(ctx context.Context, format string, args ...any) {
-    entry := l.Logger.WithContext(ctx)
-    entry = l.addContextFields(ctx, entry) // 添加上下文字段
-    entry.Infof(format, args...)
+    entry, level, ok := l.contextEntry(ctx, logrus.InfoLevel)
+    if !ok {
+        return
+    }
+    l.logf(entry, level, format, args...)
 }
 
 func (l *LogrusLogger) WarnContextf(ctx context.Context, format string, args ...any) {
-    entry := l.Logger.WithContext(ctx)
-    entry = l.addContextFields(ctx, entry) // 添加上下文字段
-    entry.Warnf(format, args...)
+    entry, level, ok := l.contextEntry(ctx, logrus.WarnLevel)
+    if !ok {
+        return
+    }
+    l.logf(entry, level, format, args...)
 }
 
 func (l *LogrusLogger) ErrorContextf(ctx context.Context, format string, args ...any) {
-    entry := l.Logger.WithContext(ctx)
-    entry = l.addContextFields(ctx, entry) // 添加上下文字段
-    entry.Errorf(format, args...)
+    entry, level, ok := l.contextEntry(ctx, logrus.ErrorLevel)
+    if !ok {
+        return
+    }
+    l.logf(entry, level, format, args...)
+}
+
+// ErrorErr 输出一条 Error 日志，err 作为 error 字段输出，参见全局函数 ErrorErr
+func (l *LogrusLogger) ErrorErr(ctx context.Context, err error, msg string) {
+    l.WithFields(errorFields(err)).ErrorContextf(ctx, "%s", msg)
+}
+
+// errorFields 返回 err 对应的 error 字段，err 为 nil 时返回 nil
+func errorFields(err error) map[string]any {
+    if err == nil {
+        return nil
+    }
+    return map[string]any{logrus.ErrorKey: err}
 }
 
 func (l *LogrusLogger) FatalContextf(ctx context.Context, format string, args ...any) {
-    entry := l.Logger.WithContext(ctx)
-    entry = l.addContextFields(ctx, entry) // 添加上下文字段
-    entry.Fatalf(format, args...)
+    l.newEntry(ctx).Fatalf(format, args...)
+}
+
+// --- 字段绑定方法实现 ---
+
+// WithField 返回绑定了单个字段的子 Logger。
+// 子 Logger 与原 Logger 共享底层输出、级别与 Hook，配置为创建时的快照。
+func (l *LogrusLogger) WithField(key string, value any) Logger {
+    return l.WithFields(map[string]any{key: value})
+}
+
+// WithFields 返回绑定了多个字段的子 Logger，同名字段以新值为准
+func (l *LogrusLogger) WithFields(fields map[string]any) Logger {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+
+    merged := make(logrus.Fields, len(l.fields)+len(fields))
+    for k, v := range l.fields {
+        merged[k] = v
+    }
+    for k, v := range fields {
+        if l.config.PrefixExplicitFields {
+            k = l.prefixed(k)
+        }
+        merged[k] = v
+    }
+    return &LogrusLogger{
+        Logger:  l.Logger,
+        config:  l.config,
+        router:  l.router,
+        fields:  merged,
+        keyed:   l.keyed,
+        panics:  l.panics,
+        errSigs: l.errSigs,
+        file:    l.file,
+        tracker: l.tracker,
+        sampler: l.sampler,
+        hooks:   l.hooks,
+        hookMu:  l.hookMu,
+        buffer:  l.buffer,
+        load:    l.load,
+        newline: l.newline,
+        seq:     l.seq,
+        tee:     l.tee,
+    }
 }
 
 // --- 动态配置方法实现 ---
@@ -182,9 +456,25 @@ func (l *LogrusLogger) SetLevel(level logrus.Level) {
 func (l *LogrusLogger) SetOutput(output io.Writer) {
     l.mu.Lock()
     defer l.mu.Unlock()
-    l.Logger.SetOutput(output)
+    switch {
+    case l.buffer != nil:
+        // 缓冲位于最内层，先写出已缓冲的内容再切换
+        _ = l.buffer.setWriter(output)
+    case l.router != nil:
+        // 分流启用时只替换普通级别的输出目标
+        l.router.setOutput(output)
+    case l.tracker != nil:
+        l.tracker.setWriter(output)
+    case l.newline != nil:
+        l.newline.setWriter(output)
+    case l.tee != nil:
+        l.tee.setWriter(output)
+    default:
+        l.Logger.SetOutput(output)
+    }
     l.config.Output = output
     l.config.FilePath = "" // 如果手动设置了输出，则清空文件路径
+    l.config.RotationFilePattern = ""
 }
 
 func (l *LogrusLogger) SetFormatter(format LogFormat) {
@@ -192,20 +482,107 @@ func (l *LogrusLogger) SetFormatter(format LogFormat) {
     defer l.mu.Unlock()
 
     l.config.Format = format
-    if format == FormatJSON {
-        l.Logger.SetFormatter(&logrus.JSONFormatter{
-            TimestampFormat: l.config.TimestampFormat,
-            PrettyPrint:     l.config.JSONPretty,
-        })
-        l.config.EnableJSON = true
-    } else {
-        l.Logger.SetFormatter(&logrus.TextFormatter{
-            FullTimestamp:   true,
-            TimestampFormat: l.config.TimestampFormat,
-            ForceColors:     true,
-            DisableColors:   false,
-        })
-        l.config.EnableJSON = false
+    l.config.EnableJSON = format == FormatJSON
+
+    formatter, err := newFormatter(l.config)
+    if err != nil {
+        // 配置已在 NewLogger 中校验过，这里仅作兜底
+        formatter = &logrus.TextFormatter{FullTimestamp: true, TimestampFormat: l.config.TimestampFormat}
     }
+    l.Logger.SetFormatter(wrapLevelFormatter(wrapSequenceFormatter(formatter, l.seq), l.router, l.buffer))
+}
+
+// SetFormatterObject 安装一个完全自定义的 logrus.Formatter，配置中的 Format 随之标记为 FormatCustom。
+// 自定义 Formatter 直接负责编码，不再经过字段白名单、命名风格转换等条目变换；
+// 之后调用 SetFormatter 可切换回内置或注册的格式。
+func (l *LogrusLogger) SetFormatterObject(f logrus.Formatter) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
 
+    l.config.Format = FormatCustom
+    l.config.EnableJSON = false
+    l.Logger.SetFormatter(wrapLevelFormatter(wrapSequenceFormatter(f, l.seq), l.router, l.buffer))
+}
+
+// Unwrap 返回底层的 *logrus.Logger，用于配置本库未暴露的 logrus 能力 (例如添加自定义 Hook)。
+// 注意: 直接修改底层 Logger 不经过 LogrusLogger 的锁，也不会同步到 GetConfig 返回的配置中。
+func (l *LogrusLogger) Unwrap() *logrus.Logger {
+    return l.Logger
+}
+
+// GetConfig 返回当前生效配置的快照
+func (l *LogrusLogger) GetConfig() Config {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+    return l.config
+}
+
+// LastError 返回最近一次写入的错误。
+// 多个 goroutine 并发写入时，返回的是全局最后一次写入的结果，需要逐条确认的场景应串行写入。
+func (l *LogrusLogger) LastError() error {
+    if l.tracker == nil {
+        return nil
+    }
+    return l.tracker.lastError()
+}
+
+// Close 写出缓冲区中剩余的日志并关闭 NewLogger 根据 FilePath 打开的文件。
+// 子 Logger 与父 Logger 共享该文件，关闭任意一个都会使其他 Logger 无法继续写入文件。
+func (l *LogrusLogger) Close() error {
+    var errs []error
+    if l.buffer != nil {
+        errs = append(errs, l.buffer.Close())
+    }
+    if l.file != nil {
+        errs = append(errs, l.file.Close())
+    }
+    return errors.Join(errs...)
+}
+
+// ErrCloseTimeout 表示 CloseWithTimeout 未能在期限内写出剩余日志
+var ErrCloseTimeout = errors.New("log: close timed out")
+
+// CloseWithTimeout 在期限 d 内执行 Close。输出目标阻塞 (例如网络存储无响应) 时返回 ErrCloseTimeout，
+// 尚未写出的日志被放弃，使进程可以继续退出；后台的关闭操作在输出目标恢复后才会结束。
+func (l *LogrusLogger) CloseWithTimeout(d time.Duration) error {
+    done := make(chan error, 1)
+    go func() {
+        done <- l.Close()
+    }()
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+    select {
+    case err := <-done:
+        return err
+    case <-timer.C:
+        return fmt.Errorf("%w after %v", ErrCloseTimeout, d)
+    }
+}
+
+// Sync 写出缓冲区中的日志，随后对 FilePath 对应的文件执行 fsync；
+// 若 Output 实现了 Flush() error (例如 kafka.Writer)，同时调用其 Flush。不会关闭任何资源。
+func (l *LogrusLogger) Sync() error {
+    var errs []error
+    if l.buffer != nil {
+        errs = append(errs, l.buffer.Flush())
+    }
+    if l.file != nil {
+        errs = append(errs, l.file.Sync())
+    } else if f, ok := l.config.Output.(flusher); ok {
+        errs = append(errs, f.Flush())
+    }
+    return errors.Join(errs...)
+}
+
+// flusher 是支持写出内部缓冲的输出目标
+type flusher interface {
+    Flush() error
+}
+
+// Flush 将缓冲区中尚未输出的日志写出，未启用缓冲时直接返回 nil
+func (l *LogrusLogger) Flush() error {
+    if l.buffer == nil {
+        return nil
+    }
+    return l.buffer.Flush()
 }
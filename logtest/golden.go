@@ -0,0 +1,36 @@
+package logtest
+
+import (
+    "io"
+    "time"
+
+    "github.com/sirupsen/logrus"
+
+    "github.com/sapaude/go-shims/x/log"
+)
+
+// GoldenTime 是 NewGoldenLogger 输出的固定时间戳
+var GoldenTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// GoldenLayout 是 NewGoldenLogger 使用的文本行模板
+const GoldenLayout = "{time} {level} {msg} {fields}"
+
+// NewGoldenLogger 创建输出稳定、可用于 golden 文件快照测试的 Logger:
+// 时间戳固定为 GoldenTime，字段按键名排序，不输出颜色与调用者信息 (文件路径因机器而异)，级别为 Debug。
+// 同样的调用序列总是得到逐字节相同的输出。
+func NewGoldenLogger(w io.Writer) log.Logger {
+    cfg := log.DefaultConfig()
+    cfg.Output = w
+    cfg.Format = log.FormatText
+    cfg.TextLayout = GoldenLayout
+    cfg.TimestampFormat = time.RFC3339
+    cfg.Level = logrus.DebugLevel
+    cfg.ReportCaller = false
+    cfg.Clock = func() time.Time { return GoldenTime }
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        // 以上配置总是合法的
+        panic("logtest: failed to create golden logger: " + err.Error())
+    }
+    return l
+}
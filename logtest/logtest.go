@@ -0,0 +1,55 @@
+// Package logtest 提供在单元测试中使用的 Logger，日志通过 testing.TB 输出，
+// 因此会归属到对应的测试用例，并且仅在测试失败或使用 -v 时显示。
+package logtest
+
+import (
+    "bytes"
+    "sync"
+    "testing"
+
+    "github.com/sapaude/go-shims/x/log"
+)
+
+// NewTBLogger 创建一个通过 tb.Log 输出每一行日志的 Logger。
+// cfg 中的 Output 与 FilePath 会被忽略。测试结束后（包括泄漏的 goroutine）写入的日志会被丢弃，
+// 避免在测试结束后调用 tb.Log 导致 panic。
+func NewTBLogger(tb testing.TB, cfg log.Config) log.Logger {
+    tb.Helper()
+
+    w := &tbWriter{tb: tb}
+    tb.Cleanup(w.stop)
+
+    cfg.Output = w
+    cfg.FilePath = ""
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        tb.Fatalf("logtest: failed to create logger: %v", err)
+    }
+    return l
+}
+
+// tbWriter 将写入的内容按行转发给 tb.Log
+type tbWriter struct {
+    mu      sync.Mutex
+    tb      testing.TB
+    stopped bool
+}
+
+// Write 实现 io.Writer
+func (w *tbWriter) Write(p []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.stopped {
+        return len(p), nil
+    }
+    for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+        w.tb.Log(string(line))
+    }
+    return len(p), nil
+}
+
+func (w *tbWriter) stop() {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.stopped = true
+}
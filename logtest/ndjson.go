@@ -0,0 +1,85 @@
+package logtest
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "sync"
+    "testing"
+)
+
+// NDJSONValidator 校验写入的每条日志都是合法的 NDJSON 行: 恰好一个 JSON 对象，以单个 "\n" 结尾，
+// 内部不含换行符。校验后原样转发给底层 Writer，违规只被记录，不影响输出。
+// 用于发现 Formatter 缺陷或未经处理的字段破坏按行消费的日志管道。
+type NDJSONValidator struct {
+    mu         sync.Mutex
+    w          io.Writer
+    lines      int
+    violations []error
+}
+
+// NewNDJSONValidator 创建转发到 w 的校验器，w 为 nil 时丢弃输出
+func NewNDJSONValidator(w io.Writer) *NDJSONValidator {
+    if w == nil {
+        w = io.Discard
+    }
+    return &NDJSONValidator{w: w}
+}
+
+// Write 实现 io.Writer，p 为一条完整的日志
+func (v *NDJSONValidator) Write(p []byte) (int, error) {
+    v.mu.Lock()
+    v.lines++
+    if err := validateNDJSONLine(p); err != nil {
+        v.violations = append(v.violations, fmt.Errorf("entry %d: %w: %q", v.lines, err, p))
+    }
+    v.mu.Unlock()
+    return v.w.Write(p)
+}
+
+// Violations 返回目前记录到的全部违规
+func (v *NDJSONValidator) Violations() []error {
+    v.mu.Lock()
+    defer v.mu.Unlock()
+    return append([]error(nil), v.violations...)
+}
+
+// Err 将全部违规合并为一个错误，没有违规时返回 nil
+func (v *NDJSONValidator) Err() error {
+    return errors.Join(v.Violations()...)
+}
+
+// CheckNDJSON 返回转发到 w 的校验器，并在测试结束时通过 tb.Errorf 报告所有违规，
+// 通常用作 Config.Output:
+//
+//	cfg.Output = logtest.CheckNDJSON(t, &buf)
+func CheckNDJSON(tb testing.TB, w io.Writer) *NDJSONValidator {
+    tb.Helper()
+    v := NewNDJSONValidator(w)
+    tb.Cleanup(func() {
+        for _, err := range v.Violations() {
+            tb.Errorf("logtest: invalid NDJSON: %v", err)
+        }
+    })
+    return v
+}
+
+// validateNDJSONLine 检查 p 是否为一个以换行结尾的单行 JSON 对象
+func validateNDJSONLine(p []byte) error {
+    line, ok := bytes.CutSuffix(p, []byte("\n"))
+    if !ok {
+        return errors.New("missing trailing newline")
+    }
+    if bytes.ContainsAny(line, "\r\n") {
+        return errors.New("embedded newline")
+    }
+    if !json.Valid(line) {
+        return errors.New("invalid JSON")
+    }
+    if trimmed := bytes.TrimSpace(line); len(trimmed) == 0 || trimmed[0] != '{' {
+        return errors.New("not a JSON object")
+    }
+    return nil
+}
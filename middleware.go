@@ -0,0 +1,299 @@
+package log
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "regexp"
+    "strings"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// RedactedValue 是被脱敏字段的替换值
+const RedactedValue = "[REDACTED]"
+
+// truncatedSuffix 追加在被截断的请求/响应体之后
+const truncatedSuffix = "...(truncated)"
+
+// defaultRedactedHeaders 默认脱敏的 HTTP Header
+var defaultRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// HTTPOption 定义 HTTPMiddleware 的可选配置
+type HTTPOption func(*httpOptions)
+
+type httpOptions struct {
+    maxBodyBytes    int // > 0 时记录请求/响应体
+    redactor        bodyRedactor
+    redactedHeaders []string
+    slowThreshold   time.Duration // > 0 时开启请求级缓冲，见 WithSlowThreshold
+    trustUserID     bool          // 是否从 X-User-ID Header 读取用户 ID，见 WithTrustedUserIDHeader
+}
+
+// WithMaxBodyBytes 启用请求体与响应体记录，每个最多记录 n 字节，超出部分截断。
+// 请求体会被预读后还原，处理函数仍可完整读取。请求体、响应体与请求头只在 Debug 级别输出。
+func WithMaxBodyBytes(n int) HTTPOption {
+    return func(o *httpOptions) {
+        o.maxBodyBytes = n
+    }
+}
+
+// WithRedactedBodyKeys 指定请求/响应体中需要脱敏的 JSON 键，匹配不区分大小写，嵌套对象中的同名键同样脱敏
+func WithRedactedBodyKeys(keys ...string) HTTPOption {
+    return func(o *httpOptions) {
+        o.redactor.add(keys...)
+    }
+}
+
+// WithRedactedHeaders 指定记录请求头时需要脱敏的 Header，默认脱敏 Authorization、Cookie 与 Set-Cookie
+func WithRedactedHeaders(headers ...string) HTTPOption {
+    return func(o *httpOptions) {
+        o.redactedHeaders = append(o.redactedHeaders, headers...)
+    }
+}
+
+// WithSlowThreshold 为每个请求开启日志缓冲 (见 BeginRequestBuffer): 处理函数通过请求 Context 记录的 Debug/Info 日志
+// 在请求成功 (状态码 < 500) 且耗时低于 d 时丢弃；失败时按原级别输出；慢请求提升到 SlowRequestLevel 输出
+func WithSlowThreshold(d time.Duration) HTTPOption {
+    return func(o *httpOptions) {
+        o.slowThreshold = d
+    }
+}
+
+// WithTrustedUserIDHeader 从 X-User-ID Header 读取用户 ID 写入 Context (见 ExtractUserIDHeader)。
+// 客户端可以伪造该 Header，只应在它由可信的上游 (如认证网关) 设置时开启
+func WithTrustedUserIDHeader() HTTPOption {
+    return func(o *httpOptions) {
+        o.trustUserID = true
+    }
+}
+
+// HTTPMiddleware 返回记录 HTTP 请求日志的中间件。
+// 它从请求头中提取请求 ID 等关联字段写入 Context (见 ExtractHeaders)，并在请求结束后输出一条 Info 日志，
+// 包含 http_method、http_path、http_status、http_duration_ms 与 http_response_bytes 字段 (l 需实现 FieldLogger)。
+func HTTPMiddleware(l Logger, opts ...HTTPOption) func(http.Handler) http.Handler {
+    o := &httpOptions{
+        redactedHeaders: append([]string(nil), defaultRedactedHeaders...),
+    }
+    for _, opt := range opts {
+        opt(o)
+    }
+
+    // 每个请求都会判断是否处于 Debug 级别，优先使用 IsLevelEnabled 避免复制整个 Config
+    debugEnabled := func() bool { return false }
+    if le, ok := l.(interface{ IsLevelEnabled(logrus.Level) bool }); ok {
+        debugEnabled = func() bool { return le.IsLevelEnabled(logrus.DebugLevel) }
+    } else if cp, ok := l.(ConfigProvider); ok {
+        debugEnabled = func() bool { return cp.GetConfig().Level >= logrus.DebugLevel }
+    }
+
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            start := time.Now()
+            ctx := ExtractHeaders(r.Context(), r.Header)
+            if o.trustUserID {
+                ctx = ExtractUserIDHeader(ctx, r.Header)
+            }
+            if o.slowThreshold > 0 {
+                r = r.WithContext(BeginRequestBuffer(ctx))
+            } else {
+                r = r.WithContext(ctx)
+            }
+
+            captureBody := o.maxBodyBytes > 0 && debugEnabled()
+            var reqBody []byte
+            var reqTruncated bool
+            if captureBody && r.Body != nil && r.Body != http.NoBody {
+                reqBody, reqTruncated = peekBody(r, o.maxBodyBytes)
+            }
+
+            rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+            if captureBody {
+                rec.limit = o.maxBodyBytes
+            }
+            next.ServeHTTP(rec, r)
+
+            if o.slowThreshold > 0 {
+                var err error
+                if rec.status >= http.StatusInternalServerError {
+                    err = fmt.Errorf("http status %d", rec.status)
+                }
+                FlushIfErrorOrSlow(r.Context(), err, o.slowThreshold)
+            }
+
+            withFields(l, map[string]any{
+                "http_method":         r.Method,
+                "http_path":           r.URL.Path,
+                "http_status":         rec.status,
+                "http_duration_ms":    time.Since(start).Milliseconds(),
+                "http_response_bytes": rec.written,
+            }).InfoContextf(ctx, "http request")
+
+            if captureBody {
+                withFields(l, map[string]any{
+                    "http_request_headers": redactHeaders(r.Header, o.redactedHeaders),
+                    "http_request_body":    o.renderBody(reqBody, reqTruncated),
+                    "http_response_body":   o.renderBody(rec.body.Bytes(), rec.truncated),
+                }).DebugContextf(ctx, "http request body")
+            }
+        })
+    }
+}
+
+// peekBody 预读最多 limit 字节的请求体，并将其还原到 r.Body 以便处理函数继续读取
+func peekBody(r *http.Request, limit int) ([]byte, bool) {
+    buf, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+    r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), r.Body), Closer: r.Body}
+    if err != nil {
+        return nil, false
+    }
+    if len(buf) > limit {
+        return buf[:limit], true
+    }
+    return buf, false
+}
+
+type readCloser struct {
+    io.Reader
+    io.Closer
+}
+
+// renderBody 返回脱敏并截断后的请求/响应体
+func (o *httpOptions) renderBody(body []byte, truncated bool) string {
+    s := o.redactor.redact(body)
+    if truncated {
+        s += truncatedSuffix
+    }
+    return s
+}
+
+// bodyRedactor 将请求/响应体中指定 JSON 键的值替换为 RedactedValue
+type bodyRedactor struct {
+    keys     map[string]struct{} // 小写的键名
+    patterns []*regexp.Regexp    // 对应的文本匹配模式，用于无法按 JSON 解析的内容
+}
+
+func (b *bodyRedactor) add(keys ...string) {
+    if b.keys == nil {
+        b.keys = make(map[string]struct{})
+    }
+    for _, k := range keys {
+        k = strings.ToLower(k)
+        if _, ok := b.keys[k]; ok {
+            continue
+        }
+        b.keys[k] = struct{}{}
+        b.patterns = append(b.patterns,
+            regexp.MustCompile(`(?i)("`+regexp.QuoteMeta(k)+`"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`))
+    }
+}
+
+// redact 完整的 JSON 按结构脱敏；被截断或非 JSON 的内容按 "key": value 文本模式尽力脱敏
+func (b *bodyRedactor) redact(body []byte) string {
+    if len(b.keys) == 0 || len(body) == 0 {
+        return string(body)
+    }
+    var v any
+    dec := json.NewDecoder(bytes.NewReader(body))
+    dec.UseNumber()
+    if err := dec.Decode(&v); err == nil && !dec.More() {
+        if out, err := json.Marshal(redactValue(v, b.keys)); err == nil {
+            return string(out)
+        }
+    }
+    s := string(body)
+    for _, re := range b.patterns {
+        s = re.ReplaceAllString(s, `${1}"`+RedactedValue+`"`)
+    }
+    return s
+}
+
+// redactValue 递归脱敏 JSON 对象中的指定键
+func redactValue(v any, keys map[string]struct{}) any {
+    switch v := v.(type) {
+    case map[string]any:
+        for k, child := range v {
+            if _, ok := keys[strings.ToLower(k)]; ok {
+                v[k] = RedactedValue
+                continue
+            }
+            v[k] = redactValue(child, keys)
+        }
+    case []any:
+        for i, child := range v {
+            v[i] = redactValue(child, keys)
+        }
+    }
+    return v
+}
+
+// redactHeaders 返回脱敏后的请求头副本
+func redactHeaders(h http.Header, redacted []string) map[string]string {
+    out := make(map[string]string, len(h))
+    for k, v := range h {
+        out[k] = strings.Join(v, ", ")
+    }
+    for _, k := range redacted {
+        k = http.CanonicalHeaderKey(k)
+        if _, ok := out[k]; ok {
+            out[k] = RedactedValue
+        }
+    }
+    return out
+}
+
+// responseRecorder 记录响应状态码与字节数，limit > 0 时同时保存前 limit 字节的响应体
+type responseRecorder struct {
+    http.ResponseWriter
+    status      int
+    wroteHeader bool
+    written     int64
+
+    limit     int
+    body      bytes.Buffer
+    truncated bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+    if !r.wroteHeader {
+        r.status = status
+        r.wroteHeader = true
+    }
+    r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+    r.wroteHeader = true
+    if r.limit > 0 {
+        if room := r.limit - r.body.Len(); room >= len(p) {
+            r.body.Write(p)
+        } else {
+            r.body.Write(p[:max(room, 0)])
+            r.truncated = true
+        }
+    }
+    n, err := r.ResponseWriter.Write(p)
+    r.written += int64(n)
+    return n, err
+}
+
+// Unwrap 供 http.ResponseController 访问底层 ResponseWriter
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+    return r.ResponseWriter
+}
+
+// Flush 实现 http.Flusher，底层 ResponseWriter 不支持时忽略，便于流式响应 (如 SSE) 直接断言 http.Flusher
+func (r *responseRecorder) Flush() {
+    r.wroteHeader = true
+    _ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack 实现 http.Hijacker，底层 ResponseWriter 不支持时返回 http.ErrNotSupported
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    return http.NewResponseController(r.ResponseWriter).Hijack()
+}
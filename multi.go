@@ -0,0 +1,287 @@
+package log
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// MultiLogger 将每次调用分发给多个独立的 Logger，每个 Logger 拥有各自的输出、格式与 Hook
+type MultiLogger struct {
+    loggers []Logger
+}
+
+// NewMultiLogger 创建一个分发到 loggers 的 Logger。
+// Fatalf/FatalContextf 先把 Fatal 日志同步写入所有 Logger 并写出各自的缓冲区，再以第一个 *LogrusLogger 的配置退出一次；
+// 其他实现的 Logger 收到同样内容的 Error 日志，没有 *LogrusLogger 时 (包括空的 MultiLogger) 以 os.Exit(1) 退出。
+func NewMultiLogger(loggers ...Logger) Logger {
+    return &MultiLogger{loggers: loggers}
+}
+
+func (m *MultiLogger) Debugf(format string, args ...any) {
+    for _, l := range m.loggers {
+        l.Debugf(format, args...)
+    }
+}
+
+func (m *MultiLogger) Infof(format string, args ...any) {
+    for _, l := range m.loggers {
+        l.Infof(format, args...)
+    }
+}
+
+func (m *MultiLogger) Warnf(format string, args ...any) {
+    for _, l := range m.loggers {
+        l.Warnf(format, args...)
+    }
+}
+
+func (m *MultiLogger) Errorf(format string, args ...any) {
+    for _, l := range m.loggers {
+        l.Errorf(format, args...)
+    }
+}
+
+func (m *MultiLogger) Fatalf(format string, args ...any) {
+    m.writeFatal(context.Background(), time.Time{}, format, args)
+    m.finishFatal()
+}
+
+func (m *MultiLogger) DebugContextf(ctx context.Context, format string, args ...any) {
+    for _, l := range m.loggers {
+        l.DebugContextf(ctx, format, args...)
+    }
+}
+
+func (m *MultiLogger) InfoContextf(ctx context.Context, format string, args ...any) {
+    for _, l := range m.loggers {
+        l.InfoContextf(ctx, format, args...)
+    }
+}
+
+func (m *MultiLogger) WarnContextf(ctx context.Context, format string, args ...any) {
+    for _, l := range m.loggers {
+        l.WarnContextf(ctx, format, args...)
+    }
+}
+
+func (m *MultiLogger) ErrorContextf(ctx context.Context, format string, args ...any) {
+    for _, l := range m.loggers {
+        l.ErrorContextf(ctx, format, args...)
+    }
+}
+
+func (m *MultiLogger) FatalContextf(ctx context.Context, format string, args ...any) {
+    m.writeFatal(ctx, time.Time{}, format, args)
+    m.finishFatal()
+}
+
+// finishFatal 在 Fatal 日志写入所有 Logger 后调用: 写出全部缓冲区后退出一次
+func (m *MultiLogger) finishFatal() {
+    _ = m.Sync()
+    m.exitFatal()
+}
+
+// writeFatal 把 Fatal 日志写入所有 Logger 但不退出。不支持两步处理的 Logger 的 FatalContextf 会直接退出，
+// 使后面的 Logger 收不到日志，因此改为写入同样内容的 Error 日志
+func (m *MultiLogger) writeFatal(ctx context.Context, t time.Time, format string, args []any) {
+    for _, l := range m.loggers {
+        if fl, ok := l.(fatalLogger); ok {
+            fl.writeFatal(ctx, t, format, args)
+        } else {
+            l.ErrorContextf(ctx, format, args...)
+        }
+    }
+}
+
+// exitFatal 按第一个支持两步处理的 Logger 的配置退出，没有这样的 Logger 时以 os.Exit(1) 退出
+func (m *MultiLogger) exitFatal() {
+    if fl := m.firstFatalLogger(); fl != nil {
+        fl.exitFatal()
+        return
+    }
+    os.Exit(1)
+}
+
+// firstFatalLogger 返回第一个支持两步处理的 Logger，嵌套的 MultiLogger 只在其中含有这样的 Logger 时计入
+func (m *MultiLogger) firstFatalLogger() fatalLogger {
+    for _, l := range m.loggers {
+        if nested, ok := l.(*MultiLogger); ok {
+            if fl := nested.firstFatalLogger(); fl != nil {
+                return fl
+            }
+            continue
+        }
+        if fl, ok := l.(fatalLogger); ok {
+            return fl
+        }
+    }
+    return nil
+}
+
+// ErrorfKeyed 在所有 Logger 上按去重键输出，不支持去重的 Logger 带上去重键以普通 Error 日志输出
+func (m *MultiLogger) ErrorfKeyed(key string, format string, args ...any) {
+    for _, l := range m.loggers {
+        if kl, ok := l.(interface{ ErrorfKeyed(string, string, ...any) }); ok {
+            kl.ErrorfKeyed(key, format, args...)
+        } else {
+            withFields(l, map[string]any{DedupKeyFieldKey: key}).Errorf(format, args...)
+        }
+    }
+}
+
+func (m *MultiLogger) WithField(key string, value any) Logger {
+    return m.WithFields(map[string]any{key: value})
+}
+
+// WithFields 在每个 Logger 上绑定字段，未实现 FieldLogger 的 Logger 保持不变
+func (m *MultiLogger) WithFields(fields map[string]any) Logger {
+    return m.each(func(l Logger) Logger { return withFields(l, fields) })
+}
+
+func (m *MultiLogger) each(fn func(Logger) Logger) Logger {
+    loggers := make([]Logger, len(m.loggers))
+    for i, l := range m.loggers {
+        loggers[i] = fn(l)
+    }
+    return &MultiLogger{loggers: loggers}
+}
+
+func (m *MultiLogger) SetLevel(level logrus.Level) {
+    for _, l := range m.loggers {
+        l.SetLevel(level)
+    }
+}
+
+func (m *MultiLogger) SetOutput(output io.Writer) {
+    for _, l := range m.loggers {
+        l.SetOutput(output)
+    }
+}
+
+func (m *MultiLogger) SetFormatter(format LogFormat) {
+    for _, l := range m.loggers {
+        l.SetFormatter(format)
+    }
+}
+
+func (m *MultiLogger) SetFormatterObject(f logrus.Formatter) {
+    for _, l := range m.loggers {
+        l.SetFormatterObject(f)
+    }
+}
+
+// GetConfig 返回第一个实现了 ConfigProvider 的 Logger 的配置
+func (m *MultiLogger) GetConfig() Config {
+    for _, l := range m.loggers {
+        if cp, ok := l.(ConfigProvider); ok {
+            return cp.GetConfig()
+        }
+    }
+    return Config{}
+}
+
+// DescribeConfig 依次输出每个 Logger 的描述，不支持描述的 Logger 只输出其类型
+func (m *MultiLogger) DescribeConfig() string {
+    var b strings.Builder
+    for i, l := range m.loggers {
+        fmt.Fprintf(&b, "logger[%d]:\n", i)
+        desc := fmt.Sprintf("%T\n", l)
+        if d, ok := l.(interface{ DescribeConfig() string }); ok {
+            desc = d.DescribeConfig()
+        }
+        for _, line := range strings.SplitAfter(strings.TrimSuffix(desc, "\n"), "\n") {
+            b.WriteString("  " + line)
+        }
+        b.WriteString("\n")
+    }
+    return b.String()
+}
+
+// IsTerminal 仅当所有 Logger 都写入终端时返回 true
+func (m *MultiLogger) IsTerminal() bool {
+    for _, l := range m.loggers {
+        if !l.IsTerminal() {
+            return false
+        }
+    }
+    return len(m.loggers) > 0
+}
+
+// LastError 汇总返回所有 Logger 最近一次写入的错误
+func (m *MultiLogger) LastError() error {
+    var errs []error
+    for _, l := range m.loggers {
+        if le, ok := l.(interface{ LastError() error }); ok {
+            if err := le.LastError(); err != nil {
+                errs = append(errs, err)
+            }
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// Sync 对所有实现了 Sync 的 Logger 执行 Sync，并汇总返回其中的错误
+func (m *MultiLogger) Sync() error {
+    var errs []error
+    for _, l := range m.loggers {
+        if s, ok := l.(interface{ Sync() error }); ok {
+            if err := s.Sync(); err != nil {
+                errs = append(errs, err)
+            }
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// HealthCheck 检查所有实现了 HealthChecker 的 Logger，并汇总返回其中的错误
+func (m *MultiLogger) HealthCheck() error {
+    var errs []error
+    for _, l := range m.loggers {
+        if hc, ok := l.(HealthChecker); ok {
+            if err := hc.HealthCheck(); err != nil {
+                errs = append(errs, err)
+            }
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// CloseWithTimeout 在共同的期限 d 内依次关闭所有 Logger，并汇总返回其中的错误，
+// 期限耗尽后剩余的 Logger 同样以 ErrCloseTimeout 结束
+func (m *MultiLogger) CloseWithTimeout(d time.Duration) error {
+    deadline := time.Now().Add(d)
+    var errs []error
+    for _, l := range m.loggers {
+        var err error
+        switch c := l.(type) {
+        case interface{ CloseWithTimeout(time.Duration) error }:
+            err = c.CloseWithTimeout(max(time.Until(deadline), 0))
+        case io.Closer:
+            err = c.Close()
+        }
+        if err != nil {
+            errs = append(errs, err)
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// Close 关闭所有实现了 io.Closer 的 Logger，并汇总返回其中的错误
+func (m *MultiLogger) Close() error {
+    var errs []error
+    for _, l := range m.loggers {
+        if c, ok := l.(io.Closer); ok {
+            if err := c.Close(); err != nil {
+                errs = append(errs, err)
+            }
+        }
+    }
+    return errors.Join(errs...)
+}
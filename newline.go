@@ -0,0 +1,64 @@
+package log
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "sync"
+)
+
+// TrailingNewline 定义每条日志末尾换行符的处理策略
+type TrailingNewline string
+
+const (
+    TrailingNewlineAuto   TrailingNewline = "auto"   // 保持 Formatter 的输出不变 (内置格式均以一个换行符结尾)
+    TrailingNewlineAlways TrailingNewline = "always" // 保证以且仅以一个 "\n" 结尾，缺失时补上，多余的去掉
+    TrailingNewlineNever  TrailingNewline = "never"  // 去掉末尾的换行符，适用于自行分帧的输出目标
+)
+
+// validate 校验换行策略，空值等同于 auto
+func (p TrailingNewline) validate() error {
+    switch p {
+    case "", TrailingNewlineAuto, TrailingNewlineAlways, TrailingNewlineNever:
+        return nil
+    }
+    return fmt.Errorf("invalid trailing newline policy %q", p)
+}
+
+// newlineWriter 按 TrailingNewline 策略调整每次写入的末尾换行符。
+// logrus 每条日志只调用一次 Write，因此按单次写入处理即可。
+type newlineWriter struct {
+    mu     sync.Mutex
+    w      io.Writer
+    policy TrailingNewline
+}
+
+// newNewlineWriter 包装 w，策略为 auto 时返回 nil 表示无需包装
+func newNewlineWriter(w io.Writer, policy TrailingNewline) *newlineWriter {
+    if policy == "" || policy == TrailingNewlineAuto {
+        return nil
+    }
+    return &newlineWriter{w: w, policy: policy}
+}
+
+// Write 实现 io.Writer，成功时返回 len(p) 而非实际写出的字节数
+func (nw *newlineWriter) Write(p []byte) (int, error) {
+    line := bytes.TrimRight(p, "\r\n")
+    if nw.policy == TrailingNewlineAlways {
+        line = append(line[:len(line):len(line)], '\n')
+    }
+
+    nw.mu.Lock()
+    w := nw.w
+    nw.mu.Unlock()
+    if _, err := w.Write(line); err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
+
+func (nw *newlineWriter) setWriter(w io.Writer) {
+    nw.mu.Lock()
+    defer nw.mu.Unlock()
+    nw.w = w
+}
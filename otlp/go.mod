@@ -0,0 +1,25 @@
+module github.com/sapaude/go-shims/x/log/otlp
+
+go 1.24.1
+
+require (
+	github.com/sapaude/go-shims/x/log v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+	go.opentelemetry.io/otel/log v0.16.0
+	go.opentelemetry.io/otel/sdk/log v0.16.0
+	go.opentelemetry.io/otel/trace v1.40.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel v1.40.0 // indirect
+	go.opentelemetry.io/otel/metric v1.40.0 // indirect
+	go.opentelemetry.io/otel/sdk v1.40.0 // indirect
+	golang.org/x/sys v0.40.0 // indirect
+)
+
+replace github.com/sapaude/go-shims/x/log => ../
@@ -0,0 +1,165 @@
+// Package otlp 提供将日志导出为 OpenTelemetry LogRecord 的 Hook。
+// 该包是独立的 Go 模块，只有引入它的程序才会依赖并链接 OpenTelemetry SDK。
+package otlp
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/sirupsen/logrus"
+    otellog "go.opentelemetry.io/otel/log"
+    sdklog "go.opentelemetry.io/otel/sdk/log"
+    "go.opentelemetry.io/otel/trace"
+
+    "github.com/sapaude/go-shims/x/log"
+)
+
+// InstrumentationName 是导出日志时使用的 instrumentation scope 名称
+const InstrumentationName = "github.com/sapaude/go-shims/x/log"
+
+// Hook 是一个 Logrus Hook，将每条日志转换为 OTel LogRecord 并通过 exporter 导出
+type Hook struct {
+    provider *sdklog.LoggerProvider
+    logger   otellog.Logger
+}
+
+// NewOTLPHook 创建一个通过 exporter 批量导出日志的 Hook，例如 otlploggrpc/otlploghttp 的 Exporter。
+// opts 会追加到 LoggerProvider 的选项中，可用于设置 Resource 等。
+func NewOTLPHook(exporter sdklog.Exporter, opts ...sdklog.LoggerProviderOption) *Hook {
+    opts = append([]sdklog.LoggerProviderOption{
+        sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+    }, opts...)
+    provider := sdklog.NewLoggerProvider(opts...)
+    return &Hook{
+        provider: provider,
+        logger:   provider.Logger(InstrumentationName),
+    }
+}
+
+// Levels 返回 Hook 应该触发的日志级别
+func (hook *Hook) Levels() []logrus.Level {
+    return logrus.AllLevels
+}
+
+// Fire 将日志条目转换为 LogRecord 并提交给 OTel SDK
+func (hook *Hook) Fire(entry *logrus.Entry) error {
+    var record otellog.Record
+    record.SetTimestamp(entry.Time)
+    record.SetObservedTimestamp(time.Now())
+    record.SetSeverity(severity(entry.Level))
+    record.SetSeverityText(entry.Level.String())
+    record.SetBody(otellog.StringValue(entry.Message))
+
+    attrs := make([]otellog.KeyValue, 0, len(entry.Data))
+    for k, v := range entry.Data {
+        attrs = append(attrs, otellog.KeyValue{Key: k, Value: attrValue(v)})
+    }
+    record.AddAttributes(attrs...)
+
+    hook.logger.Emit(traceContext(entry), record)
+    return nil
+}
+
+// ForceFlush 立即导出所有缓存的日志
+func (hook *Hook) ForceFlush(ctx context.Context) error {
+    return hook.provider.ForceFlush(ctx)
+}
+
+// Shutdown 导出剩余日志并关闭 exporter
+func (hook *Hook) Shutdown(ctx context.Context) error {
+    return hook.provider.Shutdown(ctx)
+}
+
+// severity 将 logrus 级别映射为 OTel 日志级别
+func severity(level logrus.Level) otellog.Severity {
+    switch level {
+    case logrus.TraceLevel:
+        return otellog.SeverityTrace
+    case logrus.DebugLevel:
+        return otellog.SeverityDebug
+    case logrus.InfoLevel:
+        return otellog.SeverityInfo
+    case logrus.WarnLevel:
+        return otellog.SeverityWarn
+    case logrus.ErrorLevel:
+        return otellog.SeverityError
+    case logrus.FatalLevel:
+        return otellog.SeverityFatal
+    case logrus.PanicLevel:
+        return otellog.SeverityFatal4
+    }
+    return otellog.SeverityUndefined
+}
+
+// attrValue 将字段值转换为 OTel 属性值，无法直接表示的类型使用字符串形式
+func attrValue(v any) otellog.Value {
+    switch v := v.(type) {
+    case string:
+        return otellog.StringValue(v)
+    case bool:
+        return otellog.BoolValue(v)
+    case int:
+        return otellog.IntValue(v)
+    case int8:
+        return otellog.Int64Value(int64(v))
+    case int16:
+        return otellog.Int64Value(int64(v))
+    case int32:
+        return otellog.Int64Value(int64(v))
+    case int64:
+        return otellog.Int64Value(v)
+    case uint8:
+        return otellog.Int64Value(int64(v))
+    case uint16:
+        return otellog.Int64Value(int64(v))
+    case uint32:
+        return otellog.Int64Value(int64(v))
+    case float32:
+        return otellog.Float64Value(float64(v))
+    case float64:
+        return otellog.Float64Value(v)
+    case []byte:
+        return otellog.BytesValue(v)
+    case error:
+        return otellog.StringValue(v.Error())
+    }
+    return otellog.StringValue(fmt.Sprint(v))
+}
+
+// traceContext 返回用于关联链路的 Context。
+// 若 Context 中已有 OTel Span 则直接使用；否则尝试将日志 (或 Context 中) 的 trace_id/span_id 解析为 W3C 格式的 ID。
+func traceContext(entry *logrus.Entry) context.Context {
+    ctx := entry.Context
+    if ctx == nil {
+        ctx = context.Background()
+    }
+    if trace.SpanContextFromContext(ctx).IsValid() {
+        return ctx
+    }
+
+    traceIDStr, ok := entry.Data[string(log.TraceIDKey)].(string)
+    if !ok {
+        // 配置了 FieldPrefix 时日志字段名带有前缀，直接从 Context 中读取
+        traceIDStr, _ = log.GetTraceID(ctx)
+    }
+    spanIDStr, ok := entry.Data[string(log.SpanIDKey)].(string)
+    if !ok {
+        spanIDStr, _ = log.GetSpanID(ctx)
+    }
+    traceID, err := trace.TraceIDFromHex(traceIDStr)
+    if err != nil {
+        return ctx
+    }
+    spanID, err := trace.SpanIDFromHex(spanIDStr)
+    if err != nil {
+        return ctx
+    }
+    sc := trace.NewSpanContext(trace.SpanContextConfig{
+        TraceID:    traceID,
+        SpanID:     spanID,
+        TraceFlags: trace.FlagsSampled,
+        Remote:     true,
+    })
+    return trace.ContextWithSpanContext(ctx, sc)
+}
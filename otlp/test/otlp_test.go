@@ -0,0 +1,90 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "sync"
+    "testing"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sapaude/go-shims/x/log/otlp"
+    "github.com/sirupsen/logrus"
+    otellog "go.opentelemetry.io/otel/log"
+    sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// newJSONLogger 创建一个输出到 buf 的 JSON Logger，便于断言字段
+func newJSONLogger(t *testing.T, buf *bytes.Buffer, opts ...func(*log.Config)) *log.LogrusLogger {
+    t.Helper()
+    cfg := log.DefaultConfig()
+    cfg.Output = buf
+    cfg.Format = log.FormatJSON
+    cfg.Level = logrus.DebugLevel
+    for _, opt := range opts {
+        opt(&cfg)
+    }
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    return l.(*log.LogrusLogger)
+}
+
+// memoryExporter 在内存中保存导出的日志
+type memoryExporter struct {
+    mu      sync.Mutex
+    records []sdklog.Record
+}
+
+func (e *memoryExporter) Export(_ context.Context, records []sdklog.Record) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    for _, r := range records {
+        e.records = append(e.records, r.Clone())
+    }
+    return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+func TestOTLPHook(t *testing.T) {
+    exporter := &memoryExporter{}
+    hook := otlp.NewOTLPHook(exporter)
+
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+    l.AddHook(hook)
+
+    ctx := log.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+    ctx = log.WithSpanID(ctx, "00f067aa0ba902b7")
+    l.WithField("attempt", 3).ErrorContextf(ctx, "payment failed")
+
+    if err := hook.ForceFlush(context.Background()); err != nil {
+        t.Fatalf("ForceFlush: %v", err)
+    }
+    if len(exporter.records) != 1 {
+        t.Fatalf("exported %d records, want 1", len(exporter.records))
+    }
+    r := exporter.records[0]
+    if r.Severity() != otellog.SeverityError || r.SeverityText() != "error" {
+        t.Errorf("severity = %v %q", r.Severity(), r.SeverityText())
+    }
+    if r.Body().AsString() != "payment failed" {
+        t.Errorf("body = %q", r.Body().AsString())
+    }
+    attrs := map[string]otellog.Value{}
+    r.WalkAttributes(func(kv otellog.KeyValue) bool {
+        attrs[kv.Key] = kv.Value
+        return true
+    })
+    if attrs["attempt"].AsInt64() != 3 {
+        t.Errorf("attempt attribute = %v", attrs["attempt"])
+    }
+    if r.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || r.SpanID().String() != "00f067aa0ba902b7" {
+        t.Errorf("trace correlation = %s/%s", r.TraceID(), r.SpanID())
+    }
+    if err := hook.Shutdown(context.Background()); err != nil {
+        t.Errorf("Shutdown: %v", err)
+    }
+}
@@ -0,0 +1,116 @@
+package log
+
+import (
+    "io"
+    "os"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+// levelRouter 按日志级别将输出分流到两个 Writer：
+// 达到 errLevel (含) 及以上级别的日志写入 errOut，其余写入 out。
+//
+// logrus 的 Writer 接口本身不携带级别，因此由 levelRouterFormatter 在格式化时记录当前条目的级别。
+// logrus 在同一把 Logger 锁内依次调用 Formatter.Format 与 Out.Write，二者之间不会被其他条目打断。
+type levelRouter struct {
+    mu       sync.Mutex
+    out      io.Writer
+    errOut   io.Writer
+    errLevel logrus.Level
+    level    logrus.Level // 最近一次格式化的条目级别
+}
+
+func newLevelRouter(out, errOut io.Writer, errLevel logrus.Level) *levelRouter {
+    return &levelRouter{
+        out:      out,
+        errOut:   errOut,
+        errLevel: errLevel,
+        level:    logrus.InfoLevel,
+    }
+}
+
+// Write 实现 io.Writer
+func (r *levelRouter) Write(p []byte) (int, error) {
+    r.mu.Lock()
+    w := r.out
+    if r.level <= r.errLevel {
+        w = r.errOut
+    }
+    r.mu.Unlock()
+    return w.Write(p)
+}
+
+func (r *levelRouter) setOutput(out io.Writer) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.out = out
+}
+
+func (r *levelRouter) setLevel(level logrus.Level) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.level = level
+}
+
+// levelRouterFormatter 包装实际的 Formatter，把条目级别告知 levelRouter
+type levelRouterFormatter struct {
+    logrus.Formatter
+    router *levelRouter
+}
+
+// Format 实现 logrus.Formatter
+func (f *levelRouterFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+    f.router.setLevel(entry.Level)
+    return f.Formatter.Format(entry)
+}
+
+// wrapLevelFormatter 为需要感知条目级别的输出 (分流、缓冲) 包装 Formatter，未启用时原样返回
+func wrapLevelFormatter(f logrus.Formatter, router *levelRouter, buffer *bufferedWriter) logrus.Formatter {
+    if router != nil {
+        f = &levelRouterFormatter{Formatter: f, router: router}
+    }
+    if buffer != nil {
+        f = &bufferLevelFormatter{Formatter: f, buffer: buffer}
+    }
+    return f
+}
+
+// errorOutput 返回高级别日志的输出目标，未启用分流时返回 nil
+func (c Config) errorOutput() io.Writer {
+    if c.ErrorOutput != nil {
+        return c.ErrorOutput
+    }
+    if c.SplitErrorStream {
+        return os.Stderr
+    }
+    return nil
+}
+
+// writeErrorTracker 记录最近一次写入的结果，供 LastError 查询
+type writeErrorTracker struct {
+    mu  sync.Mutex
+    w   io.Writer
+    err error
+}
+
+// Write 实现 io.Writer
+func (t *writeErrorTracker) Write(p []byte) (int, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    n, err := t.w.Write(p)
+    t.err = err
+    return n, err
+}
+
+func (t *writeErrorTracker) setWriter(w io.Writer) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.w = w
+}
+
+func (t *writeErrorTracker) lastError() error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return t.err
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// DefaultPanicThrottleWindow 是相同消息模板的 Panic 日志的默认最小输出间隔
+const DefaultPanicThrottleWindow = time.Second
+
+// newPanicLimiter 创建 Panic 日志的限流器，window 为 0 时使用 DefaultPanicThrottleWindow，小于 0 时不限流
+func newPanicLimiter(window time.Duration) *keyedLimiter {
+    switch {
+    case window < 0:
+        return nil
+    case window == 0:
+        window = DefaultPanicThrottleWindow
+    }
+    return newKeyedLimiter(window)
+}
+
+// Panicf 以 Panic 级别输出日志后 panic，panic 的值与 logrus 一致，为 *logrus.Entry。
+// 相同消息模板的 Panic 日志在 Config.PanicThrottleWindow 内只输出一次，避免被 recover 后重试的紧密循环刷屏。
+// 限流按 format 字符串而不是格式化后的消息计算: "attempt %d" 无论参数为何都共享同一限流状态，
+// 而直接传入拼接好的消息 (format 各不相同) 不会被限流。
+// 被限流的调用仍然会 panic，只是不输出日志，下一次输出时通过 suppressed_count 报告期间被限流的次数。
+func (l *LogrusLogger) Panicf(format string, args ...any) {
+    l.panicf(context.Background(), format, args...)
+}
+
+// PanicContextf 与 Panicf 相同，同时添加 Context 中的字段
+func (l *LogrusLogger) PanicContextf(ctx context.Context, format string, args ...any) {
+    l.panicf(ctx, format, args...)
+}
+
+func (l *LogrusLogger) panicf(ctx context.Context, format string, args ...any) {
+    panic(l.writePanic(ctx, format, args))
+}
+
+// panicLogger 由可以把 Panic 拆成“写出”与“panic”两步的 Logger 实现，
+// MultiLogger 借此先把 Panic 日志写入所有 Logger，最后只 panic 一次
+type panicLogger interface {
+    // writePanic 按限流规则写出一条 Panic 级别的日志但不 panic，返回应当 panic 的值，没有时返回 nil
+    writePanic(ctx context.Context, format string, args []any) any
+}
+
+func (l *LogrusLogger) writePanic(ctx context.Context, format string, args []any) (value any) {
+    msg := fmt.Sprintf(format, args...)
+    entry := l.newEntry(ctx)
+    if l.panics != nil {
+        ok, suppressed := l.panics.allow(format, time.Now())
+        if !ok {
+            entry.Level = logrus.PanicLevel
+            entry.Message = msg
+            return entry
+        }
+        if suppressed > 0 {
+            entry.Data[SuppressedCountFieldKey] = suppressed
+        }
+    }
+    // Entry.Log 在 Panic 级别写出后以新的 *logrus.Entry panic，这里取回该值
+    defer func() {
+        value = recover()
+    }()
+    entry.Log(logrus.PanicLevel, msg)
+    return nil
+}
+
+// Panicf 在所有 Logger 上输出 Panic 日志后 panic 一次，panic 的值取自第一个 *LogrusLogger，限流规则同 LogrusLogger.Panicf。
+// 其他实现的 Logger 收到同样内容的 Error 日志；没有 *LogrusLogger 时以格式化后的消息 panic
+func (m *MultiLogger) Panicf(format string, args ...any) {
+    m.panicf(context.Background(), format, args)
+}
+
+// PanicContextf 与 Panicf 相同，同时添加 Context 中的字段
+func (m *MultiLogger) PanicContextf(ctx context.Context, format string, args ...any) {
+    m.panicf(ctx, format, args)
+}
+
+func (m *MultiLogger) panicf(ctx context.Context, format string, args []any) {
+    value := m.writePanic(ctx, format, args)
+    if value == nil {
+        value = fmt.Sprintf(format, args...)
+    }
+    panic(value)
+}
+
+func (m *MultiLogger) writePanic(ctx context.Context, format string, args []any) any {
+    var value any
+    for _, l := range m.loggers {
+        if pl, ok := l.(panicLogger); ok {
+            if v := pl.writePanic(ctx, format, args); value == nil {
+                value = v
+            }
+        } else {
+            l.ErrorContextf(ctx, format, args...)
+        }
+    }
+    return value
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+    "errors"
+    "slices"
+    "sort"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+// prioritizedHook 是带优先级的 Hook
+type prioritizedHook struct {
+    hook     logrus.Hook
+    priority int
+}
+
+// hookDispatcher 是一个按优先级依次调用内部 Hook 的 logrus.Hook。
+// logrus 只按注册顺序调用 Hook，通过 AddHookWithPriority 注册的 Hook 统一由它调度。
+type hookDispatcher struct {
+    mu    sync.RWMutex
+    hooks []prioritizedHook
+}
+
+// add 按优先级插入 Hook，优先级相同的保持注册顺序
+func (d *hookDispatcher) add(hook logrus.Hook, priority int) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    d.hooks = append(d.hooks, prioritizedHook{hook: hook, priority: priority})
+    sort.SliceStable(d.hooks, func(i, j int) bool {
+        return d.hooks[i].priority < d.hooks[j].priority
+    })
+}
+
+// Levels 实现 logrus.Hook，具体级别由各内部 Hook 自行判断
+func (d *hookDispatcher) Levels() []logrus.Level {
+    return logrus.AllLevels
+}
+
+// Fire 实现 logrus.Hook。某个 Hook 返回错误不影响后续 Hook 的调用
+func (d *hookDispatcher) Fire(entry *logrus.Entry) error {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+    var errs []error
+    for _, h := range d.hooks {
+        if !slices.Contains(h.hook.Levels(), entry.Level) {
+            continue
+        }
+        if err := h.hook.Fire(entry); err != nil {
+            errs = append(errs, err)
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// AddHook 注册一个 Hook，与 DescribeConfig 读取已注册的 Hook 互斥。
+// 应通过该方法而不是直接调用内嵌 logrus.Logger 的 AddHook 注册 Hook
+func (l *LogrusLogger) AddHook(hook logrus.Hook) {
+    l.hookMu.Lock()
+    defer l.hookMu.Unlock()
+    l.Logger.AddHook(hook)
+}
+
+// AddHookWithPriority 注册一个按优先级调用的 Hook，priority 越小越先执行，相同优先级按注册顺序执行。
+// 例如脱敏 Hook 使用较小的优先级，保证在导出类 Hook 之前运行。
+// 这些 Hook 统一在 CallerHook 等内置 Hook 之后执行；由于多了一层调度，不应通过它注册依赖固定栈深度的 CallerHook。
+func (l *LogrusLogger) AddHookWithPriority(hook logrus.Hook, priority int) {
+    l.mu.Lock()
+    if l.hooks == nil {
+        l.hooks = &hookDispatcher{}
+        l.AddHook(l.hooks)
+    }
+    d := l.hooks
+    l.mu.Unlock()
+    d.add(hook, priority)
+}
@@ -0,0 +1,128 @@
+// Package prometheus 提供在记录错误日志时向 Prometheus 指标附加 trace_id exemplar 的 Hook，
+// 便于从指标跳转到对应的日志与链路。
+// 该包是独立的 Go 模块，只有引入它的程序才会依赖并链接 Prometheus 客户端。
+package prometheus
+
+import (
+    "fmt"
+    "unicode/utf8"
+
+    prom "github.com/prometheus/client_golang/prometheus"
+    "github.com/sirupsen/logrus"
+
+    "github.com/sapaude/go-shims/x/log"
+)
+
+// ExemplarLabel 是 exemplar 中存放链路追踪 ID 的标签名
+const ExemplarLabel = "trace_id"
+
+// Option 定义 Hook 的可选配置
+type Option func(*Hook)
+
+// WithLevels 指定触发 Hook 的日志级别，默认为 Error 及以上
+func WithLevels(levels ...logrus.Level) Option {
+    return func(h *Hook) {
+        h.levels = levels
+    }
+}
+
+// Hook 是一个 Logrus Hook，在指定级别的日志出现时更新指标，
+// 并将日志的 trace_id (字段或 Context 中的值) 作为 exemplar 附加到该次更新上
+type Hook struct {
+    levels  []logrus.Level
+    counter prom.Counter
+    // observer 与 valueField 用于直方图，从日志字段中读取观测值
+    observer   prom.Observer
+    valueField string
+}
+
+// NewCounterHook 创建每条日志将 counter 加 1 的 Hook
+func NewCounterHook(counter prom.Counter, opts ...Option) *Hook {
+    return newHook(&Hook{counter: counter}, opts)
+}
+
+// NewHistogramHook 创建将日志字段 valueField 的数值记录到 observer (通常是 Histogram) 的 Hook，
+// 字段不存在或不是数值时不记录。time.Duration 字段按秒记录。
+func NewHistogramHook(observer prom.Observer, valueField string, opts ...Option) *Hook {
+    return newHook(&Hook{observer: observer, valueField: valueField}, opts)
+}
+
+func newHook(h *Hook, opts []Option) *Hook {
+    h.levels = []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+    for _, opt := range opts {
+        opt(h)
+    }
+    return h
+}
+
+// Levels 返回 Hook 应该触发的日志级别
+func (hook *Hook) Levels() []logrus.Level {
+    return hook.levels
+}
+
+// Fire 更新指标，条目带有 trace_id 时附加 exemplar
+func (hook *Hook) Fire(entry *logrus.Entry) error {
+    exemplar := exemplarLabels(entry)
+    if hook.counter != nil {
+        if adder, ok := hook.counter.(prom.ExemplarAdder); ok && exemplar != nil {
+            adder.AddWithExemplar(1, exemplar)
+        } else {
+            hook.counter.Inc()
+        }
+    }
+    if hook.observer != nil {
+        v, ok := numericValue(entry.Data[hook.valueField])
+        if !ok {
+            return nil
+        }
+        if obs, ok := hook.observer.(prom.ExemplarObserver); ok && exemplar != nil {
+            obs.ObserveWithExemplar(v, exemplar)
+        } else {
+            hook.observer.Observe(v)
+        }
+    }
+    return nil
+}
+
+// exemplarLabels 返回包含 trace_id 的 exemplar 标签，没有 trace_id 或超出 exemplar 长度限制时返回 nil
+func exemplarLabels(entry *logrus.Entry) prom.Labels {
+    var traceID string
+    if v, ok := entry.Data[string(log.TraceIDKey)]; ok {
+        traceID = fmt.Sprint(v)
+    } else if entry.Context != nil {
+        traceID, _ = log.GetTraceID(entry.Context)
+    }
+    if traceID == "" {
+        return nil
+    }
+    // 超出长度限制时 client_golang 会 panic，此处直接放弃 exemplar
+    if utf8.RuneCountInString(ExemplarLabel)+utf8.RuneCountInString(traceID) > prom.ExemplarMaxRunes {
+        return nil
+    }
+    return prom.Labels{ExemplarLabel: traceID}
+}
+
+// numericValue 将字段值转换为 float64
+func numericValue(v any) (float64, bool) {
+    switch v := v.(type) {
+    case float64:
+        return v, true
+    case float32:
+        return float64(v), true
+    case int:
+        return float64(v), true
+    case int64:
+        return float64(v), true
+    case int32:
+        return float64(v), true
+    case uint:
+        return float64(v), true
+    case uint64:
+        return float64(v), true
+    case uint32:
+        return float64(v), true
+    case interface{ Seconds() float64 }:
+        return v.Seconds(), true
+    }
+    return 0, false
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "testing"
+    "time"
+
+    prom "github.com/prometheus/client_golang/prometheus"
+    dto "github.com/prometheus/client_model/go"
+    "github.com/sirupsen/logrus"
+
+    "github.com/sapaude/go-shims/x/log"
+    promhook "github.com/sapaude/go-shims/x/log/prometheus"
+)
+
+// newJSONLogger 创建一个输出到 buf 的 JSON Logger，便于断言字段
+func newJSONLogger(t *testing.T, buf *bytes.Buffer, opts ...func(*log.Config)) *log.LogrusLogger {
+    t.Helper()
+    cfg := log.DefaultConfig()
+    cfg.Output = buf
+    cfg.Format = log.FormatJSON
+    cfg.Level = logrus.DebugLevel
+    for _, opt := range opts {
+        opt(&cfg)
+    }
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    return l.(*log.LogrusLogger)
+}
+
+// exemplarTraceID 返回 exemplar 中的 trace_id 标签
+func exemplarTraceID(e *dto.Exemplar) string {
+    for _, l := range e.GetLabel() {
+        if l.GetName() == promhook.ExemplarLabel {
+            return l.GetValue()
+        }
+    }
+    return ""
+}
+
+func TestPrometheusExemplarHook(t *testing.T) {
+    counter := prom.NewCounter(prom.CounterOpts{Name: "log_errors_total", Help: "errors"})
+    histogram := prom.NewHistogram(prom.HistogramOpts{Name: "failed_request_seconds", Help: "latency"})
+
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+    l.AddHookWithPriority(promhook.NewCounterHook(counter), 0)
+    l.AddHookWithPriority(promhook.NewHistogramHook(histogram, "latency"), 0)
+
+    ctx := log.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+    l.InfoContextf(ctx, "not counted")
+    l.WithField("latency", 250*time.Millisecond).ErrorContextf(ctx, "request failed")
+
+    var m dto.Metric
+    if err := counter.Write(&m); err != nil {
+        t.Fatal(err)
+    }
+    if m.GetCounter().GetValue() != 1 {
+        t.Errorf("counter = %v, want 1", m.GetCounter().GetValue())
+    }
+    if got := exemplarTraceID(m.GetCounter().GetExemplar()); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+        t.Errorf("counter exemplar trace_id = %q", got)
+    }
+
+    m.Reset()
+    if err := histogram.Write(&m); err != nil {
+        t.Fatal(err)
+    }
+    h := m.GetHistogram()
+    if h.GetSampleCount() != 1 || h.GetSampleSum() != 0.25 {
+        t.Errorf("histogram count/sum = %d/%v", h.GetSampleCount(), h.GetSampleSum())
+    }
+    var found bool
+    for _, b := range h.GetBucket() {
+        if exemplarTraceID(b.GetExemplar()) == "4bf92f3577b34da6a3ce929d0e0e4736" {
+            found = true
+        }
+    }
+    if !found {
+        t.Error("histogram exemplar with trace_id not found")
+    }
+
+    // 没有 trace_id 时仍然计数，但不附加 exemplar
+    l.Errorf("no trace")
+    m.Reset()
+    _ = counter.Write(&m)
+    if m.GetCounter().GetValue() != 2 {
+        t.Errorf("counter = %v, want 2", m.GetCounter().GetValue())
+    }
+
+    // 自定义级别
+    warnCounter := prom.NewCounter(prom.CounterOpts{Name: "log_warnings_total", Help: "warnings"})
+    l.AddHookWithPriority(promhook.NewCounterHook(warnCounter, promhook.WithLevels(logrus.WarnLevel)), 0)
+    l.Warnf("warn")
+    m.Reset()
+    _ = warnCounter.Write(&m)
+    if m.GetCounter().GetValue() != 1 {
+        t.Errorf("warn counter = %v, want 1", m.GetCounter().GetValue())
+    }
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+    "context"
+    "net/http"
+)
+
+const (
+    // HeaderRequestID 用于跨服务传递请求 ID 的 HTTP Header
+    HeaderRequestID = "X-Request-ID"
+    // HeaderUserID 用于跨服务传递用户 ID 的 HTTP Header
+    HeaderUserID = "X-User-ID"
+    // HeaderTraceID 用于跨服务传递链路追踪 ID 的 HTTP Header
+    HeaderTraceID = "X-Trace-ID"
+    // HeaderSpanID 用于跨服务传递 Span ID 的 HTTP Header
+    HeaderSpanID = "X-Span-ID"
+)
+
+// headerMappings 定义 Context 键与 HTTP Header 的对应关系
+var headerMappings = []struct {
+    key    contextKey
+    header string
+}{
+    {RequestIDKey, HeaderRequestID},
+    {UserIDKey, HeaderUserID},
+    {TraceIDKey, HeaderTraceID},
+    {SpanIDKey, HeaderSpanID},
+}
+
+// InjectHeaders 将 Context 中的日志关联字段写入 HTTP Header，通常用于发起下游请求前
+func InjectHeaders(ctx context.Context, h http.Header) {
+    for _, m := range headerMappings {
+        if val, ok := ctx.Value(m.key).(string); ok && val != "" {
+            h.Set(m.header, val)
+        }
+    }
+}
+
+// ExtractHeaders 从 HTTP Header 中读取日志关联字段并写入 Context，通常用于服务端接收请求时。
+// 请求 ID 通过 WithRequestID 写入 (以便 WithChildRequestID 派生的子 ID 序号从 1 开始)；
+// 客户端可以任意伪造 X-User-ID，因此不读取用户 ID，只在可信来源 (如网关设置该 Header) 时使用 ExtractUserIDHeader
+func ExtractHeaders(ctx context.Context, h http.Header) context.Context {
+    for _, m := range headerMappings {
+        val := h.Get(m.header)
+        switch {
+        case val == "" || m.key == UserIDKey:
+        case m.key == RequestIDKey:
+            ctx = WithRequestID(ctx, val)
+        default:
+            ctx = context.WithValue(ctx, m.key, val)
+        }
+    }
+    return ctx
+}
+
+// ExtractUserIDHeader 从 X-User-ID Header 中读取用户 ID 并写入 Context，
+// 仅适用于该 Header 由可信的上游 (如认证网关) 设置、客户端无法直接伪造的场景
+func ExtractUserIDHeader(ctx context.Context, h http.Header) context.Context {
+    if val := h.Get(HeaderUserID); val != "" {
+        ctx = WithUserID(ctx, val)
+    }
+    return ctx
+}
@@ -0,0 +1,13 @@
+module github.com/sapaude/go-shims/x/log/protobuf
+
+go 1.24.1
+
+require (
+	github.com/sapaude/go-shims/x/log v0.0.0
+	github.com/sirupsen/logrus v1.9.3
+	google.golang.org/protobuf v1.36.8
+)
+
+require golang.org/x/sys v0.40.0 // indirect
+
+replace github.com/sapaude/go-shims/x/log => ../
@@ -0,0 +1,171 @@
+// Package protobuf 提供将 proto.Message 类型的日志字段按 protojson 编码输出的 Hook，
+// 代替 Go 默认的结构体渲染。标记了 debug_redact 选项的字段会被替换为 log.RedactedValue。
+// 该包是独立的 Go 模块，只有引入它的程序才会依赖并链接 protobuf 运行时。
+package protobuf
+
+import (
+    "encoding/json"
+    "fmt"
+
+    "github.com/sirupsen/logrus"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/reflect/protoreflect"
+    "google.golang.org/protobuf/types/descriptorpb"
+
+    "github.com/sapaude/go-shims/x/log"
+)
+
+// JSONValue 是 protojson 编码后的字段值: JSON 格式中原样嵌入，文本格式中输出 JSON 字符串
+type JSONValue json.RawMessage
+
+// MarshalJSON 实现 json.Marshaler
+func (v JSONValue) MarshalJSON() ([]byte, error) {
+    return v, nil
+}
+
+// String 实现 fmt.Stringer
+func (v JSONValue) String() string {
+    return string(v)
+}
+
+// Option 定义 Hook 的可选配置
+type Option func(*Hook)
+
+// WithMarshalOptions 指定 protojson 的编码选项，例如 UseProtoNames、EmitUnpopulated
+func WithMarshalOptions(opts protojson.MarshalOptions) Option {
+    return func(h *Hook) {
+        h.marshal = opts
+    }
+}
+
+// Hook 是一个 Logrus Hook，将 proto.Message 类型的字段值替换为 protojson 编码的 JSONValue。
+// 编码失败时保留原值。可通过 LogrusLogger.AddHookWithPriority 注册，使其先于导出类 Hook 执行。
+type Hook struct {
+    marshal protojson.MarshalOptions
+}
+
+// NewHook 创建 protobuf 字段编码 Hook
+func NewHook(opts ...Option) *Hook {
+    h := &Hook{}
+    for _, opt := range opts {
+        opt(h)
+    }
+    return h
+}
+
+// Levels 返回 Hook 应该触发的日志级别
+func (hook *Hook) Levels() []logrus.Level {
+    return logrus.AllLevels
+}
+
+// Fire 编码条目中的 proto.Message 字段
+func (hook *Hook) Fire(entry *logrus.Entry) error {
+    for k, v := range entry.Data {
+        msg, ok := v.(proto.Message)
+        if !ok {
+            continue
+        }
+        if encoded, err := hook.encode(msg); err == nil {
+            entry.Data[k] = encoded
+        }
+    }
+    return nil
+}
+
+// encode 按 protojson 编码消息，并将 debug_redact 字段替换为 log.RedactedValue
+func (hook *Hook) encode(msg proto.Message) (JSONValue, error) {
+    data, err := hook.marshal.Marshal(msg)
+    if err != nil {
+        return nil, err
+    }
+    if !hasRedactedFields(msg.ProtoReflect().Descriptor(), map[protoreflect.FullName]bool{}) {
+        return JSONValue(data), nil
+    }
+    var obj map[string]any
+    if err := json.Unmarshal(data, &obj); err != nil {
+        return nil, err
+    }
+    hook.redact(msg.ProtoReflect(), obj)
+    out, err := json.Marshal(obj)
+    if err != nil {
+        return nil, fmt.Errorf("protobuf: re-encode redacted message: %w", err)
+    }
+    return JSONValue(out), nil
+}
+
+// redact 沿消息结构遍历 protojson 解码得到的 obj，替换 debug_redact 字段的值。
+// 特殊 JSON 表示的类型 (如 Timestamp、Struct) 解码后不是对象，会被跳过
+func (hook *Hook) redact(m protoreflect.Message, obj map[string]any) {
+    m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+        name := fd.JSONName()
+        if hook.marshal.UseProtoNames {
+            name = string(fd.Name())
+        }
+        if _, ok := obj[name]; !ok {
+            return true
+        }
+        if isRedacted(fd) {
+            obj[name] = log.RedactedValue
+            return true
+        }
+        if fd.Message() == nil {
+            return true
+        }
+        switch {
+        case fd.IsList():
+            items, _ := obj[name].([]any)
+            list := v.List()
+            for i := 0; i < list.Len() && i < len(items); i++ {
+                if child, ok := items[i].(map[string]any); ok {
+                    hook.redact(list.Get(i).Message(), child)
+                }
+            }
+        case fd.IsMap():
+            if fd.MapValue().Message() == nil {
+                return true
+            }
+            entries, _ := obj[name].(map[string]any)
+            v.Map().Range(func(key protoreflect.MapKey, val protoreflect.Value) bool {
+                if child, ok := entries[key.String()].(map[string]any); ok {
+                    hook.redact(val.Message(), child)
+                }
+                return true
+            })
+        default:
+            if child, ok := obj[name].(map[string]any); ok {
+                hook.redact(v.Message(), child)
+            }
+        }
+        return true
+    })
+}
+
+// isRedacted 判断字段是否设置了 debug_redact 选项
+func isRedacted(fd protoreflect.FieldDescriptor) bool {
+    opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+    return ok && opts.GetDebugRedact()
+}
+
+// hasRedactedFields 判断消息类型 (含嵌套消息) 是否包含 debug_redact 字段，无需脱敏时可直接使用 protojson 的输出
+func hasRedactedFields(md protoreflect.MessageDescriptor, seen map[protoreflect.FullName]bool) bool {
+    if seen[md.FullName()] {
+        return false
+    }
+    seen[md.FullName()] = true
+    fields := md.Fields()
+    for i := 0; i < fields.Len(); i++ {
+        fd := fields.Get(i)
+        if isRedacted(fd) {
+            return true
+        }
+        child := fd.Message()
+        if fd.IsMap() {
+            child = fd.MapValue().Message()
+        }
+        if child != nil && hasRedactedFields(child, seen) {
+            return true
+        }
+    }
+    return false
+}
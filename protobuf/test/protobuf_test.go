@@ -0,0 +1,129 @@
+package test
+
+import (
+    "bytes"
+    "encoding/json"
+    "reflect"
+    "strings"
+    "testing"
+
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/reflect/protodesc"
+    "google.golang.org/protobuf/reflect/protoreflect"
+    "google.golang.org/protobuf/types/descriptorpb"
+    "google.golang.org/protobuf/types/dynamicpb"
+    "google.golang.org/protobuf/types/known/structpb"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sapaude/go-shims/x/log/protobuf"
+    "github.com/sirupsen/logrus"
+)
+
+// newJSONLogger 创建一个输出到 buf 的 JSON Logger，便于断言字段
+func newJSONLogger(t *testing.T, buf *bytes.Buffer, opts ...func(*log.Config)) *log.LogrusLogger {
+    t.Helper()
+    cfg := log.DefaultConfig()
+    cfg.Output = buf
+    cfg.Format = log.FormatJSON
+    cfg.Level = logrus.DebugLevel
+    for _, opt := range opts {
+        opt(&cfg)
+    }
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    return l.(*log.LogrusLogger)
+}
+
+// decodeLine 解析 buf 中的单行 JSON 日志并清空 buf
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+    t.Helper()
+    var m map[string]any
+    if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+        t.Fatalf("unmarshal %q: %v", buf.String(), err)
+    }
+    buf.Reset()
+    return m
+}
+
+// newUserMessage 构建一个动态消息类型 User { string name = 1; string password = 2 [debug_redact = true]; Address address = 3; }
+func newUserMessage(t *testing.T) protoreflect.MessageDescriptor {
+    t.Helper()
+    str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+    msg := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+    optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+    fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+        Name:    proto.String("user.proto"),
+        Package: proto.String("test"),
+        Syntax:  proto.String("proto3"),
+        MessageType: []*descriptorpb.DescriptorProto{
+            {
+                Name: proto.String("Address"),
+                Field: []*descriptorpb.FieldDescriptorProto{
+                    {Name: proto.String("city"), JsonName: proto.String("city"), Number: proto.Int32(1), Type: str, Label: optional},
+                    {Name: proto.String("street_line"), JsonName: proto.String("streetLine"), Number: proto.Int32(2), Type: str, Label: optional,
+                        Options: &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)}},
+                },
+            },
+            {
+                Name: proto.String("User"),
+                Field: []*descriptorpb.FieldDescriptorProto{
+                    {Name: proto.String("user_name"), JsonName: proto.String("userName"), Number: proto.Int32(1), Type: str, Label: optional},
+                    {Name: proto.String("password"), JsonName: proto.String("password"), Number: proto.Int32(2), Type: str, Label: optional,
+                        Options: &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)}},
+                    {Name: proto.String("address"), JsonName: proto.String("address"), Number: proto.Int32(3), Type: msg, Label: optional,
+                        TypeName: proto.String(".test.Address")},
+                },
+            },
+        },
+    }, nil)
+    if err != nil {
+        t.Fatalf("build descriptor: %v", err)
+    }
+    return fd.Messages().ByName("User")
+}
+
+func TestProtobufHook(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+    l.AddHookWithPriority(protobuf.NewHook(), 0)
+
+    // 不含脱敏字段的消息与 protojson 输出一致
+    payload, err := structpb.NewStruct(map[string]any{"id": 7, "tags": []any{"a", "b"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    l.WithField("payload", payload).Infof("proto field")
+    m := decodeLine(t, &buf)
+    want, _ := protojson.Marshal(payload)
+    var wantValue any
+    json.Unmarshal(want, &wantValue)
+    if !reflect.DeepEqual(m["payload"], wantValue) {
+        t.Errorf("payload = %v, want protojson %s", m["payload"], want)
+    }
+
+    // debug_redact 字段 (含嵌套消息中的) 被脱敏
+    md := newUserMessage(t)
+    user := dynamicpb.NewMessage(md)
+    user.Set(md.Fields().ByName("user_name"), protoreflect.ValueOfString("bob"))
+    user.Set(md.Fields().ByName("password"), protoreflect.ValueOfString("hunter2"))
+    addr := dynamicpb.NewMessage(md.Fields().ByName("address").Message())
+    addr.Set(addr.Descriptor().Fields().ByName("city"), protoreflect.ValueOfString("Paris"))
+    addr.Set(addr.Descriptor().Fields().ByName("street_line"), protoreflect.ValueOfString("1 Rue X"))
+    user.Set(md.Fields().ByName("address"), protoreflect.ValueOfMessage(addr))
+
+    l.WithField("user", user).Infof("redacted proto")
+    line := buf.String()
+    m = decodeLine(t, &buf)
+    got, _ := m["user"].(map[string]any)
+    address, _ := got["address"].(map[string]any)
+    if got["userName"] != "bob" || got["password"] != log.RedactedValue ||
+        address["city"] != "Paris" || address["streetLine"] != log.RedactedValue {
+        t.Errorf("user = %v", m["user"])
+    }
+    if strings.Contains(line, "hunter2") || strings.Contains(line, "Rue X") {
+        t.Errorf("secret leaked: %s", line)
+    }
+}
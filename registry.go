@@ -0,0 +1,39 @@
+package log
+
+import (
+    "fmt"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+// FormatterFactory 根据配置创建 Formatter
+type FormatterFactory func(cfg Config) logrus.Formatter
+
+var (
+    formatterRegistry   = map[LogFormat]FormatterFactory{}
+    formatterRegistryMu sync.RWMutex
+)
+
+// RegisterFormatter 注册一个自定义格式，之后可以通过 Config.Format (或 SetFormatter) 按名称选用。
+// 自定义格式同样会经过字段白名单、命名风格转换等条目变换。
+// 重复注册同一名称时以最后一次为准；name 为内置的 text/json/custom 或 factory 为 nil 时 panic。
+func RegisterFormatter(name LogFormat, factory FormatterFactory) {
+    if name == FormatText || name == FormatJSON || name == FormatCustom {
+        panic(fmt.Sprintf("log: cannot register built-in format %q", name))
+    }
+    if factory == nil {
+        panic("log: RegisterFormatter factory is nil")
+    }
+    formatterRegistryMu.Lock()
+    defer formatterRegistryMu.Unlock()
+    formatterRegistry[name] = factory
+}
+
+// registeredFormatter 返回 name 对应的自定义格式工厂
+func registeredFormatter(name LogFormat) (FormatterFactory, bool) {
+    formatterRegistryMu.RLock()
+    defer formatterRegistryMu.RUnlock()
+    factory, ok := formatterRegistry[name]
+    return factory, ok
+}
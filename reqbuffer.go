@@ -0,0 +1,116 @@
+package log
+
+import (
+    "context"
+    "fmt"
+    "runtime"
+    "sync"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+const (
+    // MaxRequestBufferEntries 是单个请求缓冲最多暂存的条目数，超出后新的条目被丢弃
+    MaxRequestBufferEntries = 1000
+    // SlowRequestLevel 是慢请求输出暂存日志时提升到的级别
+    SlowRequestLevel = logrus.WarnLevel
+    // OriginalLevelFieldKey 记录被提升级别的日志原本的级别
+    OriginalLevelFieldKey = "original_level"
+)
+
+const (
+    requestBufferKey contextKey = "request_buffer"
+    callerFrameKey   contextKey = "caller_frame"
+)
+
+// requestBuffer 暂存一个请求中 Info 及以下级别的日志
+type requestBuffer struct {
+    mu      sync.Mutex
+    start   time.Time // 开启缓冲的时间，用于判断慢请求
+    records []bufferedRecord
+}
+
+// bufferedRecord 是一条暂存的日志，消息已格式化，条目的 Context 中记录了调用者栈帧
+type bufferedRecord struct {
+    entry *logrus.Entry
+    level logrus.Level
+    msg   string
+}
+
+// BeginRequestBuffer 为请求开启日志缓冲 (尾部采样): 之后通过该 Context 记录的 Debug/Info 日志
+// (...Contextf 方法) 不会立即输出，而是暂存到 FlushIfError 时再决定输出或丢弃，Warn 及以上级别不受影响。
+// 条目仍按 Logger 级别过滤，时间戳与调用者信息在记录时确定。
+func BeginRequestBuffer(ctx context.Context) context.Context {
+    return context.WithValue(ctx, requestBufferKey, &requestBuffer{start: time.Now()})
+}
+
+// FlushIfError 结束请求缓冲: err 不为 nil 时按原顺序输出暂存的日志，否则全部丢弃。
+// 之后通过该 Context 记录的日志会重新开始暂存
+func FlushIfError(ctx context.Context, err error) {
+    FlushIfErrorOrSlow(ctx, err, 0)
+}
+
+// FlushIfErrorOrSlow 在 FlushIfError 的基础上结合请求耗时决定是否输出:
+// 请求成功但自 BeginRequestBuffer 起的耗时达到 slowThreshold (> 0) 时，暂存的日志提升到 SlowRequestLevel 输出，
+// 并附带原始级别 (OriginalLevelFieldKey) 与请求耗时 (ElapsedFieldKey) 字段
+func FlushIfErrorOrSlow(ctx context.Context, err error, slowThreshold time.Duration) {
+    rb, ok := ctx.Value(requestBufferKey).(*requestBuffer)
+    if !ok {
+        return
+    }
+    rb.mu.Lock()
+    records := rb.records
+    rb.records = nil
+    elapsed := time.Since(rb.start)
+    rb.mu.Unlock()
+
+    switch {
+    case err != nil:
+        for _, rec := range records {
+            rec.entry.Log(rec.level, rec.msg)
+        }
+    case slowThreshold > 0 && elapsed >= slowThreshold:
+        for _, rec := range records {
+            rec.entry.Data[OriginalLevelFieldKey] = rec.level.String()
+            rec.entry.Data[ElapsedFieldKey] = elapsed
+            rec.entry.Log(min(rec.level, SlowRequestLevel), rec.msg)
+        }
+    }
+}
+
+// bufferRequestEntry 在 Context 开启了请求缓冲时暂存条目并返回 true
+func (l *LogrusLogger) bufferRequestEntry(entry *logrus.Entry, level logrus.Level, format string, args []any) bool {
+    if entry.Context == nil {
+        return false
+    }
+    rb, ok := entry.Context.Value(requestBufferKey).(*requestBuffer)
+    if !ok {
+        return false
+    }
+    if entry.Time.IsZero() {
+        entry.Time = time.Now()
+    }
+    if l.config.ReportCaller {
+        hook := CallerHook{SkipPackages: l.config.CallerSkipPackages}
+        if frame, ok := hook.findCaller(); ok {
+            entry.Context = context.WithValue(entry.Context, callerFrameKey, frame)
+        }
+    }
+
+    rb.mu.Lock()
+    defer rb.mu.Unlock()
+    if len(rb.records) < MaxRequestBufferEntries {
+        rb.records = append(rb.records, bufferedRecord{entry: entry, level: level, msg: fmt.Sprintf(format, args...)})
+    }
+    return true
+}
+
+// capturedCaller 返回条目暂存时记录的调用者栈帧
+func capturedCaller(entry *logrus.Entry) (runtime.Frame, bool) {
+    if entry.Context == nil {
+        return runtime.Frame{}, false
+    }
+    frame, ok := entry.Context.Value(callerFrameKey).(runtime.Frame)
+    return frame, ok
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+    "context"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+// requestSamplerKey 用于在 Context 中存储请求级采样器
+const requestSamplerKey contextKey = "request_sampler"
+
+// requestSampler 记录一个请求内每种日志 (级别 + 格式模板) 已输出的次数，随请求 Context 创建，请求结束后一起丢弃
+type requestSampler struct {
+    mu         sync.Mutex
+    limit      int
+    counts     map[requestSampleKey]int
+    suppressed int
+}
+
+type requestSampleKey struct {
+    level  logrus.Level
+    format string
+}
+
+// WithRequestSampler 返回带有请求级采样器的 Context: 同一请求内，相同级别且相同格式模板的日志最多输出 limit 条，
+// 超出的部分被丢弃，例如重试循环中反复出现的同一条警告。每次调用都创建新的计数，通常在请求入口处调用，
+// 因此不同请求之间互不影响。Error 及以上级别的日志不受限制；limit <= 0 时返回原 Context。
+func WithRequestSampler(ctx context.Context, limit int) context.Context {
+    if limit <= 0 {
+        return ctx
+    }
+    return context.WithValue(ctx, requestSamplerKey, &requestSampler{limit: limit, counts: map[requestSampleKey]int{}})
+}
+
+// RequestSampleSuppressed 返回当前请求中被请求级采样器丢弃的日志条数，Context 中没有采样器时返回 0
+func RequestSampleSuppressed(ctx context.Context) int {
+    s, ok := ctx.Value(requestSamplerKey).(*requestSampler)
+    if !ok {
+        return 0
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.suppressed
+}
+
+// allowRequestSample 判断本条日志是否在请求级采样器的限额内
+func allowRequestSample(ctx context.Context, level logrus.Level, format string) bool {
+    if ctx == nil || level <= logrus.ErrorLevel {
+        return true
+    }
+    s, ok := ctx.Value(requestSamplerKey).(*requestSampler)
+    if !ok {
+        return true
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    key := requestSampleKey{level: level, format: format}
+    if s.counts[key] >= s.limit {
+        s.suppressed++
+        return false
+    }
+    s.counts[key]++
+    return true
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "strconv"
+    "sync/atomic"
+)
+
+// childCounterKey 用于在 Context 中存储子请求 ID 的计数器
+const childCounterKey contextKey = "child_request_counter"
+
+// childCounter 记录某个请求 ID 已派生的子 ID 数量
+type childCounter struct {
+    parent string
+    n      atomic.Uint64
+}
+
+// fallbackChildCounter 在 Context 中没有与请求 ID 对应的计数器时使用 (例如直接通过 context.WithValue 设置的请求 ID)，
+// 保证子 ID 仍然唯一，但序号不再从 1 开始
+var fallbackChildCounter atomic.Uint64
+
+// WithChildRequestID 为并发子任务派生子请求 ID，例如父请求 ID 为 "req-123" 时依次得到 "req-123.1"、"req-123.2"，
+// 子 ID 可继续派生 ("req-123.1.1")。Context 中没有请求 ID 时生成新的随机 ID。
+func WithChildRequestID(ctx context.Context) context.Context {
+    parent, ok := GetRequestID(ctx)
+    if !ok || parent == "" {
+        return WithRequestID(ctx, newRequestID())
+    }
+    var seq uint64
+    if c, _ := ctx.Value(childCounterKey).(*childCounter); c != nil && c.parent == parent {
+        seq = c.n.Add(1)
+    } else {
+        seq = fallbackChildCounter.Add(1)
+    }
+    return WithRequestID(ctx, parent+"."+strconv.FormatUint(seq, 10))
+}
+
+// newRequestID 生成 32 位十六进制的随机请求 ID
+func newRequestID() string {
+    var b [16]byte
+    _, _ = rand.Read(b[:])
+    return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,86 @@
+package log
+
+import (
+    "bytes"
+    "net/http"
+    "strconv"
+    "sync"
+)
+
+// RingBuffer 是保存最近 N 行日志的内存 Writer，用于在线排查时通过 HTTP 查看最近的日志。
+// 通常通过 AddTee/AddGlobalTee 作为旁路挂到 Logger 上，不影响正常输出:
+//
+//	ring := log.NewRingBuffer(500)
+//	remove := log.AddGlobalTee(ring)
+//	defer remove()
+//	mux.Handle("/debug/logs", log.RingBufferHandler(ring))
+type RingBuffer struct {
+    mu      sync.Mutex
+    lines   []string
+    next    int          // 下一行写入的位置
+    full    bool         // 是否已写满一圈
+    partial bytes.Buffer // 尚未以换行结束的内容
+}
+
+// NewRingBuffer 创建保存最近 n 行日志的 RingBuffer，n <= 0 时按 1 处理
+func NewRingBuffer(n int) *RingBuffer {
+    return &RingBuffer{lines: make([]string, max(n, 1))}
+}
+
+// Write 实现 io.Writer，按换行拆分为行保存 (多行的美化 JSON 占多行)，不以换行结束的内容等到下次写入再补全
+func (r *RingBuffer) Write(p []byte) (int, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    rest := p
+    for {
+        i := bytes.IndexByte(rest, '\n')
+        if i < 0 {
+            r.partial.Write(rest)
+            return len(p), nil
+        }
+        r.partial.Write(rest[:i])
+        r.push(r.partial.String())
+        r.partial.Reset()
+        rest = rest[i+1:]
+    }
+}
+
+func (r *RingBuffer) push(line string) {
+    r.lines[r.next] = line
+    r.next = (r.next + 1) % len(r.lines)
+    if r.next == 0 {
+        r.full = true
+    }
+}
+
+// Dump 按写入顺序 (从旧到新) 返回保存的日志行，不含换行符
+func (r *RingBuffer) Dump() []string {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if !r.full {
+        return append([]string(nil), r.lines[:r.next]...)
+    }
+    out := make([]string, 0, len(r.lines))
+    out = append(out, r.lines[r.next:]...)
+    return append(out, r.lines[:r.next]...)
+}
+
+// RingBufferHandler 返回以 text/plain 输出 ring 中最近日志 (每行一条，从旧到新) 的 HTTP Handler，
+// 查询参数 n 可进一步限制返回最近的 n 行。日志可能包含敏感信息，应只挂在内部调试端口上
+func RingBufferHandler(ring *RingBuffer) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        lines := ring.Dump()
+        if s := r.URL.Query().Get("n"); s != "" {
+            n, err := strconv.Atoi(s)
+            if err != nil || n < 0 {
+                http.Error(w, "invalid n", http.StatusBadRequest)
+                return
+            }
+            lines = lines[max(len(lines)-n, 0):]
+        }
+        w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+        for _, line := range lines {
+            _, _ = w.Write([]byte(line + "\n"))
+        }
+    })
+}
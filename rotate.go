@@ -0,0 +1,287 @@
+package log
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "regexp"
+    "runtime"
+    "slices"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// RotationPattern 定义按时间轮转日志文件的周期
+type RotationPattern string
+
+const (
+    RotationDaily  RotationPattern = "daily"
+    RotationHourly RotationPattern = "hourly"
+)
+
+// fileSink 是 Logger 自身打开、负责关闭的文件输出 (FilePath 对应的文件或按时间轮转的文件)
+type fileSink interface {
+    io.Writer
+    Sync() error
+    Close() error
+}
+
+// rotationTokens 是 RotationFilePattern 支持的类 strftime 占位符及其宽度
+var rotationTokens = map[byte]int{
+    'Y': 4, // 年
+    'm': 2, // 月
+    'd': 2, // 日
+    'H': 2, // 时
+}
+
+// rotatingWriter 按时间周期轮转的文件 Writer: 跨过周期边界后的第一次写入时切换到新文件，
+// 并按数量与保留时长清理旧文件。文件名中的时间为周期的起始时间 (Clock 所在时区)
+type rotatingWriter struct {
+    pattern  string
+    period   RotationPattern
+    maxFiles int
+    maxAge   time.Duration
+    now      func() time.Time
+    match    *regexp.Regexp // 匹配本 Writer 生成的文件名，子匹配依次为各占位符的值
+    tokens   []byte         // match 中子匹配对应的占位符
+
+    mu    sync.Mutex
+    file  *os.File
+    start time.Time // 当前文件所属周期的起始时间
+}
+
+// newRotatingWriter 校验配置并打开当前周期的文件
+func newRotatingWriter(cfg Config) (*rotatingWriter, error) {
+    period := cfg.RotationPattern
+    if period == "" {
+        period = RotationDaily
+    }
+    if period != RotationDaily && period != RotationHourly {
+        return nil, fmt.Errorf("unknown rotation pattern %q, expected %q or %q", period, RotationDaily, RotationHourly)
+    }
+    match, tokens, err := compileRotationPattern(cfg.RotationFilePattern)
+    if err != nil {
+        return nil, err
+    }
+    if err := checkRotationTokens(cfg.RotationFilePattern, period, tokens); err != nil {
+        return nil, err
+    }
+    now := time.Now
+    if cfg.Clock != nil {
+        now = cfg.Clock
+    }
+    w := &rotatingWriter{
+        pattern:  cfg.RotationFilePattern,
+        period:   period,
+        maxFiles: cfg.RotationMaxFiles,
+        maxAge:   cfg.RotationMaxAge,
+        now:      now,
+        match:    match,
+        tokens:   tokens,
+    }
+    if err := w.rotate(w.periodStart(now())); err != nil {
+        return nil, err
+    }
+    return w, nil
+}
+
+// compileRotationPattern 将文件名模板转换为匹配已生成文件的正则表达式
+func compileRotationPattern(pattern string) (*regexp.Regexp, []byte, error) {
+    if pattern == "" {
+        return nil, nil, fmt.Errorf("empty rotation file pattern")
+    }
+    var expr strings.Builder
+    var tokens []byte
+    expr.WriteByte('^')
+    for i := 0; i < len(pattern); i++ {
+        if pattern[i] != '%' {
+            expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+            continue
+        }
+        if i+1 >= len(pattern) {
+            return nil, nil, fmt.Errorf("rotation file pattern %q ends with %%", pattern)
+        }
+        i++
+        if pattern[i] == '%' {
+            expr.WriteString("%")
+            continue
+        }
+        width, ok := rotationTokens[pattern[i]]
+        if !ok {
+            return nil, nil, fmt.Errorf("unknown token %%%c in rotation file pattern %q", pattern[i], pattern)
+        }
+        fmt.Fprintf(&expr, `(\d{%d})`, width)
+        tokens = append(tokens, pattern[i])
+    }
+    expr.WriteByte('$')
+    re, err := regexp.Compile(expr.String())
+    return re, tokens, err
+}
+
+// periodTokens 是各轮转周期的文件名模板必须包含的占位符，缺少时不同周期会写入同一个文件，
+// 保留策略还会把它当作旧文件删除
+var periodTokens = map[RotationPattern]string{
+    RotationDaily:  "Ymd",
+    RotationHourly: "YmdH",
+}
+
+// checkRotationTokens 检查文件名模板包含周期所需的全部占位符
+func checkRotationTokens(pattern string, period RotationPattern, tokens []byte) error {
+    for _, token := range []byte(periodTokens[period]) {
+        if !slices.Contains(tokens, token) {
+            return fmt.Errorf("rotation file pattern %q lacks %%%c required by %s rotation", pattern, token, period)
+        }
+    }
+    return nil
+}
+
+// filename 返回周期起始时间为 t 的文件名
+func (w *rotatingWriter) filename(t time.Time) string {
+    return strings.NewReplacer(
+        "%Y", fmt.Sprintf("%04d", t.Year()),
+        "%m", fmt.Sprintf("%02d", int(t.Month())),
+        "%d", fmt.Sprintf("%02d", t.Day()),
+        "%H", fmt.Sprintf("%02d", t.Hour()),
+        "%%", "%",
+    ).Replace(w.pattern)
+}
+
+// periodStart 返回 t 所在周期的起始时间
+func (w *rotatingWriter) periodStart(t time.Time) time.Time {
+    if w.period == RotationHourly {
+        return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+    }
+    return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Write 实现 io.Writer，跨过周期边界时先切换文件
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if start := w.periodStart(w.now()); !start.Equal(w.start) {
+        if err := w.rotate(start); err != nil {
+            return 0, err
+        }
+    }
+    return w.file.Write(p)
+}
+
+// rotate 关闭当前文件并打开 start 所在周期的文件，随后清理旧文件，调用方需持有锁 (初始化时除外)
+func (w *rotatingWriter) rotate(start time.Time) error {
+    name := w.filename(start)
+    if dir := filepath.Dir(name); dir != "." {
+        if err := os.MkdirAll(dir, 0755); err != nil {
+            return err
+        }
+    }
+    file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+    if err != nil {
+        return err
+    }
+    if w.file != nil {
+        _ = w.file.Close()
+    }
+    w.file = file
+    w.start = start
+    w.cleanup(name)
+    return nil
+}
+
+// rotatedFile 是一个已生成的轮转文件及其周期起始时间
+type rotatedFile struct {
+    name  string
+    start time.Time
+}
+
+// cleanup 按 RotationMaxFiles 与 RotationMaxAge 删除旧文件，当前文件 current 始终保留。
+// 只处理文件名与模板完全匹配的文件，清理失败不影响写入
+func (w *rotatingWriter) cleanup(current string) {
+    if w.maxFiles <= 0 && w.maxAge <= 0 {
+        return
+    }
+    candidates, _ := filepath.Glob(globPattern(w.pattern))
+    var files []rotatedFile
+    for _, name := range candidates {
+        if start, ok := w.parseStart(name); ok && name != current {
+            files = append(files, rotatedFile{name: name, start: start})
+        }
+    }
+    // 从新到旧排序
+    sort.Slice(files, func(i, j int) bool { return files[i].start.After(files[j].start) })
+    cutoff := w.start.Add(-w.maxAge)
+    for i, f := range files {
+        tooMany := w.maxFiles > 0 && i+1 >= w.maxFiles // 当前文件占用一个名额
+        tooOld := w.maxAge > 0 && f.start.Before(cutoff)
+        if tooMany || tooOld {
+            _ = os.Remove(f.name)
+        }
+    }
+}
+
+// parseStart 从文件名中解析周期起始时间
+func (w *rotatingWriter) parseStart(name string) (time.Time, bool) {
+    sub := w.match.FindStringSubmatch(name)
+    if sub == nil {
+        return time.Time{}, false
+    }
+    values := map[byte]int{'Y': 1, 'm': 1, 'd': 1}
+    for i, token := range w.tokens {
+        n, err := strconv.Atoi(sub[i+1])
+        if err != nil {
+            return time.Time{}, false
+        }
+        values[token] = n
+    }
+    return time.Date(values['Y'], time.Month(values['m']), values['d'], values['H'], 0, 0, 0, w.start.Location()), true
+}
+
+// globPattern 将文件名模板中的占位符替换为通配符，其余部分中的通配符元字符按字面量转义
+func globPattern(pattern string) string {
+    var b strings.Builder
+    for i := 0; i < len(pattern); i++ {
+        if pattern[i] == '%' && i+1 < len(pattern) {
+            i++
+            if pattern[i] == '%' {
+                b.WriteByte('%')
+            } else {
+                b.WriteByte('*')
+            }
+            continue
+        }
+        writeGlobLiteral(&b, pattern[i])
+    }
+    return b.String()
+}
+
+// writeGlobLiteral 写入按字面量匹配的字符: '*'、'?'、'[' 放入字符类 (如 "[*]")，
+// '\' 在非 Windows 系统上是转义符，需写成 "\\"，在 Windows 上是路径分隔符，保持不变
+func writeGlobLiteral(b *strings.Builder, c byte) {
+    switch {
+    case c == '*' || c == '?' || c == '[':
+        b.WriteByte('[')
+        b.WriteByte(c)
+        b.WriteByte(']')
+    case c == '\\' && runtime.GOOS != "windows":
+        b.WriteString(`\\`)
+    default:
+        b.WriteByte(c)
+    }
+}
+
+// Sync 将当前文件落盘
+func (w *rotatingWriter) Sync() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    return w.file.Sync()
+}
+
+// Close 关闭当前文件
+func (w *rotatingWriter) Close() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    return w.file.Close()
+}
@@ -0,0 +1,106 @@
+package log
+
+import (
+    "net"
+    "net/http"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// loggingRoundTripper 记录出站 HTTP 请求日志，并向允许的主机注入关联字段 Header
+type loggingRoundTripper struct {
+    next   http.RoundTripper
+    logger Logger
+    hosts  []string // 除本机外注入关联字段 Header 的主机
+    self   string   // 本机主机名，获取失败时为空
+}
+
+// RoundTripperOption 定义 NewLoggingRoundTripper 的可选配置
+type RoundTripperOption func(*loggingRoundTripper)
+
+// WithPropagationHosts 指定发送前注入关联字段 Header (见 InjectHeaders) 的目标主机，不含端口，匹配不区分大小写，
+// "*.example.com" 匹配其任意子域名。默认只向本机 (localhost、回环地址与本机主机名) 注入，
+// 避免将请求 ID、用户 ID 等关联字段泄露给第三方服务
+func WithPropagationHosts(hosts ...string) RoundTripperOption {
+    return func(t *loggingRoundTripper) {
+        t.hosts = append(t.hosts, hosts...)
+    }
+}
+
+// NewLoggingRoundTripper 返回记录出站 HTTP 请求日志的 RoundTripper，next 为 nil 时使用 http.DefaultTransport。
+// 请求的目标为本机或在 WithPropagationHosts 指定的范围内时，发送前将请求 Context 中的请求 ID、trace_id 等关联字段写入 Header；
+// 请求结束后输出一条 Info 日志 (失败时为 Error)，包含 http_method、http_url (不含查询参数)、http_status 与 http_duration_ms 字段；
+// Debug 级别下另输出一条脱敏后的请求头日志，Authorization、Cookie 等 Header 以 RedactedValue 代替。
+func NewLoggingRoundTripper(next http.RoundTripper, l Logger, opts ...RoundTripperOption) http.RoundTripper {
+    if next == nil {
+        next = http.DefaultTransport
+    }
+    t := &loggingRoundTripper{next: next, logger: l}
+    t.self, _ = os.Hostname()
+    for _, opt := range opts {
+        opt(t)
+    }
+    return t
+}
+
+// propagates 判断是否向该主机注入关联字段 Header: 本机总是注入，其他主机需在 hosts 范围内
+func (t *loggingRoundTripper) propagates(host string) bool {
+    if strings.EqualFold(host, "localhost") || t.self != "" && strings.EqualFold(host, t.self) {
+        return true
+    }
+    if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+        return true
+    }
+    for _, h := range t.hosts {
+        if suffix, ok := strings.CutPrefix(h, "*"); ok {
+            if strings.HasSuffix(strings.ToLower(host), strings.ToLower(suffix)) {
+                return true
+            }
+        } else if strings.EqualFold(host, h) {
+            return true
+        }
+    }
+    return false
+}
+
+// loggedURL 返回用于日志的 URL: 去掉可能携带令牌等敏感信息的查询参数与片段，并隐藏密码
+func loggedURL(req *http.Request) string {
+    u := *req.URL
+    u.RawQuery, u.ForceQuery, u.Fragment, u.RawFragment = "", false, "", ""
+    return u.Redacted()
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+    ctx := req.Context()
+    if t.propagates(req.URL.Hostname()) {
+        // RoundTripper 不能修改传入的请求，注入 Header 前先复制
+        req = req.Clone(ctx)
+        InjectHeaders(ctx, req.Header)
+    }
+
+    start := time.Now()
+    resp, err := t.next.RoundTrip(req)
+
+    fields := map[string]any{
+        "http_method":      req.Method,
+        "http_url":         loggedURL(req),
+        "http_duration_ms": time.Since(start).Milliseconds(),
+    }
+    if err != nil {
+        fields[logrus.ErrorKey] = err
+        withFields(t.logger, fields).ErrorContextf(ctx, "http client request failed")
+    } else {
+        fields["http_status"] = resp.StatusCode
+        withFields(t.logger, fields).InfoContextf(ctx, "http client request")
+    }
+
+    if cp, ok := t.logger.(ConfigProvider); ok && cp.GetConfig().Level >= logrus.DebugLevel {
+        withFields(t.logger, map[string]any{"http_request_headers": redactHeaders(req.Header, defaultRedactedHeaders)}).
+            DebugContextf(ctx, "http client request headers")
+    }
+    return resp, err
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+    "encoding/json"
+    "fmt"
+
+    "github.com/sirupsen/logrus"
+)
+
+// safeJSONFormatter 包装 JSON Formatter: 编码失败时将无法序列化的字段值 (如 channel、循环引用的结构体)
+// 替换为 "<unmarshalable: 类型>" 占位符后重试，避免整条日志被丢弃
+type safeJSONFormatter struct {
+    logrus.Formatter
+}
+
+// Format 实现 logrus.Formatter
+func (f *safeJSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+    out, err := f.Formatter.Format(entry)
+    if err == nil {
+        return out, nil
+    }
+    data := make(logrus.Fields, len(entry.Data))
+    replaced := false
+    for k, v := range entry.Data {
+        if _, isErr := v.(error); !isErr {
+            if _, merr := json.Marshal(v); merr != nil {
+                v = fmt.Sprintf("<unmarshalable: %T>", v)
+                replaced = true
+            }
+        }
+        data[k] = v
+    }
+    if !replaced {
+        return nil, err
+    }
+    entry.Data = data
+    return f.Formatter.Format(entry)
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+    "math/rand/v2"
+
+    "github.com/sirupsen/logrus"
+)
+
+// levelSampler 按级别对日志做随机采样，rates 中未配置的级别全部保留
+type levelSampler struct {
+    rates map[logrus.Level]float64
+}
+
+// newLevelSampler 创建采样器，未配置任何采样率时返回 nil
+func newLevelSampler(rates map[logrus.Level]float64) *levelSampler {
+    if len(rates) == 0 {
+        return nil
+    }
+    copied := make(map[logrus.Level]float64, len(rates))
+    for level, rate := range rates {
+        copied[level] = rate
+    }
+    return &levelSampler{rates: copied}
+}
+
+// sample 判断该级别的本条日志是否保留
+func (s *levelSampler) sample(level logrus.Level) bool {
+    rate, ok := s.rates[level]
+    if !ok || rate >= 1 {
+        return true
+    }
+    if rate <= 0 {
+        return false
+    }
+    return rand.Float64() < rate
+}
+
+// allow 判断本条日志是否应继续构建并输出。
+// 采样需要在进入 logrus 之前完成：logrus 的 Hook 无法阻止条目被写出。
+// Fatal 日志需要退出进程，不经过该判断。
+func (l *LogrusLogger) allow(level logrus.Level) bool {
+    if !l.Logger.IsLevelEnabled(level) {
+        return false
+    }
+    return l.pass(level)
+}
+
+// pass 执行级别过滤之外的频率控制: 按级别采样与高负载时的级别收紧
+func (l *LogrusLogger) pass(level logrus.Level) bool {
+    if l.sampler != nil && !l.sampler.sample(level) {
+        return false
+    }
+    if l.load != nil && l.load.drop(level, l.Logger.GetLevel()) {
+        return false
+    }
+    return true
+}
@@ -0,0 +1,88 @@
+package log
+
+import (
+    "fmt"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/sirupsen/logrus"
+)
+
+// hasControl 判断字符串是否包含控制字符 (换行、制表符、ANSI 转义等)
+func hasControl(s string) bool {
+    return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
+
+// escapeControl 将控制字符转义为可见的转义序列，例如 "\n" 转为 `\n`，ESC 转为 `\x1b`。
+// 不含控制字符的字符串原样返回。
+func escapeControl(s string) string {
+    if !hasControl(s) {
+        return s
+    }
+    var b strings.Builder
+    b.Grow(len(s) + 8)
+    for _, r := range s {
+        switch r {
+        case '\n':
+            b.WriteString(`\n`)
+        case '\r':
+            b.WriteString(`\r`)
+        case '\t':
+            b.WriteString(`\t`)
+        default:
+            if !unicode.IsControl(r) {
+                b.WriteRune(r)
+            } else if r < 0x100 {
+                fmt.Fprintf(&b, `\x%02x`, r)
+            } else {
+                fmt.Fprintf(&b, `\u%04x`, r)
+            }
+        }
+    }
+    return b.String()
+}
+
+// sanitizeNewlines 转义消息与字段名中的控制字符，防止日志伪造 (log forging) 或破坏单行结构。
+// 字段值由文本 Formatter 在输出时加引号转义，JSON 编码本身会转义控制字符，因此只用于文本格式。
+func sanitizeNewlines(entry *logrus.Entry) {
+    // 与 logrus TextFormatter 一致，忽略消息末尾的单个换行
+    entry.Message = escapeControl(strings.TrimSuffix(entry.Message, "\n"))
+
+    for k, v := range entry.Data {
+        if hasControl(k) {
+            delete(entry.Data, k)
+            entry.Data[escapeControl(k)] = v
+        }
+    }
+}
+
+// sanitizeUTF8 将消息、字段名以及字符串 (含 error) 类型字段值中的非法 UTF-8 序列替换为 U+FFFD，
+// 避免来自不可信输入的数据破坏 JSON 编码或下游的日志解析
+func sanitizeUTF8(entry *logrus.Entry) {
+    entry.Message = toValidUTF8(entry.Message)
+
+    for k, v := range entry.Data {
+        switch val := v.(type) {
+        case string:
+            v = toValidUTF8(val)
+        case error:
+            if msg := val.Error(); !utf8.ValidString(msg) {
+                v = toValidUTF8(msg)
+            }
+        }
+        if !utf8.ValidString(k) {
+            delete(entry.Data, k)
+            k = toValidUTF8(k)
+        }
+        entry.Data[k] = v
+    }
+}
+
+// toValidUTF8 将 s 中每段非法 UTF-8 序列替换为 U+FFFD
+func toValidUTF8(s string) string {
+    if utf8.ValidString(s) {
+        return s
+    }
+    return strings.ToValidUTF8(s, string(utf8.RuneError))
+}
@@ -0,0 +1,32 @@
+package log
+
+import (
+    "sync/atomic"
+
+    "github.com/sirupsen/logrus"
+)
+
+// SequenceFieldKey 是 Config.IncludeSequence 开启时序号字段的字段名
+const SequenceFieldKey = "seq"
+
+// sequenceFormatter 在编码前为条目分配递增序号。
+// logrus 在 Logger 锁内调用 Formatter.Format 并写出，因此序号的分配顺序与输出顺序一致；
+// 被级别过滤或采样丢弃的日志不会占用序号，输出中出现的空缺即表示下游丢失了日志。
+type sequenceFormatter struct {
+    logrus.Formatter
+    seq *atomic.Uint64
+}
+
+// Format 实现 logrus.Formatter
+func (f *sequenceFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+    entry.Data[SequenceFieldKey] = f.seq.Add(1)
+    return f.Formatter.Format(entry)
+}
+
+// wrapSequenceFormatter 为 f 添加序号字段，seq 为 nil (未开启 IncludeSequence) 时原样返回
+func wrapSequenceFormatter(f logrus.Formatter, seq *atomic.Uint64) logrus.Formatter {
+    if seq == nil {
+        return f
+    }
+    return &sequenceFormatter{Formatter: f, seq: seq}
+}
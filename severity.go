@@ -0,0 +1,32 @@
+package log
+
+import "context"
+
+// SeverityKey 用于在 Context 中存储告警严重程度，同时也是日志中的字段名
+const SeverityKey contextKey = "severity"
+
+// Severity 表示供告警系统使用的严重程度，与日志级别相互独立，
+// 例如一条 Info 级别的日志可以携带 severity=notice。取值参考 syslog 的严重程度名称。
+type Severity string
+
+const (
+    SeverityDebug     Severity = "debug"
+    SeverityInfo      Severity = "info"
+    SeverityNotice    Severity = "notice"
+    SeverityWarning   Severity = "warning"
+    SeverityError     Severity = "error"
+    SeverityCritical  Severity = "critical"
+    SeverityAlert     Severity = "alert"
+    SeverityEmergency Severity = "emergency"
+)
+
+// WithSeverity 将严重程度添加到 Context 中，之后使用该 Context 的日志都会带上 severity 字段，不影响日志级别
+func WithSeverity(ctx context.Context, sev Severity) context.Context {
+    return context.WithValue(ctx, SeverityKey, sev)
+}
+
+// GetSeverity 从 Context 中获取严重程度
+func GetSeverity(ctx context.Context) (Severity, bool) {
+    val, ok := ctx.Value(SeverityKey).(Severity)
+    return val, ok
+}
@@ -0,0 +1,79 @@
+package log
+
+import (
+    "context"
+    "fmt"
+    "hash/fnv"
+    "strconv"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// ErrorSignatureFieldKey 是 ErrorfSampled 输出的错误签名字段名，取值为签名的 16 位十六进制哈希，便于按签名聚合
+const ErrorSignatureFieldKey = "error_signature"
+
+// errorSignature 返回由消息模板与错误类型组成的归一化签名。
+// 使用未格式化的模板而不是最终消息，保证参数 (ID、耗时等) 不同的同类错误得到相同的签名
+func errorSignature(format string, err error) string {
+    h := fnv.New64a()
+    _, _ = h.Write([]byte(format))
+    _, _ = h.Write([]byte{0})
+    _, _ = fmt.Fprintf(h, "%T", err)
+    return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// ErrorSampler 由支持按错误签名采样的 Logger (*LogrusLogger、*MultiLogger) 实现，全局函数 ErrorfSampled 通过它调用
+type ErrorSampler interface {
+    ErrorfSampled(ctx context.Context, err error, format string, args ...any)
+}
+
+// ErrorfSampled 以 Error 级别输出日志，err 作为 error 字段输出。按错误签名 (消息模板 + 错误类型) 采样:
+// 每个签名首次出现时总是完整输出 (与 Fatalf 一样不经过 LevelSampleRates、AdaptiveLevel 与请求级采样)，
+// 之后在 Config.ErrorSignatureWindow 内至多输出一次，并通过 suppressed_count 报告期间被丢弃的次数，
+// 保证每种错误至少留下一条完整日志。
+func (l *LogrusLogger) ErrorfSampled(ctx context.Context, err error, format string, args ...any) {
+    level, ok := l.emitLevel(ctx, logrus.ErrorLevel)
+    if !ok {
+        return
+    }
+    signature := errorSignature(format, err)
+    allowed, suppressed, first := l.errSigs.allowFirst(signature, time.Now())
+    if !allowed || !first && !l.pass(logrus.ErrorLevel) {
+        return
+    }
+    entry := l.leveledEntry(ctx, logrus.ErrorLevel, level)
+    entry.Data[ErrorSignatureFieldKey] = signature
+    if err != nil {
+        entry.Data[logrus.ErrorKey] = err
+    }
+    if suppressed > 0 {
+        entry.Data[SuppressedCountFieldKey] = suppressed
+    }
+    if first {
+        l.logfUnsampled(entry, level, format, args...)
+        return
+    }
+    l.logf(entry, level, format, args...)
+}
+
+// logfUnsampled 与 logf 相同但跳过请求级缓冲与采样，在调用栈中占据与 logf 相同的层级
+func (l *LogrusLogger) logfUnsampled(entry *logrus.Entry, level logrus.Level, format string, args ...any) {
+    entry.Logf(level, format, args...)
+}
+
+// ErrorfSampled 在所有 Logger 上按错误签名采样输出，不支持采样的 Logger 以普通 Error 日志输出
+func (m *MultiLogger) ErrorfSampled(ctx context.Context, err error, format string, args ...any) {
+    for _, l := range m.loggers {
+        errorfSampled(ctx, l, err, format, args)
+    }
+}
+
+// errorfSampled 在 l 支持时按错误签名采样输出，否则带上 error 字段以普通 Error 日志输出
+func errorfSampled(ctx context.Context, l Logger, err error, format string, args []any) {
+    if s, ok := l.(ErrorSampler); ok {
+        s.ErrorfSampled(ctx, err, format, args...)
+        return
+    }
+    withFields(l, errorFields(err)).ErrorContextf(ctx, format, args...)
+}
@@ -0,0 +1,81 @@
+package log
+
+import (
+    "context"
+    "encoding/hex"
+    "net/url"
+    "sort"
+    "strings"
+)
+
+// SQLComment 将 Context 中的请求 ID 与链路追踪 ID 格式化为 sqlcommenter 风格的 SQL 注释，
+// 例如 /*request_id='req-1',traceparent='00-<trace_id>-<span_id>-01'*/，可追加在 SQL 语句末尾，
+// 使数据库日志与应用日志能够关联。
+//
+// 只有当 trace_id 为 32 位、span_id 为 16 位十六进制 (W3C Trace Context 格式) 时才输出 traceparent，
+// 否则分别以 trace_id、span_id 键原样输出。键按字母序排列，值经过 URL 编码。
+// Context 中没有任何 ID 时返回空字符串。
+func SQLComment(ctx context.Context) string {
+    tags := make(map[string]string)
+    if reqID, ok := GetRequestID(ctx); ok && reqID != "" {
+        tags[string(RequestIDKey)] = reqID
+    }
+    traceID, _ := GetTraceID(ctx)
+    spanID, _ := GetSpanID(ctx)
+    if isHexID(traceID, 16) && isHexID(spanID, 8) {
+        tags["traceparent"] = "00-" + strings.ToLower(traceID) + "-" + strings.ToLower(spanID) + "-01"
+    } else {
+        if traceID != "" {
+            tags[string(TraceIDKey)] = traceID
+        }
+        if spanID != "" {
+            tags[string(SpanIDKey)] = spanID
+        }
+    }
+    if len(tags) == 0 {
+        return ""
+    }
+
+    keys := make([]string, 0, len(tags))
+    for k := range tags {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+
+    var b strings.Builder
+    b.WriteString("/*")
+    for i, k := range keys {
+        if i > 0 {
+            b.WriteByte(',')
+        }
+        b.WriteString(k)
+        b.WriteString("='")
+        b.WriteString(sqlCommentEscape(tags[k]))
+        b.WriteByte('\'')
+    }
+    b.WriteString("*/")
+    return b.String()
+}
+
+// sqlCommentEscape 按 sqlcommenter 规范对值进行 URL 编码，空格编码为 %20，
+// 编码后不会包含单引号与注释结束符
+func sqlCommentEscape(s string) string {
+    return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// isHexID 判断 s 是否为 n 字节的十六进制 ID，且不全为 0
+func isHexID(s string, n int) bool {
+    if len(s) != n*2 {
+        return false
+    }
+    b, err := hex.DecodeString(s)
+    if err != nil {
+        return false
+    }
+    for _, c := range b {
+        if c != 0 {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,69 @@
+package log
+
+import (
+    "runtime"
+    "strconv"
+    "strings"
+
+    "github.com/sirupsen/logrus"
+)
+
+// StacktraceFieldKey 是调用栈字段名，见 CaptureStacktrace 与 Builder.Stack
+const StacktraceFieldKey = "stacktrace"
+
+// maxStackDepth 是 CaptureStacktrace 记录的最大栈帧数
+const maxStackDepth = 64
+
+// StackFrame 是调用栈中的一帧
+type StackFrame struct {
+    Func string `json:"func"`
+    File string `json:"file"`
+    Line int    `json:"line"`
+}
+
+// Stacktrace 是从内到外排列的调用栈。默认编码为与 debug.Stack 相近的多行字符串
+// (每帧 "func\n\tfile:line")；开启 Config.StructuredStacktrace 时 JSON 格式输出为 {func, file, line} 对象数组。
+type Stacktrace []StackFrame
+
+// CaptureStacktrace 记录当前调用栈，skip 为 0 时第一帧是 CaptureStacktrace 的调用者
+func CaptureStacktrace(skip int) Stacktrace {
+    pcs := make([]uintptr, maxStackDepth)
+    n := runtime.Callers(skip+2, pcs)
+    frames := runtime.CallersFrames(pcs[:n])
+    stack := make(Stacktrace, 0, n)
+    for {
+        frame, more := frames.Next()
+        stack = append(stack, StackFrame{Func: frame.Function, File: frame.File, Line: frame.Line})
+        if !more {
+            return stack
+        }
+    }
+}
+
+// String 返回多行文本形式的调用栈
+func (s Stacktrace) String() string {
+    var b strings.Builder
+    for i, f := range s {
+        if i > 0 {
+            b.WriteByte('\n')
+        }
+        b.WriteString(f.Func)
+        b.WriteString("\n\t")
+        b.WriteString(f.File)
+        b.WriteByte(':')
+        b.WriteString(strconv.Itoa(f.Line))
+    }
+    return b.String()
+}
+
+// MarshalText 实现 encoding.TextMarshaler，使 JSON 默认将调用栈编码为字符串
+func (s Stacktrace) MarshalText() ([]byte, error) {
+    return []byte(s.String()), nil
+}
+
+// structuredStacktrace 将 Stacktrace 类型的调用栈字段转换为栈帧切片，由 JSON 编码为对象数组
+func structuredStacktrace(entry *logrus.Entry) {
+    if s, ok := entry.Data[StacktraceFieldKey].(Stacktrace); ok {
+        entry.Data[StacktraceFieldKey] = []StackFrame(s)
+    }
+}
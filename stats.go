@@ -0,0 +1,21 @@
+package log
+
+import "time"
+
+// WriterStats 是缓冲、批量发送类 Writer 的运行统计，用于排查日志丢失等问题。计数均为日志条数
+type WriterStats struct {
+    Enqueued         uint64        // 写入 Writer 的条数
+    Flushed          uint64        // 成功写出到底层输出的条数
+    Dropped          uint64        // 因写出失败或 Writer 已关闭而丢弃的条数
+    Errors           uint64        // 写出失败的次数
+    Pending          int           // 当前缓冲中尚未写出的条数
+    LastFlushLatency time.Duration // 最近一次批量写出的耗时
+}
+
+// WriterStats 返回缓冲输出 (Config.BufferSize > 0) 的统计信息，未启用缓冲时返回零值
+func (l *LogrusLogger) WriterStats() WriterStats {
+    if l.buffer == nil {
+        return WriterStats{}
+    }
+    return l.buffer.writerStats()
+}
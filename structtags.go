@@ -0,0 +1,267 @@
+package log
+
+import (
+    "fmt"
+    "reflect"
+    "strings"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+// StructTagName 是控制结构体字段日志输出的标签名:
+// `log:"-"` 表示不输出该字段，`log:"redact"` 表示输出 RedactedValue 代替真实值，
+// `log:"name"` 指定输出的字段名 (优先于 json 标签)，可与 redact 组合为 `log:"name,redact"`。
+// 与 encoding/json 一致，`json:"-"` 的字段同样不输出，除非 log 标签显式指定了名称
+const StructTagName = "log"
+
+// structFieldInfo 描述结构体的一个导出字段
+type structFieldInfo struct {
+    index  int
+    name   string
+    omit   bool
+    redact bool
+}
+
+// structInfo 缓存结构体类型的字段信息，tagged 表示该类型 (含嵌套的结构体字段) 是否使用了 log 标签
+type structInfo struct {
+    fields []structFieldInfo
+    tagged bool
+}
+
+var structInfoCache sync.Map // map[reflect.Type]*structInfo
+
+// circularValue 替换结构体值中指回自身所在路径的指针 (如链表环、parent 指针)
+const circularValue = "[circular]"
+
+// visitKey 标识当前路径上已展开的指针、切片或 map，类型用于区分地址相同的不同值 (如切片与其首个元素)
+type visitKey struct {
+    ptr uintptr
+    typ reflect.Type
+}
+
+// respectStructTags 将带有 log 标签的结构体字段值转换为按标签省略或脱敏后的 map，
+// 切片、数组与 map 中的这类结构体同样会被转换。未使用 log 标签的值保持原样，不改变其输出形式。
+func respectStructTags(entry *logrus.Entry) {
+    for k, v := range entry.Data {
+        if converted, ok := applyStructTags(reflect.ValueOf(v), map[visitKey]bool{}); ok {
+            entry.Data[k] = converted
+        }
+    }
+}
+
+// applyStructTags 转换结构体、结构体指针以及包含它们的切片、数组与 map，ok 为 false 表示无需转换。
+// path 记录当前路径上已展开的指针、切片与 map，再次遇到时输出 circularValue，避免自引用的值无限递归
+func applyStructTags(v reflect.Value, path map[visitKey]bool) (any, bool) {
+    for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+        if v.IsNil() {
+            return nil, false
+        }
+        if v.Kind() == reflect.Pointer {
+            if !mayContainTagged(v.Type()) {
+                return nil, false
+            }
+            key := visitKey{v.Pointer(), v.Type()}
+            if path[key] {
+                return circularValue, true
+            }
+            path[key] = true
+            defer delete(path, key)
+        }
+        v = v.Elem()
+    }
+    if !mayContainTagged(v.Type()) {
+        return nil, false
+    }
+    switch v.Kind() {
+    case reflect.Struct:
+        return applyStructFieldTags(v, path)
+    case reflect.Slice, reflect.Map:
+        if v.IsNil() {
+            return nil, false
+        }
+        key := visitKey{v.Pointer(), v.Type()}
+        if path[key] {
+            return circularValue, true
+        }
+        path[key] = true
+        defer delete(path, key)
+        if v.Kind() == reflect.Map {
+            return applyMapTags(v, path)
+        }
+        return applyElemTags(v, path)
+    case reflect.Array:
+        return applyElemTags(v, path)
+    }
+    return nil, false
+}
+
+// applyStructFieldTags 按标签转换结构体 v 的字段
+func applyStructFieldTags(v reflect.Value, path map[visitKey]bool) (any, bool) {
+    info := getStructInfo(v.Type())
+    if !info.tagged {
+        return nil, false
+    }
+    out := make(map[string]any, len(info.fields))
+    for _, f := range info.fields {
+        switch {
+        case f.omit:
+            continue
+        case f.redact:
+            out[f.name] = RedactedValue
+        default:
+            fv := v.Field(f.index)
+            if converted, ok := applyStructTags(fv, path); ok {
+                out[f.name] = converted
+            } else {
+                out[f.name] = fv.Interface()
+            }
+        }
+    }
+    return out, true
+}
+
+// applyElemTags 转换切片或数组的元素，没有元素需要转换时返回 false
+func applyElemTags(v reflect.Value, path map[visitKey]bool) (any, bool) {
+    var out []any
+    for i := 0; i < v.Len(); i++ {
+        converted, ok := applyStructTags(v.Index(i), path)
+        if ok && out == nil {
+            out = make([]any, v.Len())
+            for j := 0; j < i; j++ {
+                out[j] = v.Index(j).Interface()
+            }
+        }
+        switch {
+        case ok:
+            out[i] = converted
+        case out != nil:
+            out[i] = v.Index(i).Interface()
+        }
+    }
+    return out, out != nil
+}
+
+// applyMapTags 转换 map 的值，键按 fmt.Sprint 转为字符串；没有值需要转换时返回 false
+func applyMapTags(v reflect.Value, path map[visitKey]bool) (any, bool) {
+    out := make(map[string]any, v.Len())
+    changed := false
+    iter := v.MapRange()
+    for iter.Next() {
+        key := fmt.Sprint(iter.Key().Interface())
+        if converted, ok := applyStructTags(iter.Value(), path); ok {
+            out[key] = converted
+            changed = true
+        } else {
+            out[key] = iter.Value().Interface()
+        }
+    }
+    return out, changed
+}
+
+// mayContainTagged 判断 t 类型的值中是否可能含有带 log 标签的结构体: 元素类型为 interface 时只能在运行时判断
+func mayContainTagged(t reflect.Type) bool {
+    elem := elemStructType(t)
+    switch elem.Kind() {
+    case reflect.Interface:
+        return true
+    case reflect.Struct:
+        return getStructInfo(elem).tagged
+    }
+    return false
+}
+
+// getStructInfo 解析并缓存结构体类型的字段信息，字段名优先使用 json 标签中的名称
+func getStructInfo(t reflect.Type) *structInfo {
+    info, _ := loadStructInfo(t, make(map[reflect.Type]bool))
+    return info
+}
+
+// loadStructInfo 返回缓存的字段信息，未缓存时解析。visiting 记录解析中的类型，避免自引用类型无限递归。
+// 因遇到解析中的其他类型而跳过的类型记录在 pending 中: 此时 tagged 可能只是部分结果 (例如相互引用的类型中，
+// 被跳过的类型带有 redact 标签)，不能缓存，等到以该类型为起点重新解析时再缓存
+func loadStructInfo(t reflect.Type, visiting map[reflect.Type]bool) (info *structInfo, pending map[reflect.Type]bool) {
+    if cached, ok := structInfoCache.Load(t); ok {
+        return cached.(*structInfo), nil
+    }
+    visiting[t] = true
+    defer delete(visiting, t)
+    info = &structInfo{}
+    for i := 0; i < t.NumField(); i++ {
+        sf := t.Field(i)
+        if !sf.IsExported() {
+            continue
+        }
+        f := structFieldInfo{index: i, name: sf.Name}
+        // 与 encoding/json 一致，`json:"-"` 的字段默认不输出，只有 log 标签显式指定名称时才输出
+        switch jsonTag := sf.Tag.Get("json"); jsonTag {
+        case "-":
+            f.omit = true
+        default:
+            if name, _, _ := strings.Cut(jsonTag, ","); name != "" {
+                f.name = name
+            }
+        }
+        for _, opt := range strings.Split(sf.Tag.Get(StructTagName), ",") {
+            switch opt {
+            case "":
+            case "-":
+                f.omit = true
+            case "redact":
+                f.redact = true
+            default:
+                f.name = opt
+                f.omit = false
+            }
+            if opt != "" {
+                info.tagged = true
+            }
+        }
+        info.fields = append(info.fields, f)
+    }
+    for _, f := range info.fields {
+        if info.tagged {
+            break
+        }
+        ft := elemStructType(t.Field(f.index).Type)
+        switch {
+        case ft.Kind() != reflect.Struct || ft == t:
+        case visiting[ft]:
+            pending = addPending(pending, ft)
+        default:
+            nested, nestedPending := loadStructInfo(ft, visiting)
+            info.tagged = nested.tagged
+            for p := range nestedPending {
+                if p != t {
+                    pending = addPending(pending, p)
+                }
+            }
+        }
+    }
+    if info.tagged || len(pending) == 0 {
+        // tagged 为 true 时不受未解析完的类型影响
+        actual, _ := structInfoCache.LoadOrStore(t, info)
+        return actual.(*structInfo), nil
+    }
+    return info, pending
+}
+
+func addPending(pending map[reflect.Type]bool, t reflect.Type) map[reflect.Type]bool {
+    if pending == nil {
+        pending = make(map[reflect.Type]bool)
+    }
+    pending[t] = true
+    return pending
+}
+
+// elemStructType 逐层取出指针、切片、数组与 map 的元素类型，例如 []*User 与 map[string]User 均返回 User
+func elemStructType(t reflect.Type) reflect.Type {
+    for {
+        switch t.Kind() {
+        case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+            t = t.Elem()
+        default:
+            return t
+        }
+    }
+}
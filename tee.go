@@ -0,0 +1,76 @@
+package log
+
+import (
+    "io"
+    "slices"
+    "sync"
+)
+
+// teeWriter 是 LogrusLogger 输出链的最外层: 写入主输出后，再把同一条日志复制到运行时添加的旁路 Writer。
+// 旁路 Writer 的错误被忽略，不影响主输出；但写入是同步的，缓慢的旁路 Writer 会拖慢日志调用。
+type teeWriter struct {
+    mu   sync.RWMutex
+    w    io.Writer
+    tees []*teeSink
+}
+
+// teeSink 包装旁路 Writer，按指针识别，避免要求 Writer 本身可比较
+type teeSink struct {
+    w io.Writer
+}
+
+// Write 实现 io.Writer，返回主输出的结果
+func (t *teeWriter) Write(p []byte) (int, error) {
+    t.mu.RLock()
+    w, tees := t.w, t.tees
+    t.mu.RUnlock()
+
+    n, err := w.Write(p)
+    for _, tee := range tees {
+        _, _ = tee.w.Write(p)
+    }
+    return n, err
+}
+
+func (t *teeWriter) setWriter(w io.Writer) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.w = w
+}
+
+// add 添加旁路 Writer，返回的函数将其移除，可重复调用
+func (t *teeWriter) add(w io.Writer) (remove func()) {
+    sink := &teeSink{w: w}
+    t.mu.Lock()
+    // 写时复制，Write 读取到的切片不会被修改
+    t.tees = append(slices.Clip(t.tees), sink)
+    t.mu.Unlock()
+
+    var once sync.Once
+    return func() {
+        once.Do(func() {
+            t.mu.Lock()
+            defer t.mu.Unlock()
+            t.tees = slices.DeleteFunc(slices.Clone(t.tees), func(s *teeSink) bool { return s == sink })
+        })
+    }
+}
+
+// AddTee 在不改变现有输出的情况下，把之后的每条日志 (格式化后的内容) 同时写入 w，
+// 例如用于实时调试时转发到 websocket。返回的 remove 函数停止转发，不会关闭 w。
+// 父子 Logger 共享输出链，在任一 Logger 上添加的旁路对它们都生效。
+func (l *LogrusLogger) AddTee(w io.Writer) (remove func()) {
+    if l.tee == nil {
+        // 回退 Logger 没有输出链，无法添加旁路
+        return func() {}
+    }
+    return l.tee.add(w)
+}
+
+// AddGlobalTee 为全局 Logger 添加旁路输出，参见 LogrusLogger.AddTee
+func AddGlobalTee(w io.Writer) (remove func()) {
+    if l, ok := GetGlobalLogger().(*LogrusLogger); ok {
+        return l.AddTee(w)
+    }
+    return func() {}
+}
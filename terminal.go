@@ -0,0 +1,34 @@
+package log
+
+import "io"
+
+// terminalChecker 由能够自行判断是否为终端的 Writer 实现，便于包装类型或测试替身透传终端状态
+type terminalChecker interface {
+    IsTerminal() bool
+}
+
+// fdWriter 是持有文件描述符的 Writer，*os.File 即满足该接口
+type fdWriter interface {
+    Fd() uintptr
+}
+
+// isTerminalWriter 判断 w 是否写入终端。非文件类型的 Writer 返回 false
+func isTerminalWriter(w io.Writer) bool {
+    switch w := w.(type) {
+    case terminalChecker:
+        return w.IsTerminal()
+    case fdWriter:
+        return isTerminalFd(w.Fd())
+    }
+    return false
+}
+
+// IsTerminal 判断普通级别日志的输出目标 (Output 或 FilePath) 是否为终端，按时间轮转的文件总是返回 false
+func (l *LogrusLogger) IsTerminal() bool {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+    if l.file != nil && (l.config.FilePath != "" || l.config.RotationFilePattern != "") {
+        return isTerminalWriter(l.file)
+    }
+    return isTerminalWriter(l.config.Output)
+}
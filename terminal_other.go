@@ -0,0 +1,8 @@
+//go:build !linux && !aix && !zos && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package log
+
+// isTerminalFd 在不支持的平台上始终返回 false
+func isTerminalFd(fd uintptr) bool {
+    return false
+}
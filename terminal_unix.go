@@ -0,0 +1,11 @@
+//go:build linux || aix || zos
+
+package log
+
+import "golang.org/x/sys/unix"
+
+// isTerminalFd 通过读取终端属性判断 fd 是否为终端
+func isTerminalFd(fd uintptr) bool {
+    _, err := unix.IoctlGetTermios(int(fd), unix.TCGETS)
+    return err == nil
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package log
+
+import "golang.org/x/sys/windows"
+
+// isTerminalFd 通过读取控制台模式判断 fd 是否为控制台
+func isTerminalFd(fd uintptr) bool {
+    var mode uint32
+    return windows.GetConsoleMode(windows.Handle(fd), &mode) == nil
+}
@@ -0,0 +1,228 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sirupsen/logrus"
+)
+
+// syncCountingWriter 记录 Sync 调用次数
+type syncCountingWriter struct {
+    bytes.Buffer
+    syncs int
+}
+
+func (w *syncCountingWriter) Sync() error {
+    w.syncs++
+    return nil
+}
+
+func TestAuditLoggerFile(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "audit.log")
+    cfg := log.DefaultConfig()
+    cfg.FilePath = path
+    cfg.Format = log.FormatJSON
+    a, err := log.NewAuditLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewAuditLogger: %v", err)
+    }
+    defer a.Close()
+
+    ctx := log.WithRequestID(context.Background(), "req-1")
+    for i := 1; i <= 3; i++ {
+        if err := a.Log(ctx, "alice", "delete_user", "deleted user %d", i); err != nil {
+            t.Fatalf("Log: %v", err)
+        }
+        // 每条审计日志写入后立即可见
+        data, err := os.ReadFile(path)
+        if err != nil {
+            t.Fatal(err)
+        }
+        lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+        if len(lines) != i {
+            t.Fatalf("after %d entries file has %d lines", i, len(lines))
+        }
+        var m map[string]any
+        if err := json.Unmarshal(lines[i-1], &m); err != nil {
+            t.Fatal(err)
+        }
+        if m["actor"] != "alice" || m["action"] != "delete_user" || m["request_id"] != "req-1" {
+            t.Errorf("audit line = %v", m)
+        }
+    }
+}
+
+func TestAuditLoggerSyncsEachEntry(t *testing.T) {
+    w := &syncCountingWriter{}
+    cfg := log.DefaultConfig()
+    cfg.Output = w
+    a, err := log.NewAuditLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewAuditLogger: %v", err)
+    }
+    for i := 0; i < 5; i++ {
+        if err := a.Log(context.Background(), "bob", "login", "login"); err != nil {
+            t.Fatalf("Log: %v", err)
+        }
+    }
+    if w.syncs != 5 {
+        t.Errorf("syncs = %d, want 5", w.syncs)
+    }
+}
+
+func TestAuditLoggerReportsWriteError(t *testing.T) {
+    cfg := log.DefaultConfig()
+    cfg.Output = &failingWriter{fail: true}
+    a, err := log.NewAuditLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewAuditLogger: %v", err)
+    }
+    if err := a.Log(context.Background(), "bob", "login", "login"); err == nil {
+        t.Errorf("Log() = nil, want write error")
+    }
+}
+
+func TestAuditLoggerBypassesSampling(t *testing.T) {
+    w := &syncCountingWriter{}
+    cfg := log.DefaultConfig()
+    cfg.Output = w
+    cfg.LevelSampleRates = map[logrus.Level]float64{logrus.InfoLevel: 0}
+    a, err := log.NewAuditLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewAuditLogger: %v", err)
+    }
+    for i := 0; i < 10; i++ {
+        if err := a.Log(context.Background(), "bob", "login", "login %d", i); err != nil {
+            t.Fatalf("Log: %v", err)
+        }
+    }
+    if got := bytes.Count(w.Bytes(), []byte("\n")); got != 10 {
+        t.Errorf("audit lines = %d, want 10", got)
+    }
+}
+
+func TestAuditEvent(t *testing.T) {
+    var buf bytes.Buffer
+    cfg := log.DefaultConfig()
+    cfg.Output = &buf
+    a, err := log.NewAuditLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewAuditLogger: %v", err)
+    }
+
+    at := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+    err = a.Audit(context.Background(), log.AuditEvent{
+        Actor:     "alice",
+        Action:    "delete_user",
+        Resource:  "user/42",
+        Outcome:   log.AuditOutcomeSuccess,
+        Timestamp: at,
+        Fields:    map[string]any{"reason": "gdpr", "actor": "mallory"},
+    })
+    if err != nil {
+        t.Fatalf("Audit: %v", err)
+    }
+    var m map[string]any
+    if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+        t.Fatalf("unmarshal %q: %v", buf.String(), err)
+    }
+    want := map[string]any{
+        "actor":     "alice",
+        "action":    "delete_user",
+        "resource":  "user/42",
+        "outcome":   "success",
+        "timestamp": "2024-03-04T05:06:07Z",
+        "log_type":  "audit",
+        "reason":    "gdpr",
+        "msg":       "delete_user",
+    }
+    for k, v := range want {
+        if m[k] != v {
+            t.Errorf("%s = %v, want %v", k, m[k], v)
+        }
+    }
+
+    buf.Reset()
+    err = a.Audit(context.Background(), log.AuditEvent{Actor: "alice", Action: "login"})
+    if !errors.Is(err, log.ErrInvalidAuditEvent) || !strings.Contains(err.Error(), "resource, outcome") {
+        t.Errorf("Audit with missing fields = %v", err)
+    }
+    if buf.Len() != 0 {
+        t.Errorf("invalid event should not be written: %q", buf.String())
+    }
+}
+
+func TestAuditLoggerIgnoresRestrictiveLevel(t *testing.T) {
+    var buf bytes.Buffer
+    cfg := log.DefaultConfig()
+    cfg.Output = &buf
+    cfg.Level = logrus.ErrorLevel
+    a, err := log.NewAuditLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewAuditLogger: %v", err)
+    }
+    event := log.AuditEvent{Actor: "alice", Action: "delete_user", Resource: "user/42", Outcome: log.AuditOutcomeSuccess}
+    if err := a.Audit(context.Background(), event); err != nil {
+        t.Fatalf("Audit: %v", err)
+    }
+    if err := a.Log(context.Background(), "alice", "login", "login"); err != nil {
+        t.Fatalf("Log: %v", err)
+    }
+    lines := decodeLines(t, &buf)
+    if len(lines) != 2 || lines[0]["action"] != "delete_user" || lines[1]["action"] != "login" {
+        t.Fatalf("audit events dropped at Level=Error: %v", lines)
+    }
+
+    if err := a.SetLevel(logrus.WarnLevel); err == nil {
+        t.Errorf("SetLevel(Warn) should be rejected")
+    }
+    if err := a.SetLevel(logrus.DebugLevel); err != nil {
+        t.Errorf("SetLevel(Debug): %v", err)
+    }
+    if err := a.Log(context.Background(), "bob", "logout", "logout"); err != nil || len(decodeLines(t, &buf)) != 1 {
+        t.Errorf("audit event after SetLevel: err = %v", err)
+    }
+}
+
+func TestAuditLoggerFixedSchema(t *testing.T) {
+    var buf bytes.Buffer
+    cfg := log.DefaultConfig()
+    cfg.Output = &buf
+    cfg.Format = log.FormatJSON
+    cfg.AllowedFields = []string{"request_id"}
+    cfg.FieldNameStyle = log.FieldNameStyleCamel
+    cfg.FieldPrefix = "app."
+    cfg.PrefixExplicitFields = true
+    a, err := log.NewAuditLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewAuditLogger: %v", err)
+    }
+
+    // 白名单、命名风格与前缀不影响审计日志的 schema 字段
+    if err := a.Log(context.Background(), "alice", "login", "x"); err != nil {
+        t.Fatalf("Log: %v", err)
+    }
+    event := log.AuditEvent{Actor: "bob", Action: "delete_user", Resource: "user/42", Outcome: log.AuditOutcomeDenied}
+    if err := a.Audit(context.Background(), event); err != nil {
+        t.Fatalf("Audit: %v", err)
+    }
+    lines := decodeLines(t, &buf)
+    if len(lines) != 2 {
+        t.Fatalf("got %d lines: %v", len(lines), lines)
+    }
+    if m := lines[0]; m["actor"] != "alice" || m["action"] != "login" || m[log.AuditMarkerFieldKey] != log.AuditMarkerValue {
+        t.Errorf("Log line = %v", m)
+    }
+    if m := lines[1]; m["actor"] != "bob" || m["resource"] != "user/42" || m["outcome"] != log.AuditOutcomeDenied {
+        t.Errorf("Audit line = %v", m)
+    }
+}
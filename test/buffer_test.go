@@ -0,0 +1,297 @@
+package test
+
+import (
+    "bytes"
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sirupsen/logrus"
+)
+
+// syncBuffer 是并发安全的 bytes.Buffer，用于接收定时写出的日志
+type syncBuffer struct {
+    mu  sync.Mutex
+    buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    return b.buf.String()
+}
+
+func newBufferedLogger(t *testing.T, out *syncBuffer, interval time.Duration) *log.LogrusLogger {
+    t.Helper()
+    cfg := log.DefaultConfig()
+    cfg.Output = out
+    cfg.BufferSize = 64 * 1024
+    cfg.FlushInterval = interval
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    t.Cleanup(func() { l.(*log.LogrusLogger).Close() })
+    return l.(*log.LogrusLogger)
+}
+
+func TestBufferedOutput(t *testing.T) {
+    var out syncBuffer
+    l := newBufferedLogger(t, &out, 0)
+
+    l.Infof("buffered")
+    if out.String() != "" {
+        t.Fatalf("line written before flush: %q", out.String())
+    }
+    if err := l.Flush(); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if !strings.Contains(out.String(), "buffered") {
+        t.Errorf("line missing after flush: %q", out.String())
+    }
+
+    // 定时写出
+    var timed syncBuffer
+    tl := newBufferedLogger(t, &timed, 10*time.Millisecond)
+    tl.Infof("timed")
+    deadline := time.Now().Add(time.Second)
+    for !strings.Contains(timed.String(), "timed") {
+        if time.Now().After(deadline) {
+            t.Fatal("line not flushed by interval")
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+}
+
+func TestInstallCrashFlush(t *testing.T) {
+    var out syncBuffer
+    l := newBufferedLogger(t, &out, 0)
+
+    var recovered any
+    func() {
+        defer func() { recovered = recover() }()
+        defer log.InstallCrashFlush()
+        l.Infof("before crash")
+        panic("boom")
+    }()
+
+    if recovered != "boom" {
+        t.Errorf("recovered = %v, want original panic value", recovered)
+    }
+    if !strings.Contains(out.String(), "before crash") {
+        t.Errorf("buffered line lost on panic: %q", out.String())
+    }
+}
+
+func TestSyncFlushLevel(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "app.log")
+    cfg := log.DefaultConfig()
+    cfg.FilePath = path
+    cfg.BufferSize = 64 * 1024
+    cfg.FlushInterval = 0
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    defer l.(*log.LogrusLogger).Close()
+
+    read := func() string {
+        b, err := os.ReadFile(path)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return string(b)
+    }
+
+    l.Infof("info before")
+    if got := read(); got != "" {
+        t.Fatalf("info written before flush: %q", got)
+    }
+
+    // Error 达到默认的 SyncFlushLevel，立即写入文件，且之前缓冲的日志先写出以保持顺序
+    l.Errorf("error line")
+    got := read()
+    before, errLine := strings.Index(got, "info before"), strings.Index(got, "error line")
+    if errLine < 0 || before < 0 || before > errLine {
+        t.Fatalf("file content after error = %q", got)
+    }
+
+    l.Infof("info after")
+    if strings.Contains(read(), "info after") {
+        t.Error("info after error should remain buffered")
+    }
+    if err := l.(*log.LogrusLogger).Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if !strings.Contains(read(), "info after") {
+        t.Error("buffered line lost on Close")
+    }
+}
+
+func TestFatalExitCode(t *testing.T) {
+    var out syncBuffer
+    var exitCode int
+    cfg := log.DefaultConfig()
+    cfg.Output = &out
+    cfg.BufferSize = 64 * 1024
+    cfg.FlushInterval = 0
+    cfg.SyncFlushLevel = logrus.PanicLevel // 让 Fatal 日志也进入缓冲区，验证退出前会写出
+    cfg.FatalExitCode = 3
+    cfg.ExitFunc = func(code int) {
+        exitCode = code
+        // 退出时缓冲区必须已写出
+        if !strings.Contains(out.String(), `"msg":"shutting down"`) {
+            t.Errorf("fatal line not flushed before exit: %q", out.String())
+        }
+    }
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    defer l.(*log.LogrusLogger).Close()
+
+    l.Infof("buffered")
+    l.Fatalf("shutting down")
+    if exitCode != 3 {
+        t.Errorf("exit code = %d, want 3", exitCode)
+    }
+    if !strings.Contains(out.String(), "buffered") {
+        t.Errorf("earlier line lost: %q", out.String())
+    }
+}
+
+func TestBufferedWriterStats(t *testing.T) {
+    w := &failingWriter{}
+    cfg := log.DefaultConfig()
+    cfg.Output = w
+    cfg.BufferSize = 64 * 1024
+    cfg.FlushInterval = 0
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    defer l.(*log.LogrusLogger).Close()
+    bl := l.(*log.LogrusLogger)
+
+    bl.Infof("one")
+    bl.Infof("two")
+    if s := bl.WriterStats(); s.Enqueued != 2 || s.Pending != 2 || s.Flushed != 0 {
+        t.Errorf("stats before flush = %+v", s)
+    }
+    if err := bl.Flush(); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if s := bl.WriterStats(); s.Flushed != 2 || s.Pending != 0 || s.Dropped != 0 {
+        t.Errorf("stats after flush = %+v", s)
+    }
+
+    // 写出失败时缓冲中的日志被丢弃
+    w.fail = true
+    bl.Infof("three")
+    bl.Infof("four")
+    bl.Infof("five")
+    if err := bl.Flush(); !errors.Is(err, errDiskFull) {
+        t.Fatalf("Flush error = %v, want disk full", err)
+    }
+    s := bl.WriterStats()
+    if s.Enqueued != 5 || s.Flushed != 2 || s.Dropped != 3 || s.Errors != 1 || s.Pending != 0 {
+        t.Errorf("stats after failed flush = %+v", s)
+    }
+}
+
+func TestSync(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "sync.log")
+    cfg := log.DefaultConfig()
+    cfg.FilePath = path
+    cfg.BufferSize = 64 * 1024
+    cfg.FlushInterval = 0
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    defer l.(*log.LogrusLogger).Close()
+
+    read := func() string {
+        data, err := os.ReadFile(path)
+        if err != nil {
+            t.Fatalf("ReadFile: %v", err)
+        }
+        return string(data)
+    }
+
+    l.Infof("before sync")
+    if strings.Contains(read(), "before sync") {
+        t.Fatal("entry should still be buffered")
+    }
+    if err := l.(*log.LogrusLogger).Sync(); err != nil {
+        t.Fatalf("Sync: %v", err)
+    }
+    if !strings.Contains(read(), "before sync") {
+        t.Fatal("Sync did not persist buffered entry")
+    }
+
+    // Sync 之后仍可继续记录日志
+    l.Infof("after sync")
+    if err := l.(*log.LogrusLogger).Sync(); err != nil {
+        t.Fatalf("second Sync: %v", err)
+    }
+    if !strings.Contains(read(), "after sync") {
+        t.Error("logging after Sync was not persisted")
+    }
+}
+
+// blockingWriter 的 Write 一直阻塞到 release 被关闭
+type blockingWriter struct {
+    release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+    <-w.release
+    return len(p), nil
+}
+
+func TestCloseWithTimeout(t *testing.T) {
+    sink := &blockingWriter{release: make(chan struct{})}
+    defer close(sink.release)
+
+    cfg := log.DefaultConfig()
+    cfg.Output = sink
+    cfg.BufferSize = 64 * 1024
+    cfg.FlushInterval = 0
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    l.Infof("never delivered")
+
+    start := time.Now()
+    err = l.(*log.LogrusLogger).CloseWithTimeout(50 * time.Millisecond)
+    if !errors.Is(err, log.ErrCloseTimeout) {
+        t.Errorf("CloseWithTimeout error = %v, want ErrCloseTimeout", err)
+    }
+    if elapsed := time.Since(start); elapsed > time.Second {
+        t.Errorf("CloseWithTimeout took %v", elapsed)
+    }
+
+    // 输出正常时与 Close 相同
+    var out syncBuffer
+    l = newBufferedLogger(t, &out, 0)
+    l.Infof("delivered")
+    if err := l.(*log.LogrusLogger).CloseWithTimeout(time.Second); err != nil {
+        t.Fatalf("CloseWithTimeout: %v", err)
+    }
+    if !strings.Contains(out.String(), "delivered") {
+        t.Errorf("buffered entry not flushed: %q", out.String())
+    }
+}
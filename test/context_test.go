@@ -0,0 +1,775 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "reflect"
+    "regexp"
+    "runtime"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sirupsen/logrus"
+)
+
+func TestHeaderPropagation(t *testing.T) {
+    ctx := log.WithRequestID(context.Background(), "req-1")
+    ctx = log.WithTraceID(ctx, "trace-1")
+
+    h := http.Header{}
+    log.InjectHeaders(ctx, h)
+    if h.Get(log.HeaderRequestID) != "req-1" || h.Get(log.HeaderTraceID) != "trace-1" {
+        t.Fatalf("headers = %v", h)
+    }
+    if h.Get(log.HeaderSpanID) != "" {
+        t.Errorf("span header should not be set: %v", h)
+    }
+
+    got := log.ExtractHeaders(context.Background(), h)
+    if v, _ := log.GetRequestID(got); v != "req-1" {
+        t.Errorf("request id = %q, want req-1", v)
+    }
+    if v, _ := log.GetTraceID(got); v != "trace-1" {
+        t.Errorf("trace id = %q, want trace-1", v)
+    }
+    if _, ok := log.GetSpanID(got); ok {
+        t.Errorf("span id should be absent")
+    }
+    // 提取的请求 ID 与 WithRequestID 一样可以派生从 1 开始的子 ID
+    if v, _ := log.GetRequestID(log.WithChildRequestID(got)); v != "req-1.1" {
+        t.Errorf("child request id = %q, want req-1.1", v)
+    }
+
+    // 客户端可伪造的 X-User-ID 只通过 ExtractUserIDHeader 显式读取
+    h.Set(log.HeaderUserID, "42")
+    if _, ok := log.GetUserID(log.ExtractHeaders(context.Background(), h)); ok {
+        t.Error("ExtractHeaders must not trust X-User-ID")
+    }
+    if v, _ := log.GetUserID(log.ExtractUserIDHeader(context.Background(), h)); v != "42" {
+        t.Errorf("user id = %q, want 42", v)
+    }
+}
+
+func TestScopeFields(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    ctx := log.WithScopeField(context.Background(), "job", "import")
+    ctx = log.WithScopeField(ctx, "step", "parse")
+    l.InfoContextf(ctx, "in scope")
+    m := decodeLine(t, &buf)
+    if m["job"] != "import" || m["step"] != "parse" {
+        t.Errorf("scope fields missing: %v", m)
+    }
+
+    // 弹出最近的字段
+    popped := log.PopScopeField(ctx)
+    l.InfoContextf(popped, "after pop")
+    m = decodeLine(t, &buf)
+    if m["job"] != "import" || m["step"] != nil {
+        t.Errorf("after pop: %v", m)
+    }
+
+    // 清空作用域
+    l.InfoContextf(log.ClearScope(ctx), "after clear")
+    m = decodeLine(t, &buf)
+    if m["job"] != nil || m["step"] != nil {
+        t.Errorf("after clear: %v", m)
+    }
+
+    // 内层同名字段优先
+    inner := log.WithScopeField(ctx, "job", "export")
+    l.InfoContextf(inner, "shadowed")
+    if m = decodeLine(t, &buf); m["job"] != "export" {
+        t.Errorf("job = %v, want export", m["job"])
+    }
+}
+
+func TestWithOperation(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    ctx := log.WithOperation(context.Background(), "sync")
+    l.InfoContextf(ctx, "outer")
+    if m := decodeLine(t, &buf); m["operation"] != "sync" {
+        t.Errorf("operation = %v, want sync", m["operation"])
+    }
+
+    inner := log.WithOperation(log.WithOperation(ctx, "fetch"), "page")
+    l.InfoContextf(inner, "inner")
+    if m := decodeLine(t, &buf); m["operation"] != "sync.fetch.page" {
+        t.Errorf("operation = %v, want sync.fetch.page", m["operation"])
+    }
+
+    // 父 Context 不受子操作影响
+    if op, _ := log.GetOperation(ctx); op != "sync" {
+        t.Errorf("parent operation = %q, want sync", op)
+    }
+}
+
+type tenantKey struct{}
+
+func TestContextExtractors(t *testing.T) {
+    tenant := func(ctx context.Context) (any, bool) {
+        v, ok := ctx.Value(tenantKey{}).(string)
+        return v, ok
+    }
+    region := func(ctx context.Context) (any, bool) { return "eu-west", true }
+
+    var bufA, bufB bytes.Buffer
+    a := newJSONLogger(t, &bufA, func(c *log.Config) {
+        c.ContextExtractors = map[string]log.ContextExtractor{"tenant": tenant}
+    })
+    b := newJSONLogger(t, &bufB, func(c *log.Config) {
+        c.ContextExtractors = map[string]log.ContextExtractor{"region": region}
+    })
+
+    ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+    a.InfoContextf(ctx, "a")
+    b.InfoContextf(ctx, "b")
+
+    ma, mb := decodeLine(t, &bufA), decodeLine(t, &bufB)
+    if ma["tenant"] != "acme" || ma["region"] != nil {
+        t.Errorf("logger a fields: %v", ma)
+    }
+    if mb["region"] != "eu-west" || mb["tenant"] != nil {
+        t.Errorf("logger b fields: %v", mb)
+    }
+
+    // 提取失败时不添加字段
+    a.InfoContextf(context.Background(), "no tenant")
+    if m := decodeLine(t, &bufA); m["tenant"] != nil {
+        t.Errorf("tenant should be absent: %v", m)
+    }
+
+    // 全局提取器对所有 Logger 生效，同名时 Logger 自身的提取器优先
+    log.RegisterContextExtractor("region", func(context.Context) (any, bool) { return "global", true })
+    defer log.UnregisterContextExtractor("region")
+    a.InfoContextf(ctx, "a")
+    b.InfoContextf(ctx, "b")
+    if m := decodeLine(t, &bufA); m["region"] != "global" {
+        t.Errorf("logger a region = %v, want global", m["region"])
+    }
+    if m := decodeLine(t, &bufB); m["region"] != "eu-west" {
+        t.Errorf("logger b region = %v, want eu-west", m["region"])
+    }
+}
+
+func TestWithChildRequestID(t *testing.T) {
+    ctx := log.WithRequestID(context.Background(), "req-123")
+
+    c1, _ := log.GetRequestID(log.WithChildRequestID(ctx))
+    c2ctx := log.WithChildRequestID(ctx)
+    c2, _ := log.GetRequestID(c2ctx)
+    if c1 != "req-123.1" || c2 != "req-123.2" {
+        t.Errorf("children = %q, %q, want req-123.1, req-123.2", c1, c2)
+    }
+
+    // 子 ID 可继续派生，且不影响父级计数
+    grand, _ := log.GetRequestID(log.WithChildRequestID(c2ctx))
+    if grand != "req-123.2.1" {
+        t.Errorf("grandchild = %q, want req-123.2.1", grand)
+    }
+    if c3, _ := log.GetRequestID(log.WithChildRequestID(ctx)); c3 != "req-123.3" {
+        t.Errorf("third child = %q, want req-123.3", c3)
+    }
+
+    // 没有父 ID 时生成新的 ID
+    a, _ := log.GetRequestID(log.WithChildRequestID(context.Background()))
+    b, _ := log.GetRequestID(log.WithChildRequestID(context.Background()))
+    if a == "" || a == b {
+        t.Errorf("fresh ids = %q, %q, want distinct non-empty", a, b)
+    }
+
+    // 直接设置的请求 ID 没有计数器，子 ID 仍以父 ID 为前缀且互不相同
+    raw := context.WithValue(context.Background(), log.RequestIDKey, "raw")
+    r1, _ := log.GetRequestID(log.WithChildRequestID(raw))
+    r2, _ := log.GetRequestID(log.WithChildRequestID(raw))
+    if !strings.HasPrefix(r1, "raw.") || !strings.HasPrefix(r2, "raw.") || r1 == r2 {
+        t.Errorf("raw children = %q, %q", r1, r2)
+    }
+}
+
+func TestWithSeverity(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.DefaultSeverity = log.SeverityInfo })
+
+    ctx := log.WithSeverity(context.Background(), log.SeverityNotice)
+    l.InfoContextf(ctx, "escalated")
+    if m := decodeLine(t, &buf); m["severity"] != "notice" || m["level"] != "info" {
+        t.Errorf("severity/level = %v/%v, want notice/info", m["severity"], m["level"])
+    }
+
+    ctx = log.WithSeverity(context.Background(), log.SeverityCritical)
+    l.DebugContextf(ctx, "critical but debug")
+    if m := decodeLine(t, &buf); m["severity"] != "critical" || m["level"] != "debug" {
+        t.Errorf("severity/level = %v/%v, want critical/debug", m["severity"], m["level"])
+    }
+
+    // 未指定时使用配置的默认值
+    l.Warnf("default")
+    if m := decodeLine(t, &buf); m["severity"] != "info" || m["level"] != "warning" {
+        t.Errorf("severity/level = %v/%v, want info/warning", m["severity"], m["level"])
+    }
+}
+
+func TestSQLComment(t *testing.T) {
+    if got := log.SQLComment(context.Background()); got != "" {
+        t.Errorf("comment without ids = %q, want empty", got)
+    }
+
+    ctx := log.WithRequestID(context.Background(), "req 1")
+    ctx = log.WithTraceID(ctx, "4BF92F3577B34DA6A3CE929D0E0E4736")
+    ctx = log.WithSpanID(ctx, "00f067aa0ba902b7")
+    want := "/*request_id='req%201',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/"
+    if got := log.SQLComment(ctx); got != want {
+        t.Errorf("comment = %q, want %q", got, want)
+    }
+
+    // 非 W3C 格式的 ID 原样输出，特殊字符被编码
+    ctx = log.WithTraceID(context.Background(), "trace-'x'*/")
+    if got := log.SQLComment(ctx); got != "/*trace_id='trace-%27x%27%2A%2F'*/" {
+        t.Errorf("comment = %q", got)
+    }
+}
+
+func TestWithJobContext(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    pool, cancel := context.WithCancel(context.Background())
+    pool = log.WithCustomField(pool, "pool", "workers")
+    pool = log.WithCustomField(pool, "shared", "pool")
+    pool = log.WithScopeField(pool, "worker", 3)
+    pool = log.WithRequestID(pool, "pool-req")
+
+    job := log.WithRequestID(context.Background(), "job-req")
+    job = log.WithTraceID(job, "job-trace")
+    job = log.WithCustomField(job, "shared", "job")
+    job = log.WithScopeField(job, "job", "import")
+
+    ctx := log.WithJobContext(pool, job)
+    l.InfoContextf(ctx, "processing")
+    m := decodeLine(t, &buf)
+    want := map[string]any{
+        "request_id": "job-req",
+        "trace_id":   "job-trace",
+        "pool":       "workers",
+        "shared":     "job",
+        "worker":     float64(3),
+        "job":        "import",
+    }
+    for k, v := range want {
+        if m[k] != v {
+            t.Errorf("%s = %v, want %v", k, m[k], v)
+        }
+    }
+
+    // 生命周期仍由 pool 决定
+    cancel()
+    if ctx.Err() == nil {
+        t.Error("merged context should be canceled with the pool context")
+    }
+    // 子请求 ID 沿用 job 的计数
+    if id, _ := log.GetRequestID(log.WithChildRequestID(ctx)); id != "job-req.1" {
+        t.Errorf("child id = %q, want job-req.1", id)
+    }
+}
+
+func TestWithForcedLevel(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.Level = logrus.InfoLevel })
+
+    l.DebugContextf(context.Background(), "suppressed")
+    if buf.Len() != 0 {
+        t.Fatalf("debug line emitted without forced level: %q", buf.String())
+    }
+
+    forced := log.WithForcedLevel(context.Background(), logrus.DebugLevel)
+    l.DebugContextf(forced, "forced debug")
+    m := decodeLine(t, &buf)
+    if m["msg"] != "forced debug" || m["level"] != "debug" {
+        t.Errorf("forced entry = %v", m)
+    }
+    if _, ok := m["__forced_level"]; ok {
+        t.Errorf("internal field leaked: %v", m)
+    }
+
+    // 强制级别只会放宽，不会屏蔽原本输出的日志
+    quiet := log.WithForcedLevel(context.Background(), logrus.ErrorLevel)
+    l.InfoContextf(quiet, "still info")
+    if m := decodeLine(t, &buf); m["msg"] != "still info" {
+        t.Errorf("info suppressed by forced level: %v", m)
+    }
+    l.DebugContextf(quiet, "not forced")
+    if buf.Len() != 0 {
+        t.Errorf("debug emitted with forced error level: %q", buf.String())
+    }
+}
+
+func TestFieldPrefix(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.FieldPrefix = "app." })
+
+    ctx := log.WithRequestID(context.Background(), "req-1")
+    ctx = log.WithTraceID(ctx, "trace-1")
+    ctx = log.WithCustomField(ctx, "tenant", "acme")
+    l.WithField("order", 42).InfoContextf(ctx, "prefixed")
+
+    m := decodeLine(t, &buf)
+    if m["app.request_id"] != "req-1" || m["app.trace_id"] != "trace-1" || m["app.tenant"] != "acme" {
+        t.Errorf("context fields not prefixed: %v", m)
+    }
+    if _, ok := m["request_id"]; ok {
+        t.Errorf("unprefixed request_id present: %v", m)
+    }
+    if m["order"] != float64(42) {
+        t.Errorf("explicit field should keep its key: %v", m)
+    }
+    for _, k := range []string{"time", "level", "msg", "file", "func"} {
+        if _, ok := m[k]; !ok {
+            t.Errorf("core key %q missing: %v", k, m)
+        }
+    }
+
+    // 显式字段同样添加前缀
+    buf.Reset()
+    l = newJSONLogger(t, &buf, func(c *log.Config) {
+        c.FieldPrefix = "app."
+        c.PrefixExplicitFields = true
+    })
+    l.WithField("order", 42).InfoContextf(ctx, "prefixed")
+    if m = decodeLine(t, &buf); m["app.order"] != float64(42) || m["app.user_id"] != nil {
+        t.Errorf("explicit field not prefixed: %v", m)
+    }
+}
+
+func TestRequestBuffer(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    // 请求成功: 缓冲的 Debug/Info 日志被丢弃，Warn 立即输出
+    ctx := log.BeginRequestBuffer(log.WithRequestID(context.Background(), "req-ok"))
+    l.DebugContextf(ctx, "debug detail")
+    l.InfoContextf(ctx, "info detail")
+    l.WarnContextf(ctx, "slow dependency")
+    log.FlushIfError(ctx, nil)
+    lines := decodeLines(t, &buf)
+    if len(lines) != 1 || lines[0]["msg"] != "slow dependency" {
+        t.Fatalf("success path lines = %v", lines)
+    }
+
+    // 请求失败: 缓冲的日志按原顺序输出，并保留记录时的调用者
+    ctx = log.BeginRequestBuffer(log.WithRequestID(context.Background(), "req-fail"))
+    _, _, line, _ := runtime.Caller(0)
+    l.DebugContextf(ctx, "step %d", 1)
+    l.InfoContextf(ctx, "step %d", 2)
+    l.ErrorContextf(ctx, "failed")
+    log.FlushIfError(ctx, errors.New("boom"))
+
+    lines = decodeLines(t, &buf)
+    if len(lines) != 3 {
+        t.Fatalf("failure path lines = %v", lines)
+    }
+    if lines[0]["msg"] != "failed" || lines[1]["msg"] != "step 1" || lines[2]["msg"] != "step 2" {
+        t.Errorf("unexpected order: %v", lines)
+    }
+    if lines[1]["level"] != "debug" || lines[1]["request_id"] != "req-fail" {
+        t.Errorf("buffered entry = %v", lines[1])
+    }
+    if want := fmt.Sprintf("context_test.go:%d", line+1); !strings.HasSuffix(lines[1]["file"].(string), want) {
+        t.Errorf("file = %v, want suffix %s", lines[1]["file"], want)
+    }
+
+    // 缓冲已清空，再次 Flush 不会重复输出
+    log.FlushIfError(ctx, errors.New("boom"))
+    if buf.Len() != 0 {
+        t.Errorf("records flushed twice: %q", buf.String())
+    }
+}
+
+func TestBaggageAllowlist(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.BaggageAllowlist = []string{"tenant", "plan"} })
+
+    ctx := log.WithBaggage(context.Background(), map[string]string{"tenant": "acme", "session": "s-123"})
+    ctx = log.WithBaggage(ctx, map[string]string{"plan": "pro", "tenant": "globex"})
+    l.InfoContextf(ctx, "with baggage")
+
+    m := decodeLine(t, &buf)
+    if m["tenant"] != "globex" || m["plan"] != "pro" {
+        t.Errorf("allowlisted baggage missing: %v", m)
+    }
+    if _, ok := m["session"]; ok {
+        t.Errorf("non-allowlisted baggage leaked: %v", m)
+    }
+    if all, _ := log.GetBaggage(ctx); len(all) != 3 {
+        t.Errorf("baggage = %v, want 3 keys", all)
+    }
+
+    // 未配置白名单时不输出任何 baggage
+    plain := newJSONLogger(t, &buf)
+    plain.InfoContextf(ctx, "no allowlist")
+    if m := decodeLine(t, &buf); m["tenant"] != nil || m["plan"] != nil {
+        t.Errorf("baggage should be hidden without allowlist: %v", m)
+    }
+}
+
+func TestWithoutFields(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    parent := log.WithUserID(context.Background(), "u-1")
+    parent = log.WithRequestID(parent, "req-1")
+    parent = log.WithCustomField(parent, "tenant", "acme")
+    parent = log.WithCustomField(parent, "email", "a@example.com")
+    parent = log.WithScopeField(parent, "step", "load")
+    parent = log.WithScopeField(parent, "email", "scoped@example.com")
+
+    child := log.WithoutCustomField(parent, "tenant")
+    l.InfoContextf(child, "custom dropped")
+    if m := decodeLine(t, &buf); m["tenant"] != nil || m["user_id"] != "u-1" || m["email"] != "scoped@example.com" {
+        t.Errorf("WithoutCustomField output = %v", m)
+    }
+
+    child = log.WithoutFields(parent, "user_id", "email")
+    l.InfoContextf(child, "fields dropped")
+    m := decodeLine(t, &buf)
+    if m["user_id"] != nil || m["email"] != nil {
+        t.Errorf("dropped fields still present: %v", m)
+    }
+    if m["request_id"] != "req-1" || m["tenant"] != "acme" || m["step"] != "load" {
+        t.Errorf("other fields should remain: %v", m)
+    }
+
+    // 父 Context 不受影响
+    l.InfoContextf(parent, "parent")
+    if m := decodeLine(t, &buf); m["user_id"] != "u-1" || m["tenant"] != "acme" || m["email"] != "scoped@example.com" {
+        t.Errorf("parent fields changed: %v", m)
+    }
+}
+
+func TestRequestSampler(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    handle := func(reqID string) context.Context {
+        ctx := log.WithRequestSampler(log.WithRequestID(context.Background(), reqID), 3)
+        for i := 0; i < 5; i++ {
+            l.WarnContextf(ctx, "retrying upstream, attempt %d", i)
+            l.InfoContextf(ctx, "cache miss")
+            l.ErrorContextf(ctx, "upstream failed")
+        }
+        return ctx
+    }
+    count := func(lines []map[string]any, reqID, msgPrefix string) int {
+        n := 0
+        for _, m := range lines {
+            if m["request_id"] == reqID && strings.HasPrefix(m["msg"].(string), msgPrefix) {
+                n++
+            }
+        }
+        return n
+    }
+
+    ctx1 := handle("req-1")
+    ctx2 := handle("req-2")
+    lines := decodeLines(t, &buf)
+    for _, reqID := range []string{"req-1", "req-2"} {
+        if n := count(lines, reqID, "retrying"); n != 3 {
+            t.Errorf("%s: %d warnings, want 3 per request", reqID, n)
+        }
+        if n := count(lines, reqID, "cache miss"); n != 3 {
+            t.Errorf("%s: %d infos, want 3 per request", reqID, n)
+        }
+        if n := count(lines, reqID, "upstream failed"); n != 5 {
+            t.Errorf("%s: %d errors, want 5 (errors are not limited)", reqID, n)
+        }
+    }
+    if n := log.RequestSampleSuppressed(ctx1); n != 4 {
+        t.Errorf("suppressed in req-1 = %d, want 4", n)
+    }
+    if n := log.RequestSampleSuppressed(ctx2); n != 4 {
+        t.Errorf("suppressed in req-2 = %d, want 4", n)
+    }
+
+    // 没有采样器的 Context 不受限制
+    for i := 0; i < 5; i++ {
+        l.WarnContextf(context.Background(), "retrying upstream, attempt %d", i)
+    }
+    if n := len(decodeLines(t, &buf)); n != 5 {
+        t.Errorf("unsampled context emitted %d lines, want 5", n)
+    }
+}
+
+func TestCardinalityLimit(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    ctx := log.WithCardinalityLimit(context.Background(), 2)
+    for _, key := range []string{"a", "b", "c", "d", "c"} {
+        ctx = log.WithCustomField(ctx, key, key)
+    }
+    ctx = log.WithCustomField(ctx, "a", "updated")
+
+    fields, _ := log.GetCustomFields(ctx)
+    if len(fields) != 2 || fields["a"] != "updated" || fields["b"] != "b" {
+        t.Errorf("custom fields = %v", fields)
+    }
+    if dropped, _ := log.GetDroppedFields(ctx); !reflect.DeepEqual(dropped, []string{"c", "d"}) {
+        t.Errorf("dropped = %v", dropped)
+    }
+
+    l.InfoContextf(ctx, "limited")
+    m := decodeLine(t, &buf)
+    if m["c"] != nil || m["d"] != nil || m["a"] != "updated" {
+        t.Errorf("output = %v", m)
+    }
+    if d, ok := m[log.DroppedFieldsFieldKey].([]any); !ok || len(d) != 2 || d[0] != "c" || d[1] != "d" {
+        t.Errorf("dropped_fields = %v", m[log.DroppedFieldsFieldKey])
+    }
+
+    // 已有字段超出上限时按字段名保留前 n 个
+    over := log.WithCustomField(log.WithCustomField(context.Background(), "y", 1), "x", 2)
+    over = log.WithCardinalityLimit(over, 1)
+    if fields, _ := log.GetCustomFields(over); len(fields) != 1 || fields["x"] != 2 {
+        t.Errorf("trimmed fields = %v", fields)
+    }
+    if dropped, _ := log.GetDroppedFields(over); !reflect.DeepEqual(dropped, []string{"y"}) {
+        t.Errorf("trimmed dropped = %v", dropped)
+    }
+
+    l.InfoContextf(log.WithCustomField(context.Background(), "free", 1), "unlimited")
+    if m := decodeLine(t, &buf); m[log.DroppedFieldsFieldKey] != nil {
+        t.Errorf("dropped_fields should be absent without a limit: %v", m)
+    }
+}
+
+func TestInfofWith(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.DefaultFields = map[string]any{"region": "eu"}
+    })
+
+    ctx := log.WithUserID(context.Background(), "u-1")
+    ctx = log.WithCustomField(ctx, "tenant", "acme")
+
+    l.InfofWith(ctx, map[string]any{"user_id": "impersonated", "tenant": "other", "region": "us"}, "as %s", "admin")
+    m := decodeLine(t, &buf)
+    if m["user_id"] != "impersonated" || m["tenant"] != "other" || m["region"] != "us" || m["msg"] != "as admin" {
+        t.Errorf("per-call fields should override context and default fields: %v", m)
+    }
+
+    // 只作用于单次调用
+    l.InfoContextf(ctx, "next")
+    if m := decodeLine(t, &buf); m["user_id"] != "u-1" || m["tenant"] != "acme" || m["region"] != "eu" {
+        t.Errorf("override leaked into subsequent entry: %v", m)
+    }
+
+    l.SetLevel(logrus.InfoLevel)
+    l.DebugfWith(ctx, map[string]any{"k": 1}, "filtered")
+    l.ErrorfWith(ctx, nil, "no fields")
+    if m := decodeLine(t, &buf); m["level"] != "error" || m["user_id"] != "u-1" {
+        t.Errorf("ErrorfWith output = %v", m)
+    }
+}
+
+func TestContextCauseExtractors(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.ContextExtractors = log.ContextCauseExtractors() })
+
+    errShutdown := errors.New("server shutting down")
+    ctx, cancel := context.WithCancelCause(context.Background())
+    l.InfoContextf(ctx, "running")
+    if m := decodeLine(t, &buf); m[log.ContextCauseFieldKey] != nil || m[log.ContextRemainingFieldKey] != nil {
+        t.Errorf("live context should not add cause fields: %v", m)
+    }
+    cancel(errShutdown)
+    l.WarnContextf(ctx, "aborted")
+    if m := decodeLine(t, &buf); m[log.ContextCauseFieldKey] != "server shutting down" || m[log.ContextRemainingFieldKey] != nil {
+        t.Errorf("cancelled context = %v", m)
+    }
+
+    deadlineCtx, cancelDeadline := context.WithTimeoutCause(context.Background(), time.Hour, errors.New("unused"))
+    defer cancelDeadline()
+    parent, cancelParent := context.WithCancelCause(deadlineCtx)
+    cancelParent(errShutdown)
+    l.ErrorContextf(parent, "aborted early")
+    m := decodeLine(t, &buf)
+    if m[log.ContextCauseFieldKey] != "server shutting down" {
+        t.Errorf("context_cause = %v", m[log.ContextCauseFieldKey])
+    }
+    if remaining, ok := m[log.ContextRemainingFieldKey].(float64); !ok || time.Duration(remaining) <= 59*time.Minute {
+        t.Errorf("context_remaining = %v", m[log.ContextRemainingFieldKey])
+    }
+
+    expired, cancelExpired := context.WithTimeout(context.Background(), -time.Second)
+    defer cancelExpired()
+    l.ErrorContextf(expired, "timed out")
+    m = decodeLine(t, &buf)
+    if m[log.ContextCauseFieldKey] != context.DeadlineExceeded.Error() {
+        t.Errorf("context_cause = %v", m[log.ContextCauseFieldKey])
+    }
+    if remaining, ok := m[log.ContextRemainingFieldKey].(float64); !ok || remaining >= 0 {
+        t.Errorf("context_remaining should be negative: %v", m[log.ContextRemainingFieldKey])
+    }
+}
+
+func TestIDGenerator(t *testing.T) {
+    uuidRe := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+    for i := 0; i < 100; i++ {
+        if id := (log.UUIDv4Generator{}).NewID(); !uuidRe.MatchString(id) {
+            t.Fatalf("UUIDv4 %q does not match", id)
+        }
+    }
+
+    ulidRe := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+    gen := log.NewULIDGenerator()
+    prev := ""
+    sameMillis := 0
+    for i := 0; i < 1000; i++ {
+        id := gen.NewID()
+        if !ulidRe.MatchString(id) {
+            t.Fatalf("ULID %q does not match", id)
+        }
+        if id <= prev {
+            t.Fatalf("ULIDs not monotonic: %q after %q", id, prev)
+        }
+        if prev != "" && id[:10] == prev[:10] {
+            sameMillis++
+        }
+        prev = id
+    }
+    if sameMillis == 0 {
+        t.Errorf("expected some ULIDs within the same millisecond")
+    }
+
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.IDGenerator = gen })
+    ctx := l.EnsureRequestID(context.Background())
+    if id, _ := log.GetRequestID(ctx); !ulidRe.MatchString(id) {
+        t.Errorf("EnsureRequestID = %q, want ULID", id)
+    }
+    existing := log.WithRequestID(context.Background(), "req-1")
+    if id, _ := log.GetRequestID(l.EnsureRequestID(existing)); id != "req-1" {
+        t.Errorf("existing request ID replaced: %q", id)
+    }
+}
+
+func TestMergeContexts(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.BaggageAllowlist = []string{"tier", "region"} })
+
+    server, cancel := context.WithCancel(context.Background())
+    server = log.WithRequestID(server, "req-server")
+    server = log.WithUserID(server, "u-server")
+    server = log.WithCustomField(server, "tenant", "acme")
+    server = log.WithCustomField(server, "route", "/callback")
+    server = log.WithScopeField(server, "step", "server")
+    server = log.WithBaggage(server, map[string]string{"tier": "gold", "region": "eu"})
+
+    client := log.WithRequestID(context.Background(), "req-client")
+    client = log.WithTraceID(client, "trace-client")
+    client = log.WithCustomField(client, "route", "/hook")
+    client = log.WithCustomField(client, "attempt", 2)
+    client = log.WithScopeField(client, "step", "client")
+    client = log.WithBaggage(client, map[string]string{"region": "us"})
+
+    merged := log.MergeContexts(server, client)
+    l.InfoContextf(merged, "merged")
+    m := decodeLine(t, &buf)
+    want := map[string]any{
+        "request_id": "req-client",   // b 优先
+        "user_id":    "u-server",     // 只在 a 中
+        "trace_id":   "trace-client", // 只在 b 中
+        "tenant":     "acme",
+        "route":      "/hook",
+        "attempt":    float64(2),
+        "step":       "client",
+        "tier":       "gold",
+        "region":     "us",
+    }
+    for k, v := range want {
+        if m[k] != v {
+            t.Errorf("%s = %v, want %v", k, m[k], v)
+        }
+    }
+
+    // 取消状态继承自 a
+    cancel()
+    if merged.Err() == nil {
+        t.Errorf("merged context should be cancelled with a")
+    }
+}
+
+type structAddress struct {
+    City string `json:"city"`
+    Zip  string `log:"postal_code,redact"`
+}
+
+type structOrder struct {
+    ID       int `log:"id"`
+    Customer string
+    Card     string `log:"redact"`
+    Internal string `log:"-"`
+    Address  structAddress
+    Billing  *structAddress
+    Created  time.Time `json:"created_at"`
+    note     string
+}
+
+func TestWithStruct(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+    created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+    order := &structOrder{
+        ID:       42,
+        Customer: "alice",
+        Card:     "4111111111111111",
+        Internal: "secret",
+        Address:  structAddress{City: "Paris", Zip: "75001"},
+        Created:  created,
+        note:     "unexported",
+    }
+
+    ctx := log.WithStruct(context.Background(), "order", order)
+    l.InfoContextf(ctx, "order placed")
+    m := decodeLine(t, &buf)
+    want := map[string]any{
+        "order.id":                  float64(42),
+        "order.Customer":            "alice",
+        "order.Card":                log.RedactedValue,
+        "order.Address.city":        "Paris",
+        "order.Address.postal_code": log.RedactedValue,
+        "order.created_at":          created.Format(time.RFC3339),
+    }
+    for k, v := range want {
+        if m[k] != v {
+            t.Errorf("%s = %v, want %v", k, m[k], v)
+        }
+    }
+    for _, k := range []string{"order.Internal", "order.note", "order.Address", "order.Address.Zip"} {
+        if _, ok := m[k]; ok {
+            t.Errorf("%s should not be logged: %v", k, m)
+        }
+    }
+    if v, ok := m["order.Billing"]; !ok || v != nil {
+        t.Errorf("nil nested pointer should be logged as null: %v", v)
+    }
+
+    // Logger 版本，无前缀
+    l.WithStruct("", structAddress{City: "Lyon", Zip: "69001"}).Infof("address")
+    if m := decodeLine(t, &buf); m["city"] != "Lyon" || m["postal_code"] != log.RedactedValue {
+        t.Errorf("WithStruct logger output = %v", m)
+    }
+
+    // 非结构体不添加字段
+    if got := log.WithStruct(context.Background(), "x", 42); got.Value(log.CustomFieldsKey) != nil {
+        t.Errorf("non-struct should not add fields")
+    }
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "strings"
+    "testing"
+
+    "golang.org/x/sync/errgroup"
+
+    "github.com/sapaude/go-shims/x/log"
+    logerrgroup "github.com/sapaude/go-shims/x/log/errgroup"
+)
+
+func TestErrgroupGo(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    ctx := log.WithTraceID(context.Background(), "trace-parent")
+    g, gctx := errgroup.WithContext(ctx)
+    logerrgroup.Go(gctx, g, func(ctx context.Context) error {
+        l.InfoContextf(ctx, "child work")
+        return nil
+    }, logerrgroup.WithLogger(l))
+    if err := g.Wait(); err != nil {
+        t.Fatalf("Wait: %v", err)
+    }
+    if m := decodeLine(t, &buf); m["msg"] != "child work" || m["trace_id"] != "trace-parent" {
+        t.Errorf("child log = %v", m)
+    }
+
+    // panic 被恢复并记录，Wait 返回 *PanicError
+    g, gctx = errgroup.WithContext(ctx)
+    logerrgroup.Go(gctx, g, func(ctx context.Context) error {
+        panic("boom")
+    }, logerrgroup.WithLogger(l))
+    err := g.Wait()
+    var perr *logerrgroup.PanicError
+    if !errors.As(err, &perr) || perr.Value != "boom" {
+        t.Fatalf("Wait error = %v, want PanicError", err)
+    }
+    m := decodeLine(t, &buf)
+    if m["level"] != "error" || m["msg"] != "goroutine panic: boom" || m["trace_id"] != "trace-parent" {
+        t.Errorf("panic log = %v", m)
+    }
+    if stack, _ := m[logerrgroup.PanicStackFieldKey].(string); !strings.Contains(stack, "TestErrgroupGo") {
+        t.Errorf("panic stack missing caller: %q", stack)
+    }
+}
@@ -0,0 +1,999 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "reflect"
+    "regexp"
+    "slices"
+    "sort"
+    "strings"
+    "testing"
+    "time"
+    "unicode/utf8"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sirupsen/logrus"
+)
+
+// newTextLogger 创建一个输出到 buf 的文本 Logger
+func newTextLogger(t *testing.T, buf *bytes.Buffer, opts ...func(*log.Config)) *log.LogrusLogger {
+    t.Helper()
+    cfg := log.DefaultConfig()
+    cfg.Output = buf
+    cfg.Format = log.FormatText
+    cfg.Level = logrus.DebugLevel
+    cfg.ReportCaller = false
+    for _, opt := range opts {
+        opt(&cfg)
+    }
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    return l.(*log.LogrusLogger)
+}
+
+func TestTextLayout(t *testing.T) {
+    var buf bytes.Buffer
+    l := newTextLogger(t, &buf, func(cfg *log.Config) {
+        cfg.TextLayout = "{level} | {time} | {msg} | {fields}"
+        cfg.TimestampFormat = "2006-01-02"
+    })
+    l.WithFields(map[string]any{"b": 2, "a": "x y"}).Warnf("hello")
+
+    want := regexp.MustCompile(`^WARNING \| \d{4}-\d{2}-\d{2} \| hello \| a="x y" b=2\n$`)
+    if !want.MatchString(buf.String()) {
+        t.Errorf("output = %q", buf.String())
+    }
+
+    buf.Reset()
+    l2 := newTextLogger(t, &buf, func(cfg *log.Config) {
+        cfg.TextLayout = "[{level}] {msg}"
+    })
+    l2.Infof("plain")
+    if buf.String() != "[INFO] plain\n" {
+        t.Errorf("output = %q", buf.String())
+    }
+}
+
+func TestTextLayoutValidation(t *testing.T) {
+    for _, layout := range []string{"{msg", "{level} {message}", "{time} {level}"} {
+        cfg := log.DefaultConfig()
+        cfg.Format = log.FormatText
+        cfg.TextLayout = layout
+        if _, err := log.NewLogger(cfg); err == nil || !strings.Contains(err.Error(), "invalid text layout") {
+            t.Errorf("layout %q: err = %v, want invalid text layout", layout, err)
+        }
+    }
+}
+
+func TestAllowedFields(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.AllowedFields = []string{"request_id", "status"}
+    })
+    l.WithFields(map[string]any{"status": 200, "password": "hunter2"}).
+        InfoContextf(log.WithRequestID(context.Background(), "req-1"), "done")
+
+    m := decodeLine(t, &buf)
+    if m["request_id"] != "req-1" || m["status"] != float64(200) {
+        t.Errorf("allowed fields missing: %v", m)
+    }
+    for _, k := range []string{"password", "file", "func"} {
+        if _, ok := m[k]; ok {
+            t.Errorf("field %q should be dropped: %v", k, m)
+        }
+    }
+    for _, k := range []string{"time", "level", "msg"} {
+        if _, ok := m[k]; !ok {
+            t.Errorf("core field %q missing: %v", k, m)
+        }
+    }
+}
+
+func TestNormalizeTimeFields(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.NormalizeTimeFields = true
+        cfg.TimestampFormat = time.RFC3339
+    })
+    at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+    l.WithFields(map[string]any{"elapsed": 1500 * time.Microsecond, "at": at}).Infof("timed")
+
+    m := decodeLine(t, &buf)
+    if m["elapsed"] != 1.5 {
+        t.Errorf("elapsed = %#v, want 1.5", m["elapsed"])
+    }
+    if m["at"] != "2024-01-02T03:04:05Z" {
+        t.Errorf("at = %#v, want 2024-01-02T03:04:05Z", m["at"])
+    }
+}
+
+func TestFieldNameStyle(t *testing.T) {
+    cases := []struct {
+        style log.FieldNameStyle
+        want  []string
+    }{
+        {log.FieldNameStyleCamel, []string{"requestId", "httpStatus", "userId"}},
+        {log.FieldNameStyleSnake, []string{"request_id", "http_status", "user_id"}},
+        {log.FieldNameStyleKebab, []string{"request-id", "http-status", "user-id"}},
+    }
+    for _, c := range cases {
+        var buf bytes.Buffer
+        l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+            cfg.FieldNameStyle = c.style
+        })
+        l.WithFields(map[string]any{"HTTPStatus": 200, "userID": "u1"}).
+            InfoContextf(log.WithRequestID(context.Background(), "req-1"), "styled")
+        m := decodeLine(t, &buf)
+        for _, k := range c.want {
+            if _, ok := m[k]; !ok {
+                t.Errorf("%s: missing key %q in %v", c.style, k, m)
+            }
+        }
+    }
+
+    // FieldPrefix 与 '.' 保持原样，只转换各段
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.FieldNameStyle = log.FieldNameStyleCamel
+        cfg.FieldPrefix = "app_ctx."
+    })
+    l.WithField("http.status_code", 200).InfoContextf(log.WithRequestID(context.Background(), "req-1"), "prefixed")
+    m := decodeLine(t, &buf)
+    if m["app_ctx.requestId"] != "req-1" || m["http.statusCode"] != float64(200) {
+        t.Errorf("prefixed/dotted keys = %v", m)
+    }
+
+    // 转换后同名的字段不会互相覆盖，结果与 map 遍历顺序无关
+    for i := 0; i < 20; i++ {
+        l.WithFields(map[string]any{"userId": "a", "user_id": "b", "user-id": "c"}).Infof("collide")
+        m = decodeLine(t, &buf)
+        if m["userId"] != "a" || m["user_id"] != "b" || m["user-id"] != "c" {
+            t.Fatalf("colliding keys = %v", m)
+        }
+    }
+
+    cfg := log.DefaultConfig()
+    cfg.FieldNameStyle = "pascal"
+    if _, err := log.NewLogger(cfg); err == nil {
+        t.Errorf("NewLogger with invalid style should fail")
+    }
+}
+
+func TestPadLevels(t *testing.T) {
+    // 自定义布局
+    var buf bytes.Buffer
+    l := newTextLogger(t, &buf, func(cfg *log.Config) {
+        cfg.TextLayout = "{level}|{msg}"
+        cfg.PadLevels = true
+    })
+    l.Debugf("d")
+    l.Infof("i")
+    l.Warnf("w")
+    l.Errorf("e")
+    assertLevelColumn(t, buf.String(), "|")
+
+    // logrus 默认文本布局，级别标签位于颜色控制符之间
+    buf.Reset()
+    l = newTextLogger(t, &buf, func(cfg *log.Config) {
+        cfg.PadLevels = true
+    })
+    l.Infof("i")
+    l.Warnf("w")
+    l.Errorf("e")
+    assertLevelColumn(t, regexp.MustCompile(`\x1b\[\d+m`).ReplaceAllString(buf.String(), ""), "[")
+}
+
+// assertLevelColumn 断言每行中级别标签 (行首到 sep) 的宽度一致
+func assertLevelColumn(t *testing.T, out, sep string) {
+    t.Helper()
+    lines := strings.Split(strings.TrimSpace(out), "\n")
+    width := -1
+    for _, line := range lines {
+        w := strings.Index(line, sep)
+        if width == -1 {
+            width = w
+        }
+        if w != width || w <= 0 {
+            t.Errorf("level column width = %d, want %d in %q", w, width, line)
+        }
+    }
+}
+
+func FuzzSanitizeNewlines(f *testing.F) {
+    for _, seed := range []string{"plain", "line1\nline2", "fake\n{\"level\":\"error\"}", "\x1b[31mred\x1b[0m", "tab\there\r\n", "\xff\xfe"} {
+        f.Add(seed)
+    }
+    f.Fuzz(func(t *testing.T, value string) {
+        var buf bytes.Buffer
+        loggers := map[string]*log.LogrusLogger{
+            "text": newTextLogger(t, &buf),
+            "layout": newTextLogger(t, &buf, func(cfg *log.Config) {
+                cfg.TextLayout = "{time} {level} {msg} {fields}"
+            }),
+            "json": newJSONLogger(t, &buf),
+        }
+        for name, l := range loggers {
+            buf.Reset()
+            l.WithFields(map[string]any{"value": value, "key" + value: 1}).Infof("msg %s", value)
+            out := buf.String()
+            if strings.Count(out, "\n") != 1 || !strings.HasSuffix(out, "\n") {
+                t.Fatalf("%s output is not a single line: %q", name, out)
+            }
+            if name == "json" {
+                decodeLine(t, &buf)
+            }
+        }
+    })
+}
+
+// stackError 模拟带堆栈的错误 (如 pkg/errors)，%+v 输出多行堆栈
+type stackError struct {
+    msg string
+}
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+    if verb == 'v' && s.Flag('+') {
+        fmt.Fprintf(s, "%s\nmain.handler\n\t/app/handler.go:42", e.msg)
+        return
+    }
+    fmt.Fprint(s, e.msg)
+}
+
+func TestDevModeErrorRendering(t *testing.T) {
+    root := &stackError{msg: "connection refused"}
+    err := fmt.Errorf("query users: %w", root)
+
+    var buf bytes.Buffer
+    l := newTextLogger(t, &buf, func(cfg *log.Config) {
+        cfg.DevMode = true
+        cfg.TextLayout = "{level} {msg} {fields}"
+    })
+    l.WithField("error", err).Errorf("request failed")
+
+    lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+    want := []string{
+        `ERROR request failed error="query users: connection refused"`,
+        `    error: query users: connection refused`,
+        `        caused by: connection refused`,
+        `            main.handler`,
+        `            /app/handler.go:42`,
+    }
+    if len(lines) < len(want) {
+        t.Fatalf("output = %q", buf.String())
+    }
+    for i, w := range want {
+        if lines[i] != w {
+            t.Errorf("line %d = %q, want %q", i, lines[i], w)
+        }
+    }
+
+    // JSON 模式下错误折叠为单个字段
+    buf.Reset()
+    j := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.DevMode = true
+    })
+    j.WithField("error", err).Errorf("request failed")
+    if strings.Count(buf.String(), "\n") != 1 {
+        t.Errorf("JSON output should be a single line: %q", buf.String())
+    }
+    if m := decodeLine(t, &buf); m["error"] != "query users: connection refused" {
+        t.Errorf("error field = %v", m["error"])
+    }
+}
+
+func TestBytesEncoding(t *testing.T) {
+    payload := []byte("hello, world")
+
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+    l.WithField("payload", payload).Infof("base64")
+    if m := decodeLine(t, &buf); m["payload"] != "aGVsbG8sIHdvcmxk" {
+        t.Errorf("payload = %v, want base64", m["payload"])
+    }
+
+    l = newTextLogger(t, &buf, func(c *log.Config) { c.BytesEncoding = log.BytesEncodingHex })
+    l.WithField("payload", []byte{0xde, 0xad, 0xbe, 0xef}).Infof("hex")
+    if out := buf.String(); !strings.Contains(out, "=deadbeef") {
+        t.Errorf("hex payload missing: %q", out)
+    }
+    buf.Reset()
+
+    // 超过上限时截断并标明原始长度
+    l = newJSONLogger(t, &buf, func(c *log.Config) {
+        c.BytesEncoding = log.BytesEncodingHex
+        c.MaxBytesFieldLen = 2
+    })
+    l.WithField("payload", []byte{0xde, 0xad, 0xbe, 0xef}).Infof("truncated")
+    if m := decodeLine(t, &buf); m["payload"] != "dead...(4 bytes)" {
+        t.Errorf("payload = %v, want truncated hex", m["payload"])
+    }
+
+    cfg := log.DefaultConfig()
+    cfg.BytesEncoding = "base32"
+    if _, err := log.NewLogger(cfg); err == nil {
+        t.Error("expected error for unknown bytes encoding")
+    }
+}
+
+func TestSanitizeUTF8(t *testing.T) {
+    invalid := "bad\xff\xfeinput"
+    for _, format := range []log.LogFormat{log.FormatText, log.FormatJSON} {
+        var buf bytes.Buffer
+        l := newTextLogger(t, &buf, func(c *log.Config) {
+            c.Format = format
+            c.SanitizeUTF8 = true
+        })
+        l.WithFields(map[string]any{
+            "value":       invalid,
+            "err":         errors.New(invalid),
+            "key\xc3\x28": "v",
+        }).Infof("msg %s", invalid)
+
+        out := buf.String()
+        if !utf8.ValidString(out) {
+            t.Errorf("%s output is not valid UTF-8: %q", format, out)
+        }
+        if !strings.Contains(out, "bad�input") {
+            t.Errorf("%s output missing replacement character: %q", format, out)
+        }
+    }
+}
+
+// indentedJSON 的 MarshalJSON 返回带缩进与换行的 JSON
+type indentedJSON struct{}
+
+func (indentedJSON) MarshalJSON() ([]byte, error) {
+    return []byte("{\n  \"a\": [1,\n 2]\n}"), nil
+}
+
+func TestCompactJSON(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.CompactJSON = true })
+
+    for i := 0; i < 3; i++ {
+        l.WithFields(map[string]any{
+            "nested":    map[string]any{"list": []any{1, "two\nlines", map[string]any{"deep": true}}},
+            "marshaler": indentedJSON{},
+            "multiline": "line1\nline2\r\n",
+        }).Infof("entry %d\nwith newline", i)
+    }
+
+    out := buf.String()
+    if !strings.HasSuffix(out, "\n") {
+        t.Fatalf("output must end with a newline: %q", out)
+    }
+    lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+    if len(lines) != 3 {
+        t.Fatalf("got %d lines, want 3: %q", len(lines), out)
+    }
+    for _, line := range lines {
+        if line != strings.TrimSpace(line) || strings.Contains(line, "\r") {
+            t.Errorf("line has surrounding whitespace: %q", line)
+        }
+        if !json.Valid([]byte(line)) {
+            t.Errorf("line is not valid JSON: %q", line)
+        }
+        if strings.Contains(line, `"a": [`) {
+            t.Errorf("marshaler output not compacted: %q", line)
+        }
+    }
+
+    cfg := log.DefaultConfig()
+    cfg.CompactJSON = true
+    cfg.JSONPretty = true
+    if _, err := log.NewLogger(cfg); err == nil {
+        t.Error("expected error when CompactJSON and JSONPretty are both enabled")
+    }
+}
+
+// upperFormatter 以 "LEVEL|msg|k=v" 格式输出，用于测试自定义格式
+type upperFormatter struct{ prefix string }
+
+func (f *upperFormatter) Format(e *logrus.Entry) ([]byte, error) {
+    keys := make([]string, 0, len(e.Data))
+    for k := range e.Data {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    var b strings.Builder
+    b.WriteString(f.prefix + strings.ToUpper(e.Level.String()) + "|" + e.Message)
+    for _, k := range keys {
+        fmt.Fprintf(&b, "|%s=%v", k, e.Data[k])
+    }
+    b.WriteByte('\n')
+    return []byte(b.String()), nil
+}
+
+func TestRegisterFormatter(t *testing.T) {
+    const format log.LogFormat = "pipe"
+    log.RegisterFormatter(format, func(cfg log.Config) logrus.Formatter {
+        return &upperFormatter{prefix: cfg.TimestampFormat}
+    })
+
+    var buf bytes.Buffer
+    l := newTextLogger(t, &buf, func(c *log.Config) {
+        c.Format = format
+        c.TimestampFormat = ">"
+        c.FieldNameStyle = log.FieldNameStyleCamel
+    })
+    l.WithField("request_id", "r1").Infof("hello")
+    if got := buf.String(); got != ">INFO|hello|requestId=r1\n" {
+        t.Errorf("custom format output = %q", got)
+    }
+
+    // SetFormatter 同样可以按名称切换
+    buf.Reset()
+    l.SetFormatter(log.FormatJSON)
+    l.Infof("json")
+    if m := decodeLine(t, &buf); m["msg"] != "json" {
+        t.Errorf("json output = %v", m)
+    }
+    l.SetFormatter(format)
+    l.Warnf("back")
+    if got := buf.String(); got != ">WARNING|back\n" {
+        t.Errorf("output after SetFormatter = %q", got)
+    }
+
+    defer func() {
+        if recover() == nil {
+            t.Error("registering a built-in format should panic")
+        }
+    }()
+    log.RegisterFormatter(log.FormatJSON, func(log.Config) logrus.Formatter { return nil })
+}
+
+type credentials struct {
+    User     string `json:"user"`
+    Password string `log:"redact"`
+    Token    string `log:"-"`
+}
+
+type account struct {
+    ID    int
+    Creds *credentials `json:"creds"`
+}
+
+type plainStruct struct {
+    A int
+}
+
+func TestRespectStructTags(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.RespectStructTags = true })
+
+    creds := &credentials{User: "bob", Password: "hunter2", Token: "tok"}
+    l.WithFields(map[string]any{
+        "creds":   creds,
+        "account": account{ID: 7, Creds: creds},
+        "plain":   plainStruct{A: 1},
+    }).Infof("login")
+
+    out := buf.String()
+    if strings.Contains(out, "hunter2") || strings.Contains(out, "tok\"") {
+        t.Fatalf("secret leaked: %s", out)
+    }
+    var line map[string]any
+    if err := json.Unmarshal([]byte(out), &line); err != nil {
+        t.Fatal(err)
+    }
+    c, _ := line["creds"].(map[string]any)
+    if c["user"] != "bob" || c["Password"] != log.RedactedValue {
+        t.Errorf("creds = %v", c)
+    }
+    if _, ok := c["Token"]; ok {
+        t.Errorf("omitted field present: %v", c)
+    }
+    acc, _ := line["account"].(map[string]any)
+    if nested, _ := acc["creds"].(map[string]any); nested["Password"] != log.RedactedValue || acc["ID"] != float64(7) {
+        t.Errorf("account = %v", acc)
+    }
+    if p, _ := line["plain"].(map[string]any); p["A"] != float64(1) {
+        t.Errorf("plain = %v", line["plain"])
+    }
+
+    // 关闭选项时不做处理
+    buf.Reset()
+    l = newJSONLogger(t, &buf)
+    l.WithField("creds", creds).Infof("login")
+    if !strings.Contains(buf.String(), "hunter2") {
+        t.Errorf("struct tags applied while disabled: %s", buf.String())
+    }
+}
+
+type taggedUser struct {
+    Name     string
+    Password string `log:"redact"`
+    Token    string `json:"-"`
+    APIKey   string `json:"-" log:"api_key,redact"`
+}
+
+type team struct {
+    Members []taggedUser
+    ByName  map[string]*taggedUser
+}
+
+func TestRespectStructTagsContainers(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.RespectStructTags = true })
+
+    u := taggedUser{Name: "bob", Password: "pw", Token: "tok", APIKey: "key"}
+    l.WithFields(map[string]any{
+        "users":  []taggedUser{u},
+        "byName": map[string]taggedUser{"bob": u},
+        "array":  [1]*taggedUser{&u},
+        "any":    []any{u, "plain"},
+        "team":   team{Members: []taggedUser{u}, ByName: map[string]*taggedUser{"bob": &u}},
+        "names":  []string{"pw"},
+    }).Infof("containers")
+
+    out := buf.String()
+    // "pw" 只出现在未打标签的 names 中
+    if strings.Contains(out, "tok") || strings.Contains(out, `"key"`) || strings.Count(out, `"pw"`) != 1 {
+        t.Fatalf("secret leaked: %s", out)
+    }
+    var line map[string]any
+    if err := json.Unmarshal([]byte(out), &line); err != nil {
+        t.Fatal(err)
+    }
+    users, _ := line["users"].([]any)
+    if len(users) != 1 {
+        t.Fatalf("users = %v", line["users"])
+    }
+    want := map[string]any{"Name": "bob", "Password": log.RedactedValue, "api_key": log.RedactedValue}
+    if got := users[0]; !reflect.DeepEqual(got, want) {
+        t.Errorf("users[0] = %v, want %v", got, want)
+    }
+    if got := line["byName"].(map[string]any)["bob"]; !reflect.DeepEqual(got, want) {
+        t.Errorf("byName = %v", got)
+    }
+    if got := line["array"].([]any)[0]; !reflect.DeepEqual(got, want) {
+        t.Errorf("array = %v", got)
+    }
+    if got := line["any"].([]any); !reflect.DeepEqual(got[0], want) || got[1] != "plain" {
+        t.Errorf("any = %v", got)
+    }
+    tm, _ := line["team"].(map[string]any)
+    if got := tm["Members"].([]any)[0]; !reflect.DeepEqual(got, want) {
+        t.Errorf("team members = %v", got)
+    }
+    if got := tm["ByName"].(map[string]any)["bob"]; !reflect.DeepEqual(got, want) {
+        t.Errorf("team byName = %v", got)
+    }
+    // 不含带标签结构体的切片保持原样
+    if got := line["names"].([]any); len(got) != 1 || got[0] != "pw" {
+        t.Errorf("names = %v", got)
+    }
+}
+
+type cyclicNode struct {
+    Name string
+    Next *cyclicNode
+}
+
+func TestUnmarshalableFields(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    node := &cyclicNode{Name: "a"}
+    node.Next = node
+    l.WithFields(map[string]any{
+        "ch":   make(chan int),
+        "node": node,
+        "ok":   "kept",
+    }).Infof("with bad fields")
+
+    m := decodeLine(t, &buf)
+    if m["msg"] != "with bad fields" || m["ok"] != "kept" {
+        t.Fatalf("line not emitted intact: %v", m)
+    }
+    if m["ch"] != "<unmarshalable: chan int>" {
+        t.Errorf("ch = %v", m["ch"])
+    }
+    if m["node"] != "<unmarshalable: *test.cyclicNode>" {
+        t.Errorf("node = %v", m["node"])
+    }
+}
+
+func TestSetFormatterObject(t *testing.T) {
+    var buf bytes.Buffer
+    l := newTextLogger(t, &buf)
+
+    l.SetFormatterObject(&upperFormatter{prefix: "#"})
+    l.WithField("k", "v").Warnf("custom")
+    if got := buf.String(); got != "#WARNING|custom|k=v\n" {
+        t.Errorf("custom formatter output = %q", got)
+    }
+    if cfg := l.GetConfig(); cfg.Format != log.FormatCustom || cfg.EnableJSON {
+        t.Errorf("config after SetFormatterObject: format=%q json=%v", cfg.Format, cfg.EnableJSON)
+    }
+
+    // 切换回内置格式
+    buf.Reset()
+    l.SetFormatter(log.FormatJSON)
+    l.Infof("json")
+    if m := decodeLine(t, &buf); m["msg"] != "json" {
+        t.Errorf("json output = %v", m)
+    }
+}
+
+func TestChainFormatter(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.ReportCaller = false })
+
+    var order []string
+    stage := func(name string) log.EntryTransform {
+        return func(e *logrus.Entry) { order = append(order, name) }
+    }
+    l.SetFormatterObject(log.ChainFormatter(
+        &logrus.JSONFormatter{},
+        stage("first"),
+        log.RedactFields("password", "token"),
+        func(e *logrus.Entry) { e.Data["stage"] = "custom" },
+        stage("last"),
+    ))
+
+    l.WithFields(map[string]any{"user": "bob", "password": "hunter2"}).Infof("login")
+    m := decodeLine(t, &buf)
+    if m["password"] != log.RedactedValue || m["user"] != "bob" || m["stage"] != "custom" {
+        t.Errorf("chained output = %v", m)
+    }
+    if _, ok := m["token"]; ok {
+        t.Errorf("absent field should not be added: %v", m)
+    }
+    if strings.Join(order, ",") != "first,last" {
+        t.Errorf("transform order = %v", order)
+    }
+
+    // AllowFields 同样可以作为一级变换
+    buf.Reset()
+    l.SetFormatterObject(log.ChainFormatter(&logrus.JSONFormatter{}, log.AllowFields("user")))
+    l.WithFields(map[string]any{"user": "bob", "password": "hunter2"}).Infof("login")
+    if m := decodeLine(t, &buf); m["user"] != "bob" || m["password"] != nil {
+        t.Errorf("allow output = %v", m)
+    }
+}
+
+func TestMaskTail(t *testing.T) {
+    cases := []struct {
+        value   any
+        visible int
+        want    string
+    }{
+        {"1234567890", 4, "******7890"},
+        {1234567890, 4, "******7890"},
+        {"卡号一二三四五", 2, "*****四五"},
+        {"1234", 4, "****"},
+        {"12", 4, "**"},
+        {"", 4, ""},
+        {"secret", 0, "******"},
+    }
+    for _, tc := range cases {
+        var buf bytes.Buffer
+        l := newJSONLogger(t, &buf)
+        l.SetFormatterObject(log.ChainFormatter(&logrus.JSONFormatter{}, log.MaskTail("account", tc.visible)))
+        l.WithField("account", tc.value).Infof("masked")
+        if m := decodeLine(t, &buf); m["account"] != tc.want {
+            t.Errorf("MaskTail(%v, %d) = %v, want %q", tc.value, tc.visible, m["account"], tc.want)
+        }
+    }
+}
+
+func TestIncludeLevelNumber(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.IncludeLevelNumber = true
+        c.AllowedFields = []string{"user"}
+    })
+
+    cases := []struct {
+        log   func(format string, args ...any)
+        level string
+        num   float64
+    }{
+        {l.Errorf, "error", 2},
+        {l.Warnf, "warning", 3},
+        {l.Infof, "info", 4},
+        {l.Debugf, "debug", 5},
+    }
+    for _, tc := range cases {
+        tc.log("numbered")
+        if m := decodeLine(t, &buf); m["level"] != tc.level || m["level_num"] != tc.num {
+            t.Errorf("level = %v, level_num = %v, want %s/%v", m["level"], m["level_num"], tc.level, tc.num)
+        }
+    }
+
+    plain := newJSONLogger(t, &buf)
+    plain.Infof("plain")
+    if m := decodeLine(t, &buf); m["level_num"] != nil {
+        t.Errorf("level_num should be absent by default: %v", m)
+    }
+}
+
+func TestTimePrecision(t *testing.T) {
+    at := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+    cases := []struct {
+        precision log.TimePrecision
+        want      string
+    }{
+        {log.TimePrecisionSeconds, "2024-05-06T07:08:09Z"},
+        {log.TimePrecisionMillis, "2024-05-06T07:08:09.123Z"},
+        {log.TimePrecisionMicros, "2024-05-06T07:08:09.123456Z"},
+        {log.TimePrecisionNanos, "2024-05-06T07:08:09.123456789Z"},
+    }
+    for _, tc := range cases {
+        var buf bytes.Buffer
+        l := newJSONLogger(t, &buf, func(c *log.Config) {
+            c.TimestampFormat = time.Kitchen // 被 TimePrecision 覆盖
+            c.TimePrecision = tc.precision
+            c.Clock = func() time.Time { return at }
+        })
+        l.Infof("precise")
+        if m := decodeLine(t, &buf); m["time"] != tc.want {
+            t.Errorf("precision %q: time = %v, want %s", tc.precision, m["time"], tc.want)
+        }
+    }
+
+    cfg := log.DefaultConfig()
+    cfg.TimePrecision = "ps"
+    if _, err := log.NewLogger(cfg); err == nil {
+        t.Error("unknown time precision should be rejected")
+    }
+}
+
+func TestFieldTypes(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.FieldTypes = map[string]string{"user_id": "string", "count": "int", "ratio": "float", "ok": "bool"}
+    })
+
+    l.WithFields(map[string]any{"user_id": 42, "count": "7", "ratio": 3, "ok": "true", "other": 1}).Infof("typed")
+    m := decodeLine(t, &buf)
+    if m["user_id"] != "42" || m["count"] != float64(7) || m["ratio"] != float64(3) || m["ok"] != true || m["other"] != float64(1) {
+        t.Errorf("coerced fields = %v", m)
+    }
+    l.WithField("user_id", "u-1").Infof("already string")
+    if m := decodeLine(t, &buf); m["user_id"] != "u-1" {
+        t.Errorf("string user_id = %v", m["user_id"])
+    }
+
+    // 无法转换时保持原值，并且只警告一次
+    stderr := os.Stderr
+    f, err := os.CreateTemp(t.TempDir(), "stderr")
+    if err != nil {
+        t.Fatal(err)
+    }
+    os.Stderr = f
+    l.WithField("count", "many").Infof("bad")
+    l.WithField("count", "lots").Infof("bad again")
+    os.Stderr = stderr
+    f.Close()
+
+    lines := decodeLines(t, &buf)
+    if len(lines) != 2 || lines[0]["count"] != "many" || lines[1]["count"] != "lots" {
+        t.Errorf("uncoercible values should be kept: %v", lines)
+    }
+    warnings, _ := os.ReadFile(f.Name())
+    if n := strings.Count(string(warnings), "cannot coerce field \"count\""); n != 1 {
+        t.Errorf("warnings = %q, want exactly one", warnings)
+    }
+
+    // 声明的字段名同时匹配加上 FieldPrefix 的 Context 字段
+    buf.Reset()
+    prefixed := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.FieldPrefix = "app."
+        c.FieldTypes = map[string]string{"count": "int"}
+    })
+    ctx := log.WithCustomField(context.Background(), "count", "7")
+    prefixed.WithField("count", "8").InfoContextf(ctx, "prefixed")
+    if m := decodeLine(t, &buf); m["app.count"] != float64(7) || m["count"] != float64(8) {
+        t.Errorf("prefixed coerced fields = %v", m)
+    }
+
+    cfg := log.DefaultConfig()
+    cfg.FieldTypes = map[string]string{"user_id": "uuid"}
+    if _, err := log.NewLogger(cfg); err == nil {
+        t.Error("unknown field type should be rejected")
+    }
+}
+
+func TestLevelNames(t *testing.T) {
+    names := map[logrus.Level]string{logrus.WarnLevel: "WARN", logrus.ErrorLevel: "ERR!"}
+
+    var buf bytes.Buffer
+    jl := newJSONLogger(t, &buf, func(c *log.Config) { c.LevelNames = names })
+    jl.WithField("k", "<v>").Warnf("json warn")
+    if m := decodeLine(t, &buf); m["level"] != "WARN" || m["msg"] != "json warn" || m["k"] != "<v>" {
+        t.Errorf("json = %v", m)
+    }
+    jl.Infof("json info")
+    if m := decodeLine(t, &buf); m["level"] != "info" {
+        t.Errorf("unmapped level should keep default label: %v", m)
+    }
+
+    buf.Reset()
+    tl := newTextLogger(t, &buf, func(c *log.Config) { c.LevelNames = names })
+    tl.Errorf("text error")
+    if out := buf.String(); !strings.Contains(out, "ERR!") || strings.Contains(out, "ERRO") || !strings.Contains(out, "text error") {
+        t.Errorf("text = %q", out)
+    }
+
+    buf.Reset()
+    ll := newTextLogger(t, &buf, func(c *log.Config) {
+        c.LevelNames = names
+        c.TextLayout = "[{level}] {msg}"
+    })
+    ll.Warnf("layout warn")
+    if buf.String() != "[WARN] layout warn\n" {
+        t.Errorf("layout = %q", buf.String())
+    }
+}
+
+func TestStructuredStacktrace(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.StructuredStacktrace = true })
+    l.Entry(context.Background()).Stack().Error("with stack")
+
+    m := decodeLine(t, &buf)
+    frames, ok := m[log.StacktraceFieldKey].([]any)
+    if !ok || len(frames) == 0 {
+        t.Fatalf("stacktrace = %#v, want array of frames", m[log.StacktraceFieldKey])
+    }
+    first, ok := frames[0].(map[string]any)
+    if !ok {
+        t.Fatalf("frame = %#v, want object", frames[0])
+    }
+    if fn, _ := first["func"].(string); !strings.HasSuffix(fn, ".TestStructuredStacktrace") {
+        t.Errorf("func = %v", first["func"])
+    }
+    if file, _ := first["file"].(string); !strings.HasSuffix(file, "formatter_test.go") {
+        t.Errorf("file = %v", first["file"])
+    }
+    if line, _ := first["line"].(float64); line <= 0 {
+        t.Errorf("line = %v", first["line"])
+    }
+
+    // 默认输出为字符串
+    plain := newJSONLogger(t, &buf)
+    plain.Entry(context.Background()).Stack().Error("with stack")
+    if s, _ := decodeLine(t, &buf)[log.StacktraceFieldKey].(string); !strings.Contains(s, "TestStructuredStacktrace\n\t") {
+        t.Errorf("default stacktrace = %q", s)
+    }
+
+    // 文本格式回退为字符串 (换行被转义)
+    buf.Reset()
+    text := newTextLogger(t, &buf, func(c *log.Config) { c.StructuredStacktrace = true })
+    text.WithField(log.StacktraceFieldKey, log.CaptureStacktrace(0)).Errorf("text stack")
+    if out := buf.String(); !strings.Contains(out, `TestStructuredStacktrace\n\t`) || strings.Count(out, "\n") != 1 {
+        t.Errorf("text output = %q", out)
+    }
+}
+
+// callerProbeHook 记录条目在 Hook 阶段已带有调用者字段的级别
+type callerProbeHook struct {
+    withCaller []logrus.Level
+}
+
+func (h *callerProbeHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h *callerProbeHook) Fire(e *logrus.Entry) error {
+    if _, ok := e.Data["file"]; ok {
+        h.withCaller = append(h.withCaller, e.Level)
+    }
+    return nil
+}
+
+func TestErrorFormatVerbose(t *testing.T) {
+    for _, reportCaller := range []bool{false, true} {
+        var buf bytes.Buffer
+        l := newJSONLogger(t, &buf, func(c *log.Config) {
+            c.ErrorFormatVerbose = true
+            c.ReportCaller = reportCaller
+            c.StructuredStacktrace = true
+        })
+
+        l.Infof("compact")
+        l.Warnf("compact")
+        for _, m := range decodeLines(t, &buf) {
+            for _, k := range []string{"file", "func", log.StacktraceFieldKey} {
+                if _, ok := m[k]; ok {
+                    t.Errorf("ReportCaller=%v: %s line should not have %s: %v", reportCaller, m["level"], k, m)
+                }
+            }
+        }
+
+        l.Errorf("verbose")
+        m := decodeLine(t, &buf)
+        if file, _ := m["file"].(string); !strings.Contains(file, "formatter_test.go:") {
+            t.Errorf("ReportCaller=%v: file = %v", reportCaller, m["file"])
+        }
+        frames, _ := m[log.StacktraceFieldKey].([]any)
+        if len(frames) == 0 {
+            t.Fatalf("ReportCaller=%v: stacktrace = %v", reportCaller, m[log.StacktraceFieldKey])
+        }
+        if fn, _ := frames[0].(map[string]any)["func"].(string); !strings.HasSuffix(fn, ".TestErrorFormatVerbose") {
+            t.Errorf("ReportCaller=%v: first frame = %v", reportCaller, frames[0])
+        }
+    }
+
+    // CallerLevels 为空时只在 Error 及以上级别查找调用者，不在随后会被去掉的级别上浪费开销
+    var buf bytes.Buffer
+    probe := &callerProbeHook{}
+    jl := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.ErrorFormatVerbose = true
+        c.ReportCaller = true
+    })
+    jl.AddHookWithPriority(probe, 0)
+    jl.Infof("compact")
+    jl.Errorf("verbose")
+    if want := []logrus.Level{logrus.ErrorLevel}; !slices.Equal(probe.withCaller, want) {
+        t.Errorf("caller looked up at levels %v, want %v", probe.withCaller, want)
+    }
+
+    // 文本格式同样只在 Error 行输出调用栈
+    buf.Reset()
+    l := newTextLogger(t, &buf, func(c *log.Config) { c.ErrorFormatVerbose = true })
+    l.Infof("compact text")
+    if out := buf.String(); strings.Contains(out, "stacktrace") || strings.Contains(out, "file") {
+        t.Errorf("info text = %q", out)
+    }
+    buf.Reset()
+    l.Errorf("verbose text")
+    if out := buf.String(); !strings.Contains(out, "stacktrace") || !strings.Contains(out, "formatter_test.go:") {
+        t.Errorf("error text = %q", out)
+    }
+}
+
+type tagNode struct {
+    Name   string
+    Secret string `log:"redact"`
+    Next   *tagNode
+}
+
+// cycleA -> cycleB -> cycleA 相互引用，脱敏字段位于只能经由 cycleA 到达的 cycleC 中
+type cycleA struct {
+    Next  *cycleB
+    Other *cycleC
+}
+
+type cycleB struct {
+    Name string
+    Back *cycleA
+}
+
+type cycleC struct {
+    Token string `log:"redact"`
+}
+
+func TestRespectStructTagsCycles(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.RespectStructTags = true })
+
+    // 自引用的值不会无限递归
+    a := &tagNode{Name: "a", Secret: "s1"}
+    b := &tagNode{Name: "b", Secret: "s2", Next: a}
+    a.Next = b
+    l.WithField("list", a).Infof("cycle")
+    m := decodeLine(t, &buf)
+    list, _ := m["list"].(map[string]any)
+    next, _ := list["Next"].(map[string]any)
+    if list["Secret"] != log.RedactedValue || next["Name"] != "b" || next["Next"] != "[circular]" {
+        t.Errorf("list = %v", m["list"])
+    }
+
+    // 先解析 cycleA 时 cycleB 的结果不完整，不能被缓存为无标签
+    l.WithField("a", cycleA{}).Infof("warm up")
+    decodeLine(t, &buf)
+    l.WithField("b", cycleB{Name: "b", Back: &cycleA{Other: &cycleC{Token: "secret-token"}}}).Infof("mutual")
+    if out := buf.String(); strings.Contains(out, "secret-token") {
+        t.Errorf("redacted field leaked: %s", out)
+    }
+}
@@ -1,9 +1,13 @@
 package test
 
 import (
+    "bytes"
     "context"
+    "encoding/json"
+    "errors"
     "fmt"
     "io"
+    "strings"
     "testing"
     "time"
 
@@ -94,3 +98,116 @@ func TestLogX(t *testing.T) {
 
     t.Log("\n--- Logger Demo Finished ---")
 }
+
+func TestGlobalSync(t *testing.T) {
+    global := log.GetGlobalLogger().(*log.LogrusLogger)
+    original := global.GetConfig()
+    var buf bytes.Buffer
+    global.SetOutput(&buf)
+    defer global.SetOutput(original.Output)
+
+    log.Warnf("before sync")
+    if err := log.Sync(); err != nil {
+        t.Fatalf("Sync: %v", err)
+    }
+    log.Warnf("after sync")
+    if !strings.Contains(buf.String(), "before sync") || !strings.Contains(buf.String(), "after sync") {
+        t.Errorf("global logger output = %q", buf.String())
+    }
+}
+
+func TestGlobalPanicf(t *testing.T) {
+    global := log.GetGlobalLogger().(*log.LogrusLogger)
+    original := global.GetConfig()
+    var buf bytes.Buffer
+    global.SetOutput(&buf)
+    defer global.SetOutput(original.Output)
+
+    defer func() {
+        e, ok := recover().(*logrus.Entry)
+        if !ok || e.Message != "global panic 1" || !strings.Contains(buf.String(), "global panic 1") {
+            t.Errorf("recovered %#v, output %q", e, buf.String())
+        }
+    }()
+    log.PanicContextf(context.Background(), "global panic %d", 1)
+}
+
+func TestLogDiff(t *testing.T) {
+    global := log.GetGlobalLogger().(*log.LogrusLogger)
+    original := global.GetConfig()
+    var buf bytes.Buffer
+    global.SetOutput(&buf)
+    defer global.SetOutput(original.Output)
+
+    type state struct {
+        Status string
+        Count  int
+    }
+    ctx := log.WithRequestID(context.Background(), "req-diff")
+    log.LogDiff(ctx, logrus.WarnLevel, "state changed", state{"open", 1}, state{"closed", 1})
+
+    var m map[string]any
+    if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+        t.Fatalf("invalid JSON: %v: %q", err, buf.String())
+    }
+    changes, _ := m[log.ChangesFieldKey].(map[string]any)
+    status, _ := changes["Status"].(map[string]any)
+    if m["msg"] != "state changed" || m["request_id"] != "req-diff" || len(changes) != 1 ||
+        status["old"] != "open" || status["new"] != "closed" {
+        t.Errorf("diff log = %v", m)
+    }
+
+    // 无变化时不输出
+    buf.Reset()
+    log.LogDiff(ctx, logrus.WarnLevel, "unchanged", state{"open", 1}, state{"open", 1})
+    if buf.Len() != 0 {
+        t.Errorf("unexpected output: %q", buf.String())
+    }
+}
+
+func TestAddGlobalTee(t *testing.T) {
+    global := log.GetGlobalLogger().(*log.LogrusLogger)
+    original := global.GetConfig()
+    var buf, tee bytes.Buffer
+    global.SetOutput(&buf)
+    defer global.SetOutput(original.Output)
+
+    remove := log.AddGlobalTee(&tee)
+    log.Warnf("teed line")
+    if !strings.Contains(buf.String(), "teed line") || tee.String() != buf.String() {
+        t.Errorf("output = %q, tee = %q", buf.String(), tee.String())
+    }
+
+    // 替换主输出后旁路仍然生效
+    var replaced bytes.Buffer
+    global.SetOutput(&replaced)
+    log.Warnf("after set output")
+    if !strings.Contains(replaced.String(), "after set output") || !strings.Contains(tee.String(), "after set output") {
+        t.Errorf("output = %q, tee = %q", replaced.String(), tee.String())
+    }
+
+    remove()
+    remove() // 重复调用无副作用
+    tee.Reset()
+    log.Warnf("untouched")
+    if tee.Len() != 0 || !strings.Contains(replaced.String(), "untouched") {
+        t.Errorf("tee should stop after removal: output = %q, tee = %q", replaced.String(), tee.String())
+    }
+}
+
+func TestGlobalErrorErr(t *testing.T) {
+    global := log.GetGlobalLogger().(*log.LogrusLogger)
+    original := global.GetConfig()
+    var buf bytes.Buffer
+    global.SetOutput(&buf)
+    defer global.SetOutput(original.Output)
+
+    log.ErrorErr(context.Background(), errors.New("timeout"), "failed to call upstream")
+    var m map[string]any
+    if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+        t.Fatalf("unmarshal %q: %v", buf.String(), err)
+    }
+    if m["msg"] != "failed to call upstream" || m["error"] != "timeout" {
+        t.Errorf("global ErrorErr output = %v", m)
+    }
+}
@@ -0,0 +1,1047 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "os"
+    "path/filepath"
+    "reflect"
+    "runtime"
+    "runtime/debug"
+    "slices"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sapaude/go-shims/x/log/test/wrapper"
+    "github.com/sirupsen/logrus"
+)
+
+// newJSONLogger 创建一个输出到 buf 的 JSON Logger，便于断言字段
+func newJSONLogger(t *testing.T, buf *bytes.Buffer, opts ...func(*log.Config)) *log.LogrusLogger {
+    t.Helper()
+    cfg := log.DefaultConfig()
+    cfg.Output = buf
+    cfg.Format = log.FormatJSON
+    cfg.Level = logrus.DebugLevel
+    for _, opt := range opts {
+        opt(&cfg)
+    }
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    return l.(*log.LogrusLogger)
+}
+
+// decodeLine 解析 buf 中的单行 JSON 日志并清空 buf
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+    t.Helper()
+    var m map[string]any
+    if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+        t.Fatalf("unmarshal %q: %v", buf.String(), err)
+    }
+    buf.Reset()
+    return m
+}
+
+func TestFieldPrecedence(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.DefaultFields = map[string]any{"trace_id": "default-trace", "service": "svc"}
+    })
+
+    ctx := log.WithTraceID(context.Background(), "ctx-trace")
+
+    // 显式字段 > Context 字段
+    l.WithField("trace_id", "explicit-trace").InfoContextf(ctx, "explicit wins")
+    m := decodeLine(t, &buf)
+    if m["trace_id"] != "explicit-trace" {
+        t.Errorf("trace_id = %v, want explicit-trace", m["trace_id"])
+    }
+    if m["service"] != "svc" {
+        t.Errorf("service = %v, want svc", m["service"])
+    }
+
+    // Context 字段 > 默认字段
+    l.InfoContextf(ctx, "context wins")
+    m = decodeLine(t, &buf)
+    if m["trace_id"] != "ctx-trace" {
+        t.Errorf("trace_id = %v, want ctx-trace", m["trace_id"])
+    }
+
+    // 仅有默认字段
+    l.Infof("default only")
+    m = decodeLine(t, &buf)
+    if m["trace_id"] != "default-trace" {
+        t.Errorf("trace_id = %v, want default-trace", m["trace_id"])
+    }
+
+    // 自定义 Context 字段同样不能覆盖显式字段
+    ctx = log.WithCustomField(ctx, "user", "from-ctx")
+    l.WithFields(map[string]any{"user": "explicit"}).InfoContextf(ctx, "custom field")
+    m = decodeLine(t, &buf)
+    if m["user"] != "explicit" {
+        t.Errorf("user = %v, want explicit", m["user"])
+    }
+}
+
+func TestLogStartupConfig(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.Level = logrus.WarnLevel
+        cfg.IncludeSequence = true
+        cfg.DefaultFields = map[string]any{"api_key": "secret"}
+    })
+
+    // 启动配置在 Warn 级别下同样输出
+    log.LogStartupConfig(l)
+    m := decodeLine(t, &buf)
+    if m["level"] != "info" || m["log_level"] != "warning" {
+        t.Errorf("level = %v, log_level = %v, want info/warning", m["level"], m["log_level"])
+    }
+    if m["log_format"] != "json" {
+        t.Errorf("log_format = %v, want json", m["log_format"])
+    }
+    if m["log_output"] != "*bytes.Buffer" {
+        t.Errorf("log_output = %v", m["log_output"])
+    }
+    if features, _ := m["log_features"].([]any); !slices.Contains(features, any("sequence")) {
+        t.Errorf("log_features = %v, want sequence", m["log_features"])
+    }
+    // 默认字段只报告键名
+    if keys, _ := m["log_default_fields"].([]any); len(keys) != 1 || keys[0] != "api_key" {
+        t.Errorf("log_default_fields = %v, want [api_key]", m["log_default_fields"])
+    }
+
+    // 按时间轮转的文件输出与 DescribeConfig 的描述一致
+    pattern := filepath.Join(t.TempDir(), "app-%Y-%m-%d.log")
+    rotating := newJSONLogger(t, &buf, func(cfg *log.Config) { cfg.RotationFilePattern = pattern })
+    defer rotating.Close()
+    log.LogStartupConfig(rotating)
+    files, _ := filepath.Glob(filepath.Join(filepath.Dir(pattern), "*.log"))
+    if len(files) != 1 {
+        t.Fatalf("rotated files = %v", files)
+    }
+    data, _ := os.ReadFile(files[0])
+    if !bytes.Contains(data, []byte(`"log_output":"rotating:`+pattern+`"`)) {
+        t.Errorf("rotating log_output missing: %s", data)
+    }
+}
+
+func TestDescribeConfig(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.Level = logrus.WarnLevel
+        cfg.IncludeSequence = true
+        cfg.DefaultFields = map[string]any{"api_key": "secret"}
+    })
+    l.AddHook(&countingHook{})
+    l.AddHookWithPriority(&orderHook{}, 5)
+
+    desc := l.DescribeConfig()
+    for _, want := range []string{
+        "level: warning",
+        "format: json",
+        "output: *bytes.Buffer",
+        "features: sanitize_newlines, sequence",
+        "default fields: api_key",
+        "*log.CallerHook",
+        "*test.countingHook",
+        "*test.orderHook (priority 5)",
+    } {
+        if !strings.Contains(desc, want) {
+            t.Errorf("description missing %q:\n%s", want, desc)
+        }
+    }
+    if strings.Contains(desc, "secret") {
+        t.Errorf("description leaks default field values:\n%s", desc)
+    }
+
+    multi := log.NewMultiLogger(l, newJSONLogger(t, &buf)).(*log.MultiLogger)
+    if desc := multi.DescribeConfig(); !strings.Contains(desc, "logger[0]:\n  level: warning") || !strings.Contains(desc, "logger[1]:\n  level: debug") {
+        t.Errorf("multi description:\n%s", desc)
+    }
+}
+
+func TestErrorfSampled(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.ErrorSignatureWindow = 50 * time.Millisecond
+    })
+    ctx := context.Background()
+
+    // 相同模板与错误类型、不同参数的错误属于同一签名
+    emit := func(i int) {
+        l.ErrorfSampled(ctx, errors.New("timeout"), "query %d failed", i)
+        l.ErrorfSampled(ctx, &os.PathError{Op: "open", Path: fmt.Sprint(i), Err: os.ErrNotExist}, "query %d failed", i)
+        l.ErrorfSampled(ctx, errors.New("refused"), "connect to shard %d", i)
+        l.ErrorfSampled(ctx, nil, "connect to shard %d", i)
+    }
+    for i := 0; i < 5; i++ {
+        emit(i)
+    }
+    lines := decodeLines(t, &buf)
+    if len(lines) != 4 {
+        t.Fatalf("got %d lines, want one per signature: %v", len(lines), lines)
+    }
+    signatures := map[any]bool{}
+    for _, m := range lines {
+        if m["msg"] != "query 0 failed" && m["msg"] != "connect to shard 0" {
+            t.Errorf("first occurrence should be logged in full: %v", m)
+        }
+        signatures[m[log.ErrorSignatureFieldKey]] = true
+    }
+    if len(signatures) != 4 {
+        t.Errorf("signatures = %v, want 4 distinct", signatures)
+    }
+    if lines[0]["error"] != "timeout" || lines[3]["error"] != nil {
+        t.Errorf("error field = %v / %v", lines[0]["error"], lines[3]["error"])
+    }
+
+    // 窗口过后每个签名再次输出，并报告期间被丢弃的次数
+    time.Sleep(60 * time.Millisecond)
+    emit(5)
+    for _, m := range decodeLines(t, &buf) {
+        if m["suppressed_count"] != float64(4) {
+            t.Errorf("suppressed_count = %v, want 4: %v", m["suppressed_count"], m)
+        }
+    }
+}
+
+func TestErrorfSampledBypassesSamplers(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.ErrorSignatureWindow = time.Hour
+        cfg.LevelSampleRates = map[logrus.Level]float64{logrus.ErrorLevel: 0}
+    })
+    ctx := context.Background()
+
+    // 首次出现的签名不受级别采样影响，MultiLogger 同样支持
+    multi := log.NewMultiLogger(l).(log.ErrorSampler)
+    multi.ErrorfSampled(ctx, errors.New("boom"), "job %d failed", 1)
+    multi.ErrorfSampled(ctx, errors.New("boom"), "job %d failed", 2)
+    lines := decodeLines(t, &buf)
+    if len(lines) != 1 || lines[0]["msg"] != "job 1 failed" || lines[0]["error"] != "boom" {
+        t.Fatalf("first occurrence should always be written: %v", lines)
+    }
+}
+
+func TestErrorfKeyed(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.KeyedWindow = 50 * time.Millisecond
+    })
+
+    counts := func() map[string]int {
+        got := map[string]int{}
+        for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+            if len(line) == 0 {
+                continue
+            }
+            var m map[string]any
+            if err := json.Unmarshal(line, &m); err != nil {
+                t.Fatalf("unmarshal %q: %v", line, err)
+            }
+            got[m["dedup_key"].(string)]++
+        }
+        buf.Reset()
+        return got
+    }
+
+    // 每个 key 的突发只输出首条
+    for i := 0; i < 10; i++ {
+        l.ErrorfKeyed("db", "db down: %d", i)
+        l.ErrorfKeyed("cache", "cache miss: %d", i)
+    }
+    if got := counts(); got["db"] != 1 || got["cache"] != 1 {
+        t.Fatalf("first burst counts = %v, want one per key", got)
+    }
+
+    // 窗口过后再次输出，并报告被合并的次数
+    time.Sleep(60 * time.Millisecond)
+    l.ErrorfKeyed("db", "db down again")
+    m := decodeLine(t, &buf)
+    if m["dedup_key"] != "db" || m["suppressed_count"] != float64(9) {
+        t.Errorf("got %v, want db with suppressed_count 9", m)
+    }
+
+    // 其他 key 不受影响
+    l.ErrorfKeyed("queue", "queue full")
+    if got := counts(); got["queue"] != 1 {
+        t.Errorf("queue counts = %v", got)
+    }
+}
+
+func TestIncludeBuildInfo(t *testing.T) {
+    info, ok := debug.ReadBuildInfo()
+    if !ok {
+        t.Skip("build info unavailable")
+    }
+
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.IncludeBuildInfo = true
+    })
+    l.Infof("with build info")
+    m := decodeLine(t, &buf)
+    if m["go_version"] != info.GoVersion {
+        t.Errorf("go_version = %v, want %v", m["go_version"], info.GoVersion)
+    }
+
+    // 未开启时不添加
+    l = newJSONLogger(t, &buf)
+    l.Infof("without build info")
+    if m := decodeLine(t, &buf); m["go_version"] != nil {
+        t.Errorf("unexpected go_version: %v", m)
+    }
+}
+
+func TestIncludeK8sMetadata(t *testing.T) {
+    var buf bytes.Buffer
+    withK8s := func(cfg *log.Config) { cfg.IncludeK8sMetadata = true }
+
+    // 不在 Kubernetes 中运行时不添加任何字段
+    t.Setenv("POD_NAME", "")
+    t.Setenv("POD_NAMESPACE", "")
+    t.Setenv("NODE_NAME", "")
+    newJSONLogger(t, &buf, withK8s).Infof("outside k8s")
+    if m := decodeLine(t, &buf); m["pod"] != nil || m["namespace"] != nil || m["node"] != nil {
+        t.Errorf("unexpected k8s fields: %v", m)
+    }
+
+    t.Setenv("POD_NAME", "api-7d9f")
+    t.Setenv("POD_NAMESPACE", "prod")
+    t.Setenv("NODE_NAME", "node-1")
+    l := newJSONLogger(t, &buf, withK8s)
+    l.Infof("inside k8s")
+    m := decodeLine(t, &buf)
+    if m["pod"] != "api-7d9f" || m["namespace"] != "prod" || m["node"] != "node-1" {
+        t.Errorf("k8s fields = %v", m)
+    }
+
+    // 显式字段优先
+    l.WithField("node", "override").Infof("explicit")
+    if m := decodeLine(t, &buf); m["node"] != "override" {
+        t.Errorf("node = %v, want override", m["node"])
+    }
+}
+
+func TestLevelSampleRates(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(cfg *log.Config) {
+        cfg.ReportCaller = false
+        cfg.LevelSampleRates = map[logrus.Level]float64{
+            logrus.DebugLevel: 0.1,
+            logrus.ErrorLevel: 1.0,
+        }
+    })
+
+    const n = 5000
+    for i := 0; i < n; i++ {
+        l.Debugf("debug %d", i)
+    }
+    debugLines := bytes.Count(buf.Bytes(), []byte("\n"))
+    // 期望约 500 条，允许较宽的随机波动
+    if debugLines < 350 || debugLines > 650 {
+        t.Errorf("debug lines = %d, want about %d", debugLines, n/10)
+    }
+
+    buf.Reset()
+    for i := 0; i < n; i++ {
+        l.Errorf("error %d", i)
+    }
+    if got := bytes.Count(buf.Bytes(), []byte("\n")); got != n {
+        t.Errorf("error lines = %d, want %d", got, n)
+    }
+
+    // 未配置的级别全部保留
+    buf.Reset()
+    for i := 0; i < 100; i++ {
+        l.Infof("info %d", i)
+    }
+    if got := bytes.Count(buf.Bytes(), []byte("\n")); got != 100 {
+        t.Errorf("info lines = %d, want 100", got)
+    }
+}
+
+// countingHook 记录触发次数
+type countingHook struct {
+    fired int
+}
+
+func (h *countingHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h *countingHook) Fire(*logrus.Entry) error {
+    h.fired++
+    return nil
+}
+
+func TestUnwrap(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    raw := l.Unwrap()
+    if raw == nil {
+        t.Fatal("Unwrap() returned nil")
+    }
+    hook := &countingHook{}
+    raw.AddHook(hook)
+
+    l.Infof("one")
+    l.WithField("k", "v").Warnf("two")
+    if hook.fired != 2 {
+        t.Errorf("hook fired %d times, want 2", hook.fired)
+    }
+}
+
+func TestCallerLevels(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.CallerLevels = []logrus.Level{logrus.WarnLevel, logrus.ErrorLevel}
+    })
+
+    l.Infof("info")
+    if m := decodeLine(t, &buf); m["file"] != nil {
+        t.Errorf("info should not report caller: %v", m)
+    }
+    l.Warnf("warn")
+    if m := decodeLine(t, &buf); m["file"] == nil {
+        t.Errorf("warn should report caller: %v", m)
+    }
+    l.Errorf("error")
+    if m := decodeLine(t, &buf); m["file"] == nil {
+        t.Errorf("error should report caller: %v", m)
+    }
+}
+
+func TestCallerFields(t *testing.T) {
+    cases := []struct {
+        name             string
+        fields           []string
+        wantFile, wantFn bool
+    }{
+        {"default", nil, true, true},
+        {"file only", []string{"file"}, true, false},
+        {"func only", []string{"func"}, false, true},
+        {"both", []string{"file", "func"}, true, true},
+        {"neither", []string{}, false, false},
+    }
+    for _, tc := range cases {
+        t.Run(tc.name, func(t *testing.T) {
+            var buf bytes.Buffer
+            l := newJSONLogger(t, &buf, func(c *log.Config) { c.CallerFields = tc.fields })
+            l.Infof("caller fields")
+            m := decodeLine(t, &buf)
+            if _, ok := m["file"]; ok != tc.wantFile {
+                t.Errorf("file present = %v, want %v: %v", ok, tc.wantFile, m)
+            }
+            if _, ok := m["func"]; ok != tc.wantFn {
+                t.Errorf("func present = %v, want %v: %v", ok, tc.wantFn, m)
+            }
+        })
+    }
+
+    cfg := log.DefaultConfig()
+    cfg.CallerFields = []string{"line"}
+    if _, err := log.NewLogger(cfg); err == nil {
+        t.Error("unknown caller field should be rejected")
+    }
+}
+
+func TestCallerURIScheme(t *testing.T) {
+    for _, scheme := range []bool{false, true} {
+        var buf bytes.Buffer
+        l := newJSONLogger(t, &buf, func(c *log.Config) { c.CallerURIScheme = scheme })
+        _, file, line, _ := runtime.Caller(0)
+        l.Entry(context.Background()).Info("caller")
+
+        want := fmt.Sprintf("%s:%d", file, line+1)
+        if scheme {
+            want = "file://" + want
+        }
+        if m := decodeLine(t, &buf); m["file"] != want {
+            t.Errorf("CallerURIScheme=%v: file = %v, want %s", scheme, m["file"], want)
+        }
+    }
+}
+
+func benchmarkCaller(b *testing.B, levels []logrus.Level) {
+    cfg := log.DefaultConfig()
+    cfg.Output = io.Discard
+    cfg.CallerLevels = levels
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        b.Fatalf("NewLogger: %v", err)
+    }
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        l.Infof("request %d handled", i)
+    }
+}
+
+// BenchmarkInfoCallerAllLevels 与 BenchmarkInfoCallerErrorOnly 对比 Info 日志在是否计算调用者信息时的开销
+func BenchmarkInfoCallerAllLevels(b *testing.B) { benchmarkCaller(b, nil) }
+
+func BenchmarkInfoCallerErrorOnly(b *testing.B) {
+    benchmarkCaller(b, []logrus.Level{logrus.ErrorLevel})
+}
+
+func TestEntryBuilder(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    ctx := log.WithRequestID(context.Background(), "req-1")
+    _, file, line, _ := runtime.Caller(0)
+    l.Entry(ctx).Field("k", "v").Fields(map[string]any{"k2": 2}).Err(errors.New("boom")).Error("failed")
+
+    m := decodeLine(t, &buf)
+    if m["k"] != "v" || m["k2"] != float64(2) || m["error"] != "boom" || m["request_id"] != "req-1" {
+        t.Errorf("builder fields: %v", m)
+    }
+    if m["msg"] != "failed" || m["level"] != "error" {
+        t.Errorf("builder entry: %v", m)
+    }
+    if want := fmt.Sprintf("%s:%d", file, line+1); m["file"] != want {
+        t.Errorf("file = %v, want %s", m["file"], want)
+    }
+
+    // 不同 Builder 之间的字段互不影响
+    l.Entry(ctx).Info("plain")
+    if m := decodeLine(t, &buf); m["k"] != nil || m["error"] != nil {
+        t.Errorf("fields leaked: %v", m)
+    }
+}
+
+func TestLargeIntegerFields(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    ctx := log.WithCustomField(context.Background(), "big", int64(math.MaxInt64))
+    ctx = log.WithCustomField(ctx, "ratio", 0.25)
+    l.WithField("unsigned", uint64(math.MaxUint64)).InfoContextf(ctx, "numbers")
+
+    line := buf.Bytes()
+    for _, want := range []string{
+        `"big":9223372036854775807`,
+        `"unsigned":18446744073709551615`,
+        `"ratio":0.25`,
+    } {
+        if !bytes.Contains(line, []byte(want)) {
+            t.Errorf("output missing %s: %s", want, line)
+        }
+    }
+
+    // 按 json.Number 解码可以精确还原
+    var m map[string]any
+    dec := json.NewDecoder(&buf)
+    dec.UseNumber()
+    if err := dec.Decode(&m); err != nil {
+        t.Fatal(err)
+    }
+    if n, err := m["big"].(json.Number).Int64(); err != nil || n != math.MaxInt64 {
+        t.Errorf("big = %v (%v), want MaxInt64", m["big"], err)
+    }
+}
+
+// orderHook 在触发时记录自己的名字
+type orderHook struct {
+    name   string
+    levels []logrus.Level
+    fired  *[]string
+}
+
+func (h *orderHook) Levels() []logrus.Level { return h.levels }
+
+func (h *orderHook) Fire(*logrus.Entry) error {
+    *h.fired = append(*h.fired, h.name)
+    return nil
+}
+
+func TestAddHookWithPriority(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    var fired []string
+    add := func(name string, priority int, levels ...logrus.Level) {
+        if len(levels) == 0 {
+            levels = logrus.AllLevels
+        }
+        l.AddHookWithPriority(&orderHook{name: name, levels: levels, fired: &fired}, priority)
+    }
+    add("export", 100)
+    add("metrics", 50)
+    add("redact", 0)
+    add("redact-2", 0)
+    add("errors-only", 10, logrus.ErrorLevel)
+
+    // 子 Logger 共享同一组 Hook
+    l.WithField("k", "v").Infof("hello")
+    if got := strings.Join(fired, ","); got != "redact,redact-2,metrics,export" {
+        t.Errorf("info hooks fired in order %s", got)
+    }
+
+    fired = nil
+    l.Errorf("boom")
+    if got := strings.Join(fired, ","); got != "redact,redact-2,errors-only,metrics,export" {
+        t.Errorf("error hooks fired in order %s", got)
+    }
+}
+
+func TestClockAndTimer(t *testing.T) {
+    frozen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.Clock = func() time.Time { return frozen }
+        c.TimestampFormat = time.RFC3339
+        c.NormalizeTimeFields = true
+    })
+
+    timer := log.StartTimer()
+    time.Sleep(20 * time.Millisecond)
+    timer.WithElapsed(l).Infof("done")
+
+    m := decodeLine(t, &buf)
+    if m["time"] != "2024-01-02T03:04:05Z" {
+        t.Errorf("time = %v, want frozen clock", m["time"])
+    }
+    // 墙上时钟被冻结，耗时仍基于单调时钟
+    if elapsed, _ := m["elapsed"].(float64); elapsed < 20 || elapsed > 5000 {
+        t.Errorf("elapsed = %v ms, want >= 20", m["elapsed"])
+    }
+}
+
+func TestCallerSkipPackages(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.CallerSkipPackages = []string{"github.com/sapaude/go-shims/x/log/test/wrapper"}
+    })
+    w := wrapper.Logger{L: l}
+
+    _, _, line, _ := runtime.Caller(0)
+    w.Infof("via wrapper")
+    m := decodeLine(t, &buf)
+    if want := fmt.Sprintf("logger_test.go:%d", line+1); !strings.HasSuffix(m["file"].(string), want) {
+        t.Errorf("file = %v, want suffix %s", m["file"], want)
+    }
+    if m["func"] != "TestCallerSkipPackages()" {
+        t.Errorf("func = %v", m["func"])
+    }
+
+    _, _, line, _ = runtime.Caller(0)
+    w.InfoContextf(context.Background(), "via nested wrapper")
+    m = decodeLine(t, &buf)
+    if want := fmt.Sprintf("logger_test.go:%d", line+1); !strings.HasSuffix(m["file"].(string), want) {
+        t.Errorf("file = %v, want suffix %s", m["file"], want)
+    }
+
+    // 直接调用同样定位到调用处
+    _, _, line, _ = runtime.Caller(0)
+    l.Infof("direct")
+    m = decodeLine(t, &buf)
+    if want := fmt.Sprintf("logger_test.go:%d", line+1); !strings.HasSuffix(m["file"].(string), want) {
+        t.Errorf("file = %v, want suffix %s", m["file"], want)
+    }
+}
+
+func TestLogAt(t *testing.T) {
+    eventTime := time.Date(2019, 6, 7, 8, 9, 10, 0, time.UTC)
+    now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.Clock = func() time.Time { return now }
+        c.TimestampFormat = time.RFC3339
+    })
+
+    l.LogAt(eventTime, logrus.WarnLevel, "imported %d", 1)
+    m := decodeLine(t, &buf)
+    if m["time"] != "2019-06-07T08:09:10Z" || m["level"] != "warning" || m["msg"] != "imported 1" {
+        t.Errorf("LogAt entry = %v", m)
+    }
+
+    ctx := log.WithRequestID(context.Background(), "req-1")
+    l.LogAtContext(ctx, eventTime, logrus.InfoLevel, "imported with context")
+    m = decodeLine(t, &buf)
+    if m["time"] != "2019-06-07T08:09:10Z" || m["request_id"] != "req-1" {
+        t.Errorf("LogAtContext entry = %v", m)
+    }
+
+    // 未指定时间的日志仍使用 Clock
+    l.Infof("live")
+    if m = decodeLine(t, &buf); m["time"] != "2024-01-02T03:04:05Z" {
+        t.Errorf("time = %v, want clock time", m["time"])
+    }
+
+    // 低于 Logger 级别的日志不输出
+    l.SetLevel(logrus.WarnLevel)
+    l.LogAt(eventTime, logrus.InfoLevel, "filtered")
+    if buf.Len() != 0 {
+        t.Errorf("filtered entry written: %q", buf.String())
+    }
+}
+
+func TestAdaptiveLevel(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.AdaptiveLevel = log.AdaptiveLevel{RateThreshold: 50, Window: 100 * time.Millisecond}
+    })
+
+    // 窗口内超过 5 条后收紧级别，后续 Debug 日志被丢弃
+    for i := 0; i < 20; i++ {
+        l.Debugf("debug %d", i)
+    }
+    l.Infof("info while busy")
+    lines := decodeLines(t, &buf)
+    if len(lines) != 7 {
+        t.Fatalf("got %d lines under load, want 6 debug + 1 info: %v", len(lines), lines)
+    }
+    if last := lines[len(lines)-1]; last["msg"] != "info while busy" {
+        t.Errorf("info should still be written under load: %v", last)
+    }
+
+    // 负载回落后恢复
+    time.Sleep(250 * time.Millisecond)
+    l.Debugf("debug after load")
+    if m := decodeLine(t, &buf); m["msg"] != "debug after load" {
+        t.Errorf("debug after load = %v", m)
+    }
+}
+
+func TestAdaptiveLevelSustainedLoad(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) {
+        c.AdaptiveLevel = log.AdaptiveLevel{RateThreshold: 25, Window: 200 * time.Millisecond}
+    })
+
+    // 持续高负载的多个窗口内保持收紧: 被丢弃的条目同样计入速率，级别不会在窗口之间来回切换
+    for w := 0; w < 5; w++ {
+        for i := 0; i < 30; i++ {
+            l.Debugf("debug %d-%d", w, i)
+        }
+        time.Sleep(220 * time.Millisecond)
+    }
+    if lines := decodeLines(t, &buf); len(lines) != 6 {
+        t.Fatalf("got %d debug lines across windows of sustained load, want 6: %v", len(lines), lines)
+    }
+}
+
+type diffAddress struct {
+    City   string
+    Street string `json:"street"`
+}
+
+type diffUser struct {
+    Name     string
+    Age      int
+    Password string `log:"redact"`
+    Internal string `log:"-"`
+    Address  *diffAddress
+    Tags     []string
+    Attrs    map[string]any
+    Updated  time.Time
+    secret   string
+}
+
+func TestDiff(t *testing.T) {
+    ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    old := diffUser{
+        Name: "bob", Age: 30, Password: "a", Internal: "x",
+        Address: &diffAddress{City: "Paris", Street: "1 Rue"},
+        Tags:    []string{"a"}, Attrs: map[string]any{"plan": "free", "seats": 1},
+        Updated: ts, secret: "s1",
+    }
+    new := old
+    new.Age = 31
+    new.Password = "b"
+    new.Internal = "y"
+    new.Address = &diffAddress{City: "Paris", Street: "2 Rue"}
+    new.Attrs = map[string]any{"plan": "pro", "seats": 1}
+    new.secret = "s2"
+
+    changes := log.Diff(old, &new)
+    want := map[string]any{
+        "Age":            &log.FieldChange{Old: 30, New: 31},
+        "Password":       &log.FieldChange{Old: log.RedactedValue, New: log.RedactedValue},
+        "Address.street": &log.FieldChange{Old: "1 Rue", New: "2 Rue"},
+        "Attrs.plan":     &log.FieldChange{Old: "free", New: "pro"},
+    }
+    if !reflect.DeepEqual(changes, want) {
+        t.Errorf("Diff = %v, want %v", changes, want)
+    }
+
+    // nil 安全
+    if d := log.Diff(nil, nil); len(d) != 0 {
+        t.Errorf("Diff(nil, nil) = %v", d)
+    }
+    var nilUser *diffUser
+    if d := log.Diff(nilUser, &new); len(d) != 1 || d["value"] == nil {
+        t.Errorf("Diff(nil, user) = %v", d)
+    }
+    if d := log.Diff(old, old); len(d) != 0 {
+        t.Errorf("Diff of equal values = %v", d)
+    }
+}
+
+func TestNewLoggerWithFallback(t *testing.T) {
+    var buf bytes.Buffer
+    cfg := log.DefaultConfig()
+    cfg.Output = &buf
+    cfg.Format = log.FormatJSON
+    cfg.DefaultFields = map[string]any{"service": "api"}
+    cfg.FilePath = filepath.Join(t.TempDir(), "missing", "app.log") // 目录不存在，无法打开
+
+    l, err := log.NewLoggerWithFallback(cfg)
+    if err == nil {
+        t.Fatal("expected init error for unwritable file path")
+    }
+    if l == nil {
+        t.Fatal("fallback logger should not be nil")
+    }
+    l.Warnf("after fallback")
+    m := decodeLine(t, &buf)
+    if m["msg"] != "after fallback" || m["service"] != "api" || m["file"] == nil || m["func"] == nil {
+        t.Errorf("fallback should keep the configured structure: %v", m)
+    }
+
+    // 其余配置同样无效时退回到默认 JSON 格式
+    buf.Reset()
+    cfg.CompactJSON, cfg.JSONPretty = true, true
+    l, err = log.NewLoggerWithFallback(cfg)
+    if err == nil {
+        t.Fatal("expected init error")
+    }
+    l.Warnf("minimal")
+    if m := decodeLine(t, &buf); m["msg"] != "minimal" || m["file"] == nil {
+        t.Errorf("minimal fallback output = %v", m)
+    }
+}
+
+func TestIncludeSequence(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.IncludeSequence = true })
+    child := l.WithField("child", true)
+
+    const workers, perWorker = 8, 50
+    var wg sync.WaitGroup
+    for i := 0; i < workers; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            for j := 0; j < perWorker; j++ {
+                if i%2 == 0 {
+                    l.Infof("entry %d-%d", i, j)
+                } else {
+                    child.Infof("entry %d-%d", i, j)
+                }
+            }
+        }(i)
+    }
+    wg.Wait()
+
+    lines := decodeLines(t, &buf)
+    if len(lines) != workers*perWorker {
+        t.Fatalf("got %d lines, want %d", len(lines), workers*perWorker)
+    }
+    for i, m := range lines {
+        if m["seq"] != float64(i+1) {
+            t.Fatalf("line %d seq = %v, want %d", i, m["seq"], i+1)
+        }
+    }
+
+    // 未开启时不添加 seq 字段
+    plain := newJSONLogger(t, &buf)
+    plain.Infof("no seq")
+    if m := decodeLine(t, &buf); m["seq"] != nil {
+        t.Errorf("seq should be absent by default: %v", m)
+    }
+}
+
+func TestErrorErr(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+    ctx := log.WithRequestID(context.Background(), "req-1")
+
+    _, file, line, _ := runtime.Caller(0)
+    l.ErrorErr(ctx, errors.New("connection refused"), "failed to load user")
+    m := decodeLine(t, &buf)
+    if m["msg"] != "failed to load user" || m["error"] != "connection refused" || m["level"] != "error" {
+        t.Errorf("ErrorErr output = %v", m)
+    }
+    if m["request_id"] != "req-1" {
+        t.Errorf("context fields missing: %v", m)
+    }
+    if want := fmt.Sprintf("%s:%d", file, line+1); m["file"] != want {
+        t.Errorf("file = %v, want %s", m["file"], want)
+    }
+
+    // 消息中的 % 不会被当作格式化动词
+    l.ErrorErr(ctx, nil, "100% done")
+    if m := decodeLine(t, &buf); m["msg"] != "100% done" || m["error"] != nil {
+        t.Errorf("nil error output = %v", m)
+    }
+}
+
+func TestPanicThrottle(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.PanicThrottleWindow = 50 * time.Millisecond })
+
+    panicOnce := func(format string, args ...any) (recovered any) {
+        defer func() { recovered = recover() }()
+        l.PanicContextf(context.Background(), format, args...)
+        return nil
+    }
+
+    // 紧密的 recover-重试循环: 每次都 panic，但只输出一条日志；按消息模板限流，参数不同 (如重试次数) 也会被限流
+    for i := 0; i < 100; i++ {
+        r := panicOnce("worker crashed: attempt %d", i)
+        if e, ok := r.(*logrus.Entry); !ok || e.Message != fmt.Sprintf("worker crashed: attempt %d", i) {
+            t.Fatalf("recovered %#v, want *logrus.Entry with the message", r)
+        }
+    }
+    panicOnce("worker crashed: %s", "index out of range")
+    lines := decodeLines(t, &buf)
+    if len(lines) != 2 || lines[0]["level"] != "panic" || lines[0]["msg"] != "worker crashed: attempt 0" ||
+        lines[1]["msg"] != "worker crashed: index out of range" {
+        t.Fatalf("throttled output = %v", lines)
+    }
+
+    // 窗口过后再次输出，并报告期间被限流的次数
+    time.Sleep(60 * time.Millisecond)
+    panicOnce("worker crashed: attempt %d", 100)
+    if m := decodeLine(t, &buf); m["suppressed_count"] != float64(99) {
+        t.Errorf("suppressed_count = %v, want 99", m["suppressed_count"])
+    }
+
+    // 关闭限流后每次都输出
+    unthrottled := newJSONLogger(t, &buf, func(c *log.Config) { c.PanicThrottleWindow = -1 })
+    for i := 0; i < 3; i++ {
+        func() {
+            defer func() { _ = recover() }()
+            unthrottled.Panicf("boom")
+        }()
+    }
+    if n := len(decodeLines(t, &buf)); n != 3 {
+        t.Errorf("unthrottled lines = %d, want 3", n)
+    }
+}
+
+func TestCallerCalibration(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf, func(c *log.Config) { c.ReportCaller = true })
+    multi := log.NewMultiLogger(log.NewMultiLogger(l))
+    ctx := context.Background()
+
+    // here 返回调用者所在的文件与下一行的行号，即紧随其后的日志调用位置
+    here := func() string {
+        _, file, line, _ := runtime.Caller(1)
+        return fmt.Sprintf("%s:%d", file, line+1)
+    }
+    cases := []struct {
+        name string
+        log  func() string
+    }{
+        {"method", func() string {
+            want := here()
+            l.Infof("direct")
+            return want
+        }},
+        {"context method", func() string {
+            want := here()
+            l.InfoContextf(ctx, "context")
+            return want
+        }},
+        {"per-call fields", func() string {
+            want := here()
+            l.InfofWith(ctx, map[string]any{"k": 1}, "with")
+            return want
+        }},
+        {"nested multi", func() string {
+            want := here()
+            multi.Warnf("multi")
+            return want
+        }},
+        {"multi panic", func() (want string) {
+            defer func() { _ = recover() }()
+            want = here()
+            multi.(*log.MultiLogger).Panicf("multi panic")
+            return want
+        }},
+    }
+    for _, tc := range cases {
+        want := tc.log()
+        if m := decodeLine(t, &buf); m["file"] != want {
+            t.Errorf("%s: file = %v, want %s", tc.name, m["file"], want)
+        }
+    }
+}
+
+func TestValidateConfig(t *testing.T) {
+    var buf bytes.Buffer
+    valid := log.DefaultConfig()
+    valid.Output = &buf
+    valid.ReportCaller = true
+    if err := log.ValidateConfig(valid); err != nil {
+        t.Fatalf("valid config: %v", err)
+    }
+    if buf.Len() != 0 {
+        t.Errorf("ValidateConfig wrote output: %q", buf.String())
+    }
+
+    cases := []struct {
+        name   string
+        modify func(*log.Config)
+        want   string
+    }{
+        {"unknown level", func(c *log.Config) { c.Level = logrus.Level(42) }, "unknown level 42"},
+        {"unknown format", func(c *log.Config) { c.Format = "yaml" }, `unknown format "yaml"`},
+        {"custom format", func(c *log.Config) { c.Format = log.FormatCustom }, "SetFormatterObject"},
+        {"conflicting json options", func(c *log.Config) { c.CompactJSON, c.JSONPretty = true, true }, "CompactJSON and JSONPretty"},
+        {"invalid layout", func(c *log.Config) { c.Format, c.TextLayout = log.FormatText, "{bogus}" }, "bogus"},
+        {"missing directory", func(c *log.Config) { c.FilePath = filepath.Join(t.TempDir(), "missing", "app.log") }, "no such file"},
+        {"unwritable output", func(c *log.Config) { c.Output = &failingWriter{fail: true} }, "output not writable: log output: disk full"},
+    }
+    for _, tc := range cases {
+        cfg := valid
+        tc.modify(&cfg)
+        err := log.ValidateConfig(cfg)
+        if err == nil || !strings.Contains(err.Error(), tc.want) {
+            t.Errorf("%s: err = %v, want containing %q", tc.name, err, tc.want)
+        }
+    }
+}
+
+// sliceHook 是包含切片的值类型 Hook，不能用作 map 键
+type sliceHook struct {
+    levels []logrus.Level
+}
+
+func (h sliceHook) Levels() []logrus.Level { return h.levels }
+func (sliceHook) Fire(*logrus.Entry) error { return nil }
+
+func TestDescribeConfigUnhashableHook(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+    l.AddHook(sliceHook{levels: []logrus.Level{logrus.ErrorLevel, logrus.WarnLevel}})
+
+    var wg sync.WaitGroup
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        for i := 0; i < 50; i++ {
+            l.AddHook(&countingHook{})
+        }
+    }()
+    desc := l.DescribeConfig()
+    wg.Wait()
+    if n := strings.Count(desc, "test.sliceHook"); n != 1 {
+        t.Errorf("sliceHook listed %d times:\n%s", n, desc)
+    }
+}
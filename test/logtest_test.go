@@ -0,0 +1,124 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sapaude/go-shims/x/log/logtest"
+)
+
+// fakeTB 记录 Log 输出，并允许手动触发 Cleanup
+type fakeTB struct {
+    testing.TB
+    lines    []string
+    cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Log(args ...any) {
+    f.lines = append(f.lines, fmt.Sprint(args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+    f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) finish() {
+    for i := len(f.cleanups) - 1; i >= 0; i-- {
+        f.cleanups[i]()
+    }
+}
+
+func TestTBLogger(t *testing.T) {
+    tb := &fakeTB{TB: t}
+    cfg := log.DefaultConfig()
+    cfg.Format = log.FormatJSON
+    l := logtest.NewTBLogger(tb, cfg)
+
+    l.Infof("first line")
+    l.Warnf("second line")
+    if len(tb.lines) != 2 {
+        t.Fatalf("lines = %q, want 2 lines", tb.lines)
+    }
+    if !strings.Contains(tb.lines[0], "first line") || strings.HasSuffix(tb.lines[0], "\n") {
+        t.Errorf("line = %q", tb.lines[0])
+    }
+
+    // 测试结束后写入的日志被丢弃
+    tb.finish()
+    l.Errorf("after test end")
+    if len(tb.lines) != 2 {
+        t.Errorf("log after cleanup reached tb: %q", tb.lines)
+    }
+}
+
+var updateGolden = flag.Bool("update", false, "重新生成 testdata 中的 golden 文件")
+
+func TestGoldenLogger(t *testing.T) {
+    var buf bytes.Buffer
+    l := logtest.NewGoldenLogger(&buf)
+
+    ctx := log.WithRequestID(context.Background(), "req-1")
+    l.Debugf("starting")
+    l.(log.FieldLogger).WithFields(map[string]any{"zeta": 1, "alpha": "a b", "mid": true}).InfoContextf(ctx, "sorted fields")
+    l.Warnf("done in %dms", 42)
+
+    golden := filepath.Join("testdata", "golden_logger.golden")
+    if *updateGolden {
+        if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+            t.Fatalf("update golden: %v", err)
+        }
+    }
+    want, err := os.ReadFile(golden)
+    if err != nil {
+        t.Fatalf("read golden: %v", err)
+    }
+    if got := buf.String(); got != string(want) {
+        t.Errorf("output differs from %s (run with -update to regenerate):\ngot:\n%s\nwant:\n%s", golden, got, want)
+    }
+}
+
+func TestNDJSONValidator(t *testing.T) {
+    // 紧凑 JSON 会转义字段中的换行，每条日志仍为单行
+    var buf bytes.Buffer
+    cfg := log.DefaultConfig()
+    cfg.Output = logtest.CheckNDJSON(t, &buf)
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    l.(log.FieldLogger).WithField("note", "line one\nline two").Infof("multi\nline message")
+    if strings.Count(buf.String(), "\n") != 1 {
+        t.Errorf("compact JSON output = %q", buf.String())
+    }
+
+    // 美化输出与文本格式都会被标记为违规
+    pretty := logtest.NewNDJSONValidator(nil)
+    cfg.Output = pretty
+    cfg.JSONPretty = true
+    l, err = log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    l.(log.FieldLogger).WithField("note", "x").Infof("pretty")
+    if err := pretty.Err(); err == nil || !strings.Contains(err.Error(), "embedded newline") {
+        t.Errorf("pretty JSON violation = %v", err)
+    }
+
+    text := logtest.NewNDJSONValidator(nil)
+    text.Write([]byte("level=info msg=hello\n"))
+    text.Write([]byte(`{"msg":"no newline"}`))
+    text.Write([]byte("[1,2]\n"))
+    text.Write([]byte(`{"msg":"ok"}` + "\n"))
+    if got := len(text.Violations()); got != 3 {
+        t.Errorf("violations = %v, want 3", text.Violations())
+    }
+}
@@ -0,0 +1,266 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sirupsen/logrus"
+)
+
+// decodeLines 解析 buf 中的全部 JSON 日志行
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+    t.Helper()
+    var lines []map[string]any
+    dec := json.NewDecoder(buf)
+    for dec.More() {
+        var m map[string]any
+        if err := dec.Decode(&m); err != nil {
+            t.Fatalf("invalid JSON log: %v", err)
+        }
+        lines = append(lines, m)
+    }
+    return lines
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    var handlerBody string
+    handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        b, _ := io.ReadAll(r.Body)
+        handlerBody = string(b)
+        if id, _ := log.GetRequestID(r.Context()); id != "req-1" {
+            t.Errorf("request id in handler = %q", id)
+        }
+        w.WriteHeader(http.StatusCreated)
+        io.WriteString(w, `{"token":"abc","id":7}`)
+    })
+    mw := log.HTTPMiddleware(l,
+        log.WithMaxBodyBytes(64),
+        log.WithRedactedBodyKeys("password", "Token"),
+    )(handler)
+
+    reqBody := `{"user":"bob","password":"hunter2","nested":{"PASSWORD":"x"}}`
+    req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(reqBody))
+    req.Header.Set(log.HeaderRequestID, "req-1")
+    req.Header.Set("Authorization", "Bearer secret")
+    mw.ServeHTTP(httptest.NewRecorder(), req)
+
+    if handlerBody != reqBody {
+        t.Errorf("handler read %q, want full body", handlerBody)
+    }
+
+    lines := decodeLines(t, &buf)
+    if len(lines) != 2 {
+        t.Fatalf("got %d lines, want 2", len(lines))
+    }
+    access, body := lines[0], lines[1]
+    if access["http_status"] != float64(http.StatusCreated) || access["http_path"] != "/login" || access["request_id"] != "req-1" {
+        t.Errorf("access log: %v", access)
+    }
+    if body["level"] != "debug" {
+        t.Errorf("body log level = %v, want debug", body["level"])
+    }
+    reqLogged, _ := body["http_request_body"].(string)
+    if strings.Contains(reqLogged, "hunter2") || strings.Contains(reqLogged, `"x"`) || !strings.Contains(reqLogged, "bob") {
+        t.Errorf("request body not redacted: %s", reqLogged)
+    }
+    if resp, _ := body["http_response_body"].(string); strings.Contains(resp, "abc") || !strings.Contains(resp, `"id":7`) {
+        t.Errorf("response body not redacted: %s", resp)
+    }
+    headers, _ := body["http_request_headers"].(map[string]any)
+    if headers["Authorization"] != log.RedactedValue {
+        t.Errorf("authorization header = %v", headers["Authorization"])
+    }
+}
+
+func TestHTTPMiddlewareBodyTruncation(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    var handlerBody string
+    handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        b, _ := io.ReadAll(r.Body)
+        handlerBody = string(b)
+    })
+    mw := log.HTTPMiddleware(l, log.WithMaxBodyBytes(24), log.WithRedactedBodyKeys("password"))(handler)
+
+    reqBody := `{"password":"hunter2","padding":"` + strings.Repeat("a", 100) + `"}`
+    mw.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(reqBody)))
+
+    if handlerBody != reqBody {
+        t.Errorf("handler body truncated: %d bytes", len(handlerBody))
+    }
+    lines := decodeLines(t, &buf)
+    logged, _ := lines[1]["http_request_body"].(string)
+    if !strings.HasSuffix(logged, "...(truncated)") || strings.Contains(logged, "hunter2") {
+        t.Errorf("truncated body = %q", logged)
+    }
+
+    // 非 Debug 级别不记录请求体
+    buf.Reset()
+    l.SetLevel(logrus.InfoLevel)
+    mw.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(reqBody)))
+    if lines := decodeLines(t, &buf); len(lines) != 1 || lines[0]["http_request_body"] != nil {
+        t.Errorf("body captured at info level: %v", lines)
+    }
+}
+
+func TestHTTPMiddlewareSlowThreshold(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        l.InfoContextf(r.Context(), "handler detail")
+        switch r.URL.Path {
+        case "/slow":
+            time.Sleep(30 * time.Millisecond)
+        case "/fail":
+            w.WriteHeader(http.StatusInternalServerError)
+        }
+    })
+    mw := log.HTTPMiddleware(l, log.WithSlowThreshold(20*time.Millisecond))(handler)
+    serve := func(path string) []map[string]any {
+        mw.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+        return decodeLines(t, &buf)
+    }
+
+    // 快速成功的请求只输出访问日志
+    if lines := serve("/fast"); len(lines) != 1 || lines[0]["msg"] != "http request" {
+        t.Errorf("fast request lines = %v", lines)
+    }
+
+    // 慢请求的暂存日志提升级别输出
+    lines := serve("/slow")
+    if len(lines) != 2 {
+        t.Fatalf("slow request lines = %v", lines)
+    }
+    if lines[0]["msg"] != "handler detail" || lines[0]["level"] != "warning" || lines[0][log.OriginalLevelFieldKey] != "info" {
+        t.Errorf("slow flushed entry = %v", lines[0])
+    }
+
+    // 失败的请求按原级别输出
+    lines = serve("/fail")
+    if len(lines) != 2 {
+        t.Fatalf("failed request lines = %v", lines)
+    }
+    if lines[0]["msg"] != "handler detail" || lines[0]["level"] != "info" {
+        t.Errorf("error flushed entry = %v", lines[0])
+    }
+}
+
+func TestHTTPMiddlewareFlushHijack(t *testing.T) {
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+
+    var flushed, hijackErr bool
+    handler := log.HTTPMiddleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if f, ok := w.(http.Flusher); ok {
+            _, _ = w.Write([]byte("chunk"))
+            f.Flush()
+            flushed = true
+        }
+        // httptest.ResponseRecorder 不支持 Hijack，包装后应返回 http.ErrNotSupported 而不是断言失败
+        if h, ok := w.(http.Hijacker); ok {
+            _, _, err := h.Hijack()
+            hijackErr = errors.Is(err, http.ErrNotSupported)
+        }
+    }))
+    rec := httptest.NewRecorder()
+    handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+
+    if !flushed || !rec.Flushed {
+        t.Errorf("flush not forwarded: asserted=%v recorder flushed=%v", flushed, rec.Flushed)
+    }
+    if !hijackErr {
+        t.Error("Hijack should be exposed and report http.ErrNotSupported")
+    }
+}
+
+// roundTripFunc 将函数适配为 http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+    return f(r)
+}
+
+func TestLoggingRoundTripper(t *testing.T) {
+    var gotTrace, gotAuth string
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        gotTrace = r.Header.Get(log.HeaderTraceID)
+        gotAuth = r.Header.Get("Authorization")
+        w.WriteHeader(http.StatusAccepted)
+    }))
+    defer srv.Close()
+
+    var buf bytes.Buffer
+    l := newJSONLogger(t, &buf)
+    // 默认向本机注入关联字段
+    client := &http.Client{Transport: log.NewLoggingRoundTripper(nil, l)}
+
+    ctx := log.WithTraceID(context.Background(), "trace-out")
+    req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/items?id=1&token=secret", nil)
+    req.Header.Set("Authorization", "Bearer secret")
+    resp, err := client.Do(req)
+    if err != nil {
+        t.Fatalf("Do: %v", err)
+    }
+    resp.Body.Close()
+
+    if gotTrace != "trace-out" || gotAuth != "Bearer secret" {
+        t.Errorf("server saw trace=%q auth=%q", gotTrace, gotAuth)
+    }
+    if req.Header.Get(log.HeaderTraceID) != "" {
+        t.Error("original request must not be modified")
+    }
+
+    lines := decodeLines(t, &buf)
+    if len(lines) != 2 {
+        t.Fatalf("got %d lines, want 2: %v", len(lines), lines)
+    }
+    access, headers := lines[0], lines[1]
+    if access["http_method"] != "GET" || access["http_status"] != float64(http.StatusAccepted) ||
+        access["http_url"] != srv.URL+"/items" || access["trace_id"] != "trace-out" {
+        t.Errorf("client log = %v", access)
+    }
+    if _, ok := access["http_duration_ms"]; !ok {
+        t.Errorf("duration missing: %v", access)
+    }
+    h, _ := headers["http_request_headers"].(map[string]any)
+    if h["Authorization"] != log.RedactedValue || h[http.CanonicalHeaderKey(log.HeaderTraceID)] != "trace-out" {
+        t.Errorf("logged headers = %v", headers["http_request_headers"])
+    }
+
+    // 其他主机只在列入 WithPropagationHosts 时注入关联字段
+    for host, want := range map[string]string{
+        "api.thirdparty.test": "",
+        "orders.example.com":  "trace-out",
+    } {
+        var sent http.Header
+        next := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+            sent = r.Header
+            return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+        })
+        other := &http.Client{Transport: log.NewLoggingRoundTripper(next, l, log.WithPropagationHosts("*.example.com"))}
+        req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+"/", nil)
+        resp, err = other.Do(req)
+        if err != nil {
+            t.Fatalf("Do: %v", err)
+        }
+        resp.Body.Close()
+        if got := sent.Get(log.HeaderTraceID); got != want {
+            t.Errorf("%s: trace header = %q, want %q", host, got, want)
+        }
+    }
+
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sirupsen/logrus"
+)
+
+// failingCloseLogger 在 Close 时返回指定错误
+type failingCloseLogger struct {
+    log.Logger
+    err error
+}
+
+func (l failingCloseLogger) Close() error {
+    return l.err
+}
+
+func TestMultiLogger(t *testing.T) {
+    var textBuf, jsonBuf bytes.Buffer
+    text := newTextLogger(t, &textBuf)
+    jsonLogger := newJSONLogger(t, &jsonBuf)
+
+    m := log.NewMultiLogger(text, jsonLogger).(*log.MultiLogger)
+    ctx := log.WithRequestID(context.Background(), "req-1")
+    m.Debugf("debug %d", 1)
+    m.Infof("info %d", 2)
+    m.WithField("k", "v").WarnContextf(ctx, "warn %d", 3)
+    m.ErrorContextf(ctx, "error %d", 4)
+
+    for name, buf := range map[string]*bytes.Buffer{"text": &textBuf, "json": &jsonBuf} {
+        out := buf.String()
+        for _, want := range []string{"debug 1", "info 2", "warn 3", "error 4", "req-1"} {
+            if !strings.Contains(out, want) {
+                t.Errorf("%s logger missing %q in %q", name, want, out)
+            }
+        }
+        if got := strings.Count(out, "\n"); got != 4 {
+            t.Errorf("%s logger got %d lines, want 4", name, got)
+        }
+    }
+}
+
+func TestMultiLoggerClose(t *testing.T) {
+    errA := errors.New("close a")
+    errB := errors.New("close b")
+    var buf bytes.Buffer
+    m := log.NewMultiLogger(
+        failingCloseLogger{Logger: newJSONLogger(t, &buf), err: errA},
+        newJSONLogger(t, &buf),
+        failingCloseLogger{Logger: newJSONLogger(t, &buf), err: errB},
+    ).(*log.MultiLogger)
+    err := m.Close()
+    if !errors.Is(err, errA) || !errors.Is(err, errB) {
+        t.Errorf("Close() = %v, want both errors", err)
+    }
+}
+
+// plainLogger 隐藏具体类型，模拟 MultiLogger 无法拆分 Fatal 的其他 Logger 实现
+type plainLogger struct {
+    log.Logger
+}
+
+func TestMultiLoggerFatalPlainLoggers(t *testing.T) {
+    var exits []int
+    var plainA, plainB, last bytes.Buffer
+    recordExit := func(c *log.Config) {
+        c.ExitFunc = func(code int) { exits = append(exits, code) }
+    }
+    m := log.NewMultiLogger(
+        plainLogger{newJSONLogger(t, &plainA, recordExit)},
+        plainLogger{newJSONLogger(t, &plainB, recordExit)},
+        newJSONLogger(t, &last, recordExit),
+    )
+    m.Fatalf("cannot start: %s", "port in use")
+
+    // 其他实现的 Logger 收到 Error 日志而不会各自退出
+    if len(exits) != 1 {
+        t.Fatalf("exit calls = %v, want exactly one", exits)
+    }
+    for name, buf := range map[string]*bytes.Buffer{"plainA": &plainA, "plainB": &plainB} {
+        if out := buf.String(); !strings.Contains(out, "cannot start: port in use") || !strings.Contains(out, `"level":"error"`) {
+            t.Errorf("%s = %q", name, out)
+        }
+    }
+    if !strings.Contains(last.String(), `"level":"fatal"`) {
+        t.Errorf("last = %q", last.String())
+    }
+}
+
+func TestMultiLoggerOptionalMethods(t *testing.T) {
+    var plainBuf, fullBuf bytes.Buffer
+    m := log.NewMultiLogger(plainLogger{newJSONLogger(t, &plainBuf)}, newJSONLogger(t, &fullBuf)).(*log.MultiLogger)
+
+    // 只实现 Logger 核心接口的 Logger 不带绑定字段，其他可选方法跳过它
+    m.WithField("k", "v").Infof("bound")
+    m.ErrorfKeyed("disk", "disk full")
+    if !strings.Contains(plainBuf.String(), "bound") || strings.Contains(plainBuf.String(), `"k"`) {
+        t.Errorf("plain logger = %q", plainBuf.String())
+    }
+    if !strings.Contains(plainBuf.String(), `"msg":"disk full"`) {
+        t.Errorf("plain logger keyed error = %q", plainBuf.String())
+    }
+    if !strings.Contains(fullBuf.String(), `"k":"v"`) {
+        t.Errorf("full logger = %q", fullBuf.String())
+    }
+    if m.GetConfig().Format != log.FormatJSON {
+        t.Errorf("GetConfig should come from the first ConfigProvider: %+v", m.GetConfig())
+    }
+    if err := m.Sync(); err != nil {
+        t.Errorf("Sync: %v", err)
+    }
+    if err := m.Close(); err != nil {
+        t.Errorf("Close: %v", err)
+    }
+}
+
+func TestMultiLoggerPanic(t *testing.T) {
+    var plainBuf, fullBuf bytes.Buffer
+    m := log.NewMultiLogger(plainLogger{newJSONLogger(t, &plainBuf)}, newJSONLogger(t, &fullBuf)).(*log.MultiLogger)
+
+    panicOnce := func(format string, args ...any) (recovered any) {
+        defer func() { recovered = recover() }()
+        m.PanicContextf(log.WithRequestID(context.Background(), "req-1"), format, args...)
+        return nil
+    }
+    // 所有 Logger 都收到日志后只 panic 一次，panic 的值来自 *LogrusLogger
+    for i := 0; i < 3; i++ {
+        if e, ok := panicOnce("worker crashed: attempt %d", i).(*logrus.Entry); !ok || e.Message != fmt.Sprintf("worker crashed: attempt %d", i) {
+            t.Fatalf("recovered %#v, want *logrus.Entry", e)
+        }
+    }
+    if out := fullBuf.String(); strings.Count(out, "\n") != 1 || !strings.Contains(out, `"level":"panic"`) || !strings.Contains(out, "req-1") {
+        t.Errorf("full logger = %q, want one throttled panic line", out)
+    }
+    if out := plainBuf.String(); strings.Count(out, "\n") != 3 || !strings.Contains(out, `"level":"error"`) {
+        t.Errorf("plain logger = %q", out)
+    }
+
+    // 没有 *LogrusLogger 时以格式化后的消息 panic
+    plainOnly := log.NewMultiLogger(plainLogger{newJSONLogger(t, &plainBuf)}).(*log.MultiLogger)
+    func() {
+        defer func() {
+            if r := recover(); r != "boom 1" {
+                t.Errorf("recovered %#v, want the formatted message", r)
+            }
+        }()
+        plainOnly.Panicf("boom %d", 1)
+    }()
+}
+
+func TestEmptyMultiLoggerFatalExits(t *testing.T) {
+    if os.Getenv("LOG_TEST_EMPTY_MULTI_FATAL") == "1" {
+        log.NewMultiLogger().Fatalf("no sinks")
+        return
+    }
+    cmd := exec.Command(os.Args[0], "-test.run=^TestEmptyMultiLoggerFatalExits$")
+    cmd.Env = append(os.Environ(), "LOG_TEST_EMPTY_MULTI_FATAL=1")
+    err := cmd.Run()
+    var exitErr *exec.ExitError
+    if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+        t.Errorf("empty MultiLogger Fatalf: err = %v, want exit status 1", err)
+    }
+}
+
+func TestMultiLoggerFatalReachesAllSinks(t *testing.T) {
+    var first, second bytes.Buffer
+    var exits []int
+    var seenAtExit []string
+
+    newSink := func(buf *bytes.Buffer, exitCode int) log.Logger {
+        return newJSONLogger(t, buf, func(c *log.Config) {
+            c.BufferSize = 4096 // 缓冲中的日志也必须在退出前写出
+            c.SyncFlushLevel = logrus.PanicLevel
+            c.FatalExitCode = exitCode
+            c.ExitFunc = func(code int) {
+                exits = append(exits, code)
+                seenAtExit = append(seenAtExit, first.String(), second.String())
+            }
+        })
+    }
+    m := log.NewMultiLogger(newSink(&first, 3), newSink(&second, 4)).(*log.MultiLogger)
+
+    m.Infof("before fatal")
+    m.FatalContextf(log.WithRequestID(context.Background(), "req-1"), "cannot start: %s", "port in use")
+
+    if len(exits) != 1 || exits[0] != 3 {
+        t.Fatalf("exit calls = %v, want a single exit with the first logger's code", exits)
+    }
+    for i, out := range seenAtExit {
+        if !strings.Contains(out, "before fatal") || !strings.Contains(out, "cannot start: port in use") || !strings.Contains(out, `"level":"fatal"`) {
+            t.Errorf("sink %d at exit = %q", i, out)
+        }
+    }
+
+    // LogAt 的 Fatal 级别同样写入所有 Logger 后只退出一次
+    exits, seenAtExit = nil, nil
+    first.Reset()
+    second.Reset()
+    m.LogAt(time.Now(), logrus.FatalLevel, "late fatal")
+    if len(exits) != 1 || !strings.Contains(seenAtExit[0], "late fatal") || !strings.Contains(seenAtExit[1], "late fatal") {
+        t.Errorf("LogAt fatal: exits = %v, seen = %q", exits, seenAtExit)
+    }
+}
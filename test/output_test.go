@@ -0,0 +1,357 @@
+package test
+
+import (
+    "bytes"
+    "errors"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sirupsen/logrus"
+)
+
+func TestSplitErrorStream(t *testing.T) {
+    var stdout, stderr bytes.Buffer
+    cfg := log.DefaultConfig()
+    cfg.Output = &stdout
+    cfg.ErrorOutput = &stderr
+    cfg.Level = logrus.DebugLevel
+
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+
+    l.Infof("info line")
+    l.Errorf("error line")
+
+    if !strings.Contains(stdout.String(), "info line") || strings.Contains(stdout.String(), "error line") {
+        t.Errorf("stdout = %q, want only the info line", stdout.String())
+    }
+    if !strings.Contains(stderr.String(), "error line") || strings.Contains(stderr.String(), "info line") {
+        t.Errorf("stderr = %q, want only the error line", stderr.String())
+    }
+
+    // 动态替换输出后，分流依然生效
+    var stdout2 bytes.Buffer
+    l.SetOutput(&stdout2)
+    l.SetFormatter(log.FormatText)
+    l.Warnf("warn line")
+    l.Debugf("debug line")
+    if !strings.Contains(stderr.String(), "warn line") {
+        t.Errorf("stderr = %q, want the warn line", stderr.String())
+    }
+    if !strings.Contains(stdout2.String(), "debug line") {
+        t.Errorf("stdout2 = %q, want the debug line", stdout2.String())
+    }
+}
+
+// failingWriter 在 fail 为 true 时返回写入错误
+type failingWriter struct {
+    fail bool
+}
+
+var errDiskFull = errors.New("disk full")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+    if w.fail {
+        return 0, errDiskFull
+    }
+    return len(p), nil
+}
+
+func TestLastError(t *testing.T) {
+    w := &failingWriter{}
+    cfg := log.DefaultConfig()
+    cfg.Output = w
+    cfg.PropagateWriteErrors = true
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+
+    l.Infof("ok")
+    if err := l.(*log.LogrusLogger).LastError(); err != nil {
+        t.Fatalf("LastError() = %v, want nil", err)
+    }
+
+    w.fail = true
+    l.Infof("lost")
+    if err := l.(*log.LogrusLogger).LastError(); !errors.Is(err, errDiskFull) {
+        t.Errorf("LastError() = %v, want %v", err, errDiskFull)
+    }
+
+    w.fail = false
+    l.Infof("recovered")
+    if err := l.(*log.LogrusLogger).LastError(); err != nil {
+        t.Errorf("LastError() after recovery = %v, want nil", err)
+    }
+}
+
+// fakeTTY 模拟一个终端输出
+type fakeTTY struct{ bytes.Buffer }
+
+func (*fakeTTY) IsTerminal() bool { return true }
+
+func TestIsTerminal(t *testing.T) {
+    r, w, err := os.Pipe()
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer r.Close()
+    defer w.Close()
+
+    cfg := log.DefaultConfig()
+    cfg.Output = w
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    if l.IsTerminal() {
+        t.Error("pipe reported as terminal")
+    }
+
+    l.SetOutput(&fakeTTY{})
+    if !l.IsTerminal() {
+        t.Error("fake tty not reported as terminal")
+    }
+    if l.(*log.LogrusLogger).WithField("k", "v").IsTerminal() != true {
+        t.Error("child logger should share the terminal state")
+    }
+
+    l.SetOutput(&bytes.Buffer{})
+    if l.IsTerminal() {
+        t.Error("buffer reported as terminal")
+    }
+}
+
+func TestTimeRotation(t *testing.T) {
+    dir := t.TempDir()
+    now := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+    cfg := log.DefaultConfig()
+    cfg.Format = log.FormatJSON
+    cfg.RotationFilePattern = filepath.Join(dir, "app-%Y-%m-%d.log")
+    cfg.RotationMaxFiles = 2
+    cfg.Clock = func() time.Time { return now }
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    defer l.(*log.LogrusLogger).Close()
+
+    read := func(name string) string {
+        data, err := os.ReadFile(filepath.Join(dir, name))
+        if err != nil {
+            t.Fatalf("ReadFile: %v", err)
+        }
+        return string(data)
+    }
+
+    l.Infof("day one")
+    now = now.Add(2 * time.Minute) // 跨过零点
+    l.Infof("day two")
+
+    if got := read("app-2024-01-01.log"); !strings.Contains(got, "day one") || strings.Contains(got, "day two") {
+        t.Errorf("day one file = %q", got)
+    }
+    if got := read("app-2024-01-02.log"); !strings.Contains(got, "day two") {
+        t.Errorf("day two file = %q", got)
+    }
+
+    // 超出保留数量后最旧的文件被删除
+    now = now.Add(24 * time.Hour)
+    l.Infof("day three")
+    entries, _ := os.ReadDir(dir)
+    var names []string
+    for _, e := range entries {
+        names = append(names, e.Name())
+    }
+    if strings.Join(names, ",") != "app-2024-01-02.log,app-2024-01-03.log" {
+        t.Errorf("files after retention = %v", names)
+    }
+
+    cfg.RotationPattern = "weekly"
+    if _, err := log.NewLogger(cfg); err == nil {
+        t.Error("unknown rotation pattern should be rejected")
+    }
+
+    // 模板缺少周期所需的占位符时，同一文件会被反复写入并被保留策略删除，直接拒绝
+    for _, tc := range []struct {
+        period  log.RotationPattern
+        pattern string
+    }{
+        {log.RotationDaily, "app-%Y-%m.log"},
+        {log.RotationHourly, "app-%Y-%m-%d.log"},
+    } {
+        cfg.RotationPattern = tc.period
+        cfg.RotationFilePattern = filepath.Join(dir, tc.pattern)
+        if _, err := log.NewLogger(cfg); err == nil {
+            t.Errorf("%s rotation with pattern %q should be rejected", tc.period, tc.pattern)
+        }
+    }
+}
+
+func TestTimeRotationGlobMetacharacters(t *testing.T) {
+    // 目录名中的通配符元字符按字面量处理，保留策略仍然生效
+    dir := filepath.Join(t.TempDir(), "logs[1]*?")
+    now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+    cfg := log.DefaultConfig()
+    cfg.RotationFilePattern = filepath.Join(dir, "app-%Y-%m-%d.log")
+    cfg.RotationMaxFiles = 1
+    cfg.Clock = func() time.Time { return now }
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    defer l.(*log.LogrusLogger).Close()
+
+    l.Infof("day one")
+    now = now.Add(24 * time.Hour)
+    l.Infof("day two")
+    entries, _ := os.ReadDir(dir)
+    var names []string
+    for _, e := range entries {
+        names = append(names, e.Name())
+    }
+    if strings.Join(names, ",") != "app-2024-01-02.log" {
+        t.Errorf("files after retention = %v", names)
+    }
+}
+
+func TestTrailingNewline(t *testing.T) {
+    cases := []struct {
+        policy log.TrailingNewline
+        want   string
+    }{
+        {"", "}\n"},
+        {log.TrailingNewlineAuto, "}\n"},
+        {log.TrailingNewlineAlways, "}\n"},
+        {log.TrailingNewlineNever, "}"},
+    }
+    for _, tc := range cases {
+        var buf bytes.Buffer
+        cfg := log.DefaultConfig()
+        cfg.Output = &buf
+        cfg.TrailingNewline = tc.policy
+        l, err := log.NewLogger(cfg)
+        if err != nil {
+            t.Fatalf("NewLogger(%q): %v", tc.policy, err)
+        }
+        l.Infof("first")
+        l.Infof("second")
+        got := buf.String()
+        if !strings.HasSuffix(got, tc.want) || strings.HasSuffix(got, "\n\n") {
+            t.Errorf("policy %q: output %q should end with %q", tc.policy, got, tc.want)
+        }
+        if tc.policy == log.TrailingNewlineNever && strings.Contains(got, "\n") {
+            t.Errorf("policy never: output %q contains newline", got)
+        }
+
+        // 替换输出目标后策略仍然生效
+        var replaced bytes.Buffer
+        l.SetOutput(&replaced)
+        l.Infof("third")
+        if !strings.HasSuffix(replaced.String(), tc.want) {
+            t.Errorf("policy %q after SetOutput: output %q", tc.policy, replaced.String())
+        }
+    }
+
+    cfg := log.DefaultConfig()
+    cfg.TrailingNewline = "sometimes"
+    if _, err := log.NewLogger(cfg); err == nil {
+        t.Error("unknown trailing newline policy should be rejected")
+    }
+}
+
+func TestHealthCheck(t *testing.T) {
+    w := &failingWriter{}
+    var errOut bytes.Buffer
+    cfg := log.DefaultConfig()
+    cfg.Output = w
+    cfg.ErrorOutput = &errOut
+    cfg.BufferSize = 1024
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    defer l.(*log.LogrusLogger).Close()
+
+    if err := l.(*log.LogrusLogger).HealthCheck(); err != nil {
+        t.Fatalf("HealthCheck() = %v, want nil", err)
+    }
+    w.fail = true
+    if err := l.(*log.LogrusLogger).HealthCheck(); !errors.Is(err, errDiskFull) {
+        t.Errorf("HealthCheck() = %v, want %v", err, errDiskFull)
+    }
+    if errOut.Len() != 0 {
+        t.Errorf("probe should not produce log output: %q", errOut.String())
+    }
+
+    // 文件关闭后不可写
+    fileCfg := log.DefaultConfig()
+    fileCfg.FilePath = filepath.Join(t.TempDir(), "app.log")
+    fl, err := log.NewLogger(fileCfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    if err := fl.(*log.LogrusLogger).HealthCheck(); err != nil {
+        t.Fatalf("file HealthCheck() = %v, want nil", err)
+    }
+    fl.(*log.LogrusLogger).Close()
+    if err := fl.(*log.LogrusLogger).HealthCheck(); !errors.Is(err, os.ErrClosed) {
+        t.Errorf("closed file HealthCheck() = %v, want %v", err, os.ErrClosed)
+    }
+}
+
+func TestRingBuffer(t *testing.T) {
+    var buf bytes.Buffer
+    l := newTextLogger(t, &buf, func(c *log.Config) { c.TextLayout = "{msg}" })
+    ring := log.NewRingBuffer(3)
+    remove := l.AddTee(ring)
+    defer remove()
+
+    for i := 1; i <= 5; i++ {
+        l.Infof("line %d", i)
+    }
+    if got := ring.Dump(); strings.Join(got, ",") != "line 3,line 4,line 5" {
+        t.Errorf("Dump = %q", got)
+    }
+    if n := strings.Count(buf.String(), "\n"); n != 5 {
+        t.Errorf("main output has %d lines, want 5", n)
+    }
+
+    // 不以换行结束的内容等到补全后才成为一行
+    _, _ = ring.Write([]byte("part"))
+    _, _ = ring.Write([]byte("ial\nnext\n"))
+    if got := ring.Dump(); strings.Join(got, ",") != "line 5,partial,next" {
+        t.Errorf("Dump after partial writes = %q", got)
+    }
+
+    srv := httptest.NewServer(log.RingBufferHandler(ring))
+    defer srv.Close()
+    for query, want := range map[string]string{"": "line 5\npartial\nnext\n", "?n=2": "partial\nnext\n"} {
+        resp, err := http.Get(srv.URL + query)
+        if err != nil {
+            t.Fatalf("GET: %v", err)
+        }
+        body, _ := io.ReadAll(resp.Body)
+        resp.Body.Close()
+        if resp.StatusCode != http.StatusOK || string(body) != want {
+            t.Errorf("GET %q = %d %q, want %q", query, resp.StatusCode, body, want)
+        }
+    }
+    resp, err := http.Get(srv.URL + "?n=x")
+    if err != nil {
+        t.Fatalf("GET: %v", err)
+    }
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusBadRequest {
+        t.Errorf("invalid n status = %d", resp.StatusCode)
+    }
+}
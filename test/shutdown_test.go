@@ -0,0 +1,69 @@
+package test
+
+import (
+    "context"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "go.uber.org/goleak"
+)
+
+// 只检查本测试启动的 goroutine，其他测试遗留的 goroutine 不计入
+func TestNoGoroutineLeakAfterClose(t *testing.T) {
+    defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+    cfg := log.DefaultConfig()
+    cfg.Output = io.Discard
+    cfg.BufferSize = 1024
+    cfg.FlushInterval = time.Millisecond
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+    l.Infof("buffered")
+    if err := l.(*log.LogrusLogger).Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+}
+
+func TestNoGoroutineLeakAfterCancel(t *testing.T) {
+    defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+    ctx, cancel := context.WithCancel(context.Background())
+
+    var out syncBuffer
+    cfg := log.DefaultConfig()
+    cfg.Output = &out
+    cfg.BufferSize = 1024
+    cfg.FlushInterval = time.Hour
+    cfg.Context = ctx
+    l, err := log.NewLogger(cfg)
+    if err != nil {
+        t.Fatalf("NewLogger: %v", err)
+    }
+
+    global := log.GetGlobalLogger().(*log.LogrusLogger)
+    defer global.SetLevel(global.GetConfig().Level)
+    path := filepath.Join(t.TempDir(), "level")
+    if err := os.WriteFile(path, []byte("warn"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    log.WatchLevelFile(ctx, path, time.Millisecond)
+
+    // 取消后缓冲中的日志仍会写出
+    l.Warnf("pending at cancel")
+    cancel()
+
+    deadline := time.Now().Add(time.Second)
+    for !strings.Contains(out.String(), "pending at cancel") && time.Now().Before(deadline) {
+        time.Sleep(time.Millisecond)
+    }
+    if !strings.Contains(out.String(), "pending at cancel") {
+        t.Errorf("buffer not flushed on cancel: %q", out.String())
+    }
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/sapaude/go-shims/x/log"
+    "github.com/sirupsen/logrus"
+)
+
+func TestWatchLevelFile(t *testing.T) {
+    global := log.GetGlobalLogger().(*log.LogrusLogger)
+    original := global.GetConfig().Level
+    defer global.SetLevel(original)
+
+    path := filepath.Join(t.TempDir(), "level")
+    if err := os.WriteFile(path, []byte("debug\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    log.WatchLevelFile(ctx, path, 10*time.Millisecond)
+    if got := global.GetConfig().Level; got != logrus.DebugLevel {
+        t.Fatalf("initial level = %v, want debug", got)
+    }
+
+    waitLevel := func(want logrus.Level) {
+        t.Helper()
+        deadline := time.Now().Add(time.Second)
+        for time.Now().Before(deadline) {
+            if global.GetConfig().Level == want {
+                return
+            }
+            time.Sleep(5 * time.Millisecond)
+        }
+        t.Fatalf("level = %v, want %v", global.GetConfig().Level, want)
+    }
+
+    if err := os.WriteFile(path, []byte("error"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    waitLevel(logrus.ErrorLevel)
+
+    // 非法内容被忽略，级别保持不变
+    if err := os.WriteFile(path, []byte("loud"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    time.Sleep(50 * time.Millisecond)
+    if got := global.GetConfig().Level; got != logrus.ErrorLevel {
+        t.Errorf("level after invalid content = %v, want error", got)
+    }
+}
+
+func TestWatchLevelFileNonPositiveInterval(t *testing.T) {
+    global := log.GetGlobalLogger().(*log.LogrusLogger)
+    original := global.GetConfig().Level
+    defer global.SetLevel(original)
+
+    path := filepath.Join(t.TempDir(), "level")
+    for _, interval := range []time.Duration{0, -time.Second} {
+        global.SetLevel(logrus.WarnLevel)
+        if err := os.WriteFile(path, []byte("info"), 0644); err != nil {
+            t.Fatal(err)
+        }
+        ctx, cancel := context.WithCancel(context.Background())
+        // 不能 panic (后台 goroutine 中的 panic 会使进程退出)，首次读取仍然生效
+        log.WatchLevelFile(ctx, path, interval)
+        time.Sleep(20 * time.Millisecond)
+        cancel()
+        if got := global.GetConfig().Level; got != logrus.InfoLevel {
+            t.Errorf("interval %v: level = %v, want info", interval, got)
+        }
+        if err := os.WriteFile(path, []byte("warn"), 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+}
@@ -0,0 +1,27 @@
+// Package wrapper 模拟用户在日志库之上封装的一层薄包装，用于测试 CallerSkipPackages
+package wrapper
+
+import (
+    "context"
+
+    "github.com/sapaude/go-shims/x/log"
+)
+
+// Logger 是对 log.Logger 的简单封装
+type Logger struct {
+    L log.Logger
+}
+
+// Infof 转发到底层 Logger
+func (w Logger) Infof(format string, args ...any) {
+    w.L.Infof(format, args...)
+}
+
+// InfoContextf 经过两层封装后转发到底层 Logger
+func (w Logger) InfoContextf(ctx context.Context, format string, args ...any) {
+    w.logContext(ctx, format, args...)
+}
+
+func (w Logger) logContext(ctx context.Context, format string, args ...any) {
+    w.L.InfoContextf(ctx, format, args...)
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+    "fmt"
+    "slices"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// ValidateConfig 检查 cfg 能否创建出可用的 Logger，但不输出任何日志，适用于 CI 与启动前检查:
+// 级别与格式有效、NewLogger 能成功构建 (格式化器选项、输出文件、Hook 等)、输出目标可写、格式化器能编码一条探测日志。
+// 检查完成后关闭构建出的 Logger；外部传入的 Output 不会被关闭，FilePath 指向的文件不存在时会被创建。
+func ValidateConfig(cfg Config) error {
+    if !slices.Contains(logrus.AllLevels, cfg.Level) {
+        return fmt.Errorf("invalid config: unknown level %d", cfg.Level)
+    }
+    if err := validateFormat(cfg); err != nil {
+        return fmt.Errorf("invalid config: %w", err)
+    }
+
+    logger, err := NewLogger(cfg)
+    if err != nil {
+        return fmt.Errorf("invalid config: %w", err)
+    }
+    l := logger.(*LogrusLogger)
+    defer l.Close()
+
+    if err := l.HealthCheck(); err != nil {
+        return fmt.Errorf("invalid config: output not writable: %w", err)
+    }
+
+    // 只编码不写出，检查格式化器与字段变换能处理一条带字段的日志
+    entry := logrus.NewEntry(l.Logger).WithFields(logrus.Fields{"validate": true})
+    entry.Time = time.Now()
+    entry.Level = cfg.Level
+    entry.Message = "config validation"
+    if _, err := l.Logger.Formatter.Format(entry); err != nil {
+        return fmt.Errorf("invalid config: formatter: %w", err)
+    }
+    return nil
+}
+
+// validateFormat 检查 Format 是内置格式或已通过 RegisterFormatter 注册的格式。
+// NewLogger 会把未知格式当作文本格式处理，ValidateConfig 将其视为配置错误
+func validateFormat(cfg Config) error {
+    if cfg.EnableJSON {
+        return nil
+    }
+    switch cfg.Format {
+    case "", FormatText, FormatJSON:
+        return nil
+    case FormatCustom:
+        return fmt.Errorf("format %q cannot be created by name, use SetFormatterObject", FormatCustom)
+    }
+    if _, ok := registeredFormatter(cfg.Format); !ok {
+        return fmt.Errorf("unknown format %q", cfg.Format)
+    }
+    return nil
+}
@@ -0,0 +1,91 @@
+package log
+
+import (
+    "runtime"
+    "slices"
+
+    "github.com/sirupsen/logrus"
+)
+
+// verboseLevels 是 Config.ErrorFormatVerbose 开启时输出调用者与调用栈的级别
+var verboseLevels = []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+
+// verboseCallerLevels 返回开启 ErrorFormatVerbose 时 CallerHook 需要覆盖的级别:
+// 未开启 ReportCaller 或 CallerLevels 为空时只有 Error 及以上 (其余级别的调用者字段反正会被去掉，不必查找)，
+// CallerLevels 受限时补上 Error 及以上
+func verboseCallerLevels(cfg Config) []logrus.Level {
+    if !cfg.ReportCaller || len(cfg.CallerLevels) == 0 {
+        return verboseLevels
+    }
+    levels := slices.Clone(cfg.CallerLevels)
+    for _, level := range verboseLevels {
+        if !slices.Contains(levels, level) {
+            levels = append(levels, level)
+        }
+    }
+    return levels
+}
+
+// levelBandFormatter 按级别分派到不同的格式化设置: Error 及以上使用完整的 verbose 格式 (含调用者与调用栈)，
+// 其余级别使用去掉调用者与调用栈字段的紧凑格式
+type levelBandFormatter struct {
+    verbose logrus.Formatter
+    compact logrus.Formatter
+}
+
+// newLevelBandFormatter 基于同一个 Formatter 构建按级别分派的 Formatter
+func newLevelBandFormatter(f logrus.Formatter) *levelBandFormatter {
+    return &levelBandFormatter{
+        verbose: f,
+        compact: &transformFormatter{Formatter: f, transforms: []EntryTransform{stripDiagnostics}},
+    }
+}
+
+// Format 实现 logrus.Formatter
+func (f *levelBandFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+    if entry.Level <= logrus.ErrorLevel {
+        return f.verbose.Format(entry)
+    }
+    return f.compact.Format(entry)
+}
+
+// stripDiagnostics 移除调用者与调用栈字段
+func stripDiagnostics(entry *logrus.Entry) {
+    delete(entry.Data, CallerFileFieldKey)
+    delete(entry.Data, CallerFuncFieldKey)
+    delete(entry.Data, StacktraceFieldKey)
+}
+
+// stacktraceHook 为 Error 及以上级别的条目添加从调用者开始的调用栈，已有 stacktrace 字段时不覆盖
+type stacktraceHook struct {
+    caller CallerHook // 用于跳过 logrus、本包与 CallerSkipPackages 中的栈帧
+}
+
+// Levels 返回 Hook 应该触发的日志级别
+func (*stacktraceHook) Levels() []logrus.Level {
+    return verboseLevels
+}
+
+// Fire 记录调用栈，跳过日志库内部的栈帧
+func (h *stacktraceHook) Fire(entry *logrus.Entry) error {
+    if _, exists := entry.Data[StacktraceFieldKey]; exists {
+        return nil
+    }
+    pcs := make([]uintptr, maxStackDepth)
+    n := runtime.Callers(2, pcs) // 跳过 runtime.Callers 与 Fire
+    frames := runtime.CallersFrames(pcs[:n])
+    var stack Stacktrace
+    for {
+        frame, more := frames.Next()
+        if stack != nil || !h.caller.skipFrame(funcPackage(frame.Function)) {
+            stack = append(stack, StackFrame{Func: frame.Function, File: frame.File, Line: frame.Line})
+        }
+        if !more {
+            break
+        }
+    }
+    if len(stack) > 0 {
+        entry.Data[StacktraceFieldKey] = stack
+    }
+    return nil
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+    "context"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// DefaultWatchInterval 是 WatchLevelFile 的 interval <= 0 时使用的轮询间隔
+const DefaultWatchInterval = 10 * time.Second
+
+// WatchLevelFile 定期读取 path 中的日志级别字符串 (如 "debug")，并应用到全局 Logger。
+// 适用于 Kubernetes 将 ConfigMap 挂载为文件的场景，无需重启即可调整级别。
+// 文件内容无法解析时输出一条警告并保持当前级别；文件暂不存在时静默等待。
+// 首次读取同步完成，之后每隔 interval (<= 0 时为 DefaultWatchInterval) 在后台轮询，直到 ctx 结束。
+func WatchLevelFile(ctx context.Context, path string, interval time.Duration) {
+    if interval <= 0 {
+        interval = DefaultWatchInterval
+    }
+    w := &levelFileWatcher{path: path}
+    w.check()
+
+    ticker := time.NewTicker(interval)
+    go func() {
+        defer ticker.Stop()
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+                w.check()
+            }
+        }
+    }()
+}
+
+// levelFileWatcher 记录上一次读取到的内容，只在内容变化时处理
+type levelFileWatcher struct {
+    path string
+    last string
+}
+
+func (w *levelFileWatcher) check() {
+    data, err := os.ReadFile(w.path)
+    if err != nil {
+        return
+    }
+    content := strings.TrimSpace(string(data))
+    if content == w.last {
+        return
+    }
+    w.last = content
+
+    level, err := logrus.ParseLevel(content)
+    if err != nil {
+        GetGlobalLogger().Warnf("Ignoring invalid log level %q in %s: %v", content, w.path, err)
+        return
+    }
+    GetGlobalLogger().SetLevel(level)
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+    "context"
+    "encoding"
+    "encoding/json"
+    "fmt"
+    "reflect"
+)
+
+// maxStructFieldDepth 是 WithStruct 展开嵌套结构体的最大层数，超出的部分作为单个字段值输出
+const maxStructFieldDepth = 8
+
+// WithStruct 将结构体 v 的导出字段作为自定义字段添加到 Context 中 (见 WithCustomField)，
+// 字段名为 prefix + "." + 字段名 (prefix 为空时不加前缀)，字段名按 log 标签、json 标签、Go 字段名的顺序确定。
+// 嵌套的结构体逐层展开 (如 "req.user.name")，实现了 fmt.Stringer、error、json.Marshaler 或
+// encoding.TextMarshaler 的类型 (如 time.Time) 作为单个值输出；`log:"-"` 的字段被省略，`log:"redact"` 的字段输出 RedactedValue。
+// v 不是结构体或结构体指针时原样返回 ctx。
+func WithStruct(ctx context.Context, prefix string, v any) context.Context {
+    for k, val := range structFields(prefix, v) {
+        ctx = WithCustomField(ctx, k, val)
+    }
+    return ctx
+}
+
+// WithStruct 返回绑定了结构体 v 的导出字段的子 Logger，字段命名规则与全局函数 WithStruct 相同
+func (l *LogrusLogger) WithStruct(prefix string, v any) Logger {
+    return l.WithFields(structFields(prefix, v))
+}
+
+func (m *MultiLogger) WithStruct(prefix string, v any) Logger {
+    return m.WithFields(structFields(prefix, v))
+}
+
+var (
+    stringerType      = reflect.TypeFor[fmt.Stringer]()
+    errorType         = reflect.TypeFor[error]()
+    jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+    textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// structFields 将结构体展开为扁平的字段 map，v 不是结构体时返回 nil
+func structFields(prefix string, v any) map[string]any {
+    rv := reflect.ValueOf(v)
+    for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+        if rv.IsNil() {
+            return nil
+        }
+        rv = rv.Elem()
+    }
+    if rv.Kind() != reflect.Struct || isLeafType(rv.Type()) {
+        return nil
+    }
+    fields := make(map[string]any)
+    flattenStruct(fields, prefix, rv, 0)
+    return fields
+}
+
+// flattenStruct 将结构体 rv 的字段写入 fields
+func flattenStruct(fields map[string]any, prefix string, rv reflect.Value, depth int) {
+    for _, f := range getStructInfo(rv.Type()).fields {
+        if f.omit {
+            continue
+        }
+        key := f.name
+        if prefix != "" {
+            key = prefix + "." + f.name
+        }
+        if f.redact {
+            fields[key] = RedactedValue
+            continue
+        }
+        fv := rv.Field(f.index)
+        if nested, ok := nestedStruct(fv); ok && depth < maxStructFieldDepth {
+            flattenStruct(fields, key, nested, depth+1)
+            continue
+        }
+        fields[key] = fv.Interface()
+    }
+}
+
+// nestedStruct 返回需要继续展开的结构体值，nil 指针与可自行编码的类型不展开
+func nestedStruct(v reflect.Value) (reflect.Value, bool) {
+    for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+        if v.IsNil() || isLeafType(v.Type()) {
+            return reflect.Value{}, false
+        }
+        v = v.Elem()
+    }
+    if v.Kind() != reflect.Struct || isLeafType(v.Type()) {
+        return reflect.Value{}, false
+    }
+    return v, true
+}
+
+// isLeafType 判断类型是否自行决定输出形式 (值或指针实现了 Stringer、error 或编码接口)
+func isLeafType(t reflect.Type) bool {
+    for _, iface := range []reflect.Type{stringerType, errorType, jsonMarshalerType, textMarshalerType} {
+        if t.Implements(iface) || (t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(iface)) {
+            return true
+        }
+    }
+    return false
+}